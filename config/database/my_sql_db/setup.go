@@ -4,13 +4,20 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sync"
 
 	"github.com/joho/godotenv"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 )
 
-var DB *gorm.DB
+// DB is the connection pool shared by every repository. Repositories must
+// share it so that a transaction can span several of them.
+var (
+	DB     *gorm.DB
+	dbErr  error
+	dbOnce sync.Once
+)
 
 type DatabaseConfig struct {
 	Username string
@@ -38,13 +45,26 @@ func LoadDatabaseConfig() *DatabaseConfig {
 	}
 }
 
-// SetupDatabase initializes the database connection using GORM
+// ConnectDatabase returns the shared database connection, opening it on
+// first use.
 func ConnectDatabase() (*gorm.DB, error) {
+	dbOnce.Do(func() {
+		DB, dbErr = openDatabase()
+	})
+	return DB, dbErr
+}
+
+// openDatabase initializes the database connection using GORM
+func openDatabase() (*gorm.DB, error) {
 	cfg := LoadDatabaseConfig()
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=True",
 		cfg.Username, cfg.Password, cfg.Host, cfg.Port, cfg.DBName)
 
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	// Relations are kept consistent by the services; foreign key constraints
+	// would block deleting products that are still referenced by carts or orders.
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
 	if err != nil {
 		return nil, err
 	}
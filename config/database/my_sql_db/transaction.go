@@ -0,0 +1,86 @@
+package mysql
+
+import (
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// Transactor runs a unit of work in a single database transaction. Repositories
+// join the transaction through their WithTx method; the work is committed when
+// fn returns nil and rolled back otherwise.
+type Transactor interface {
+	WithinTransaction(fn func(tx *gorm.DB) error) error
+}
+
+type GormTransactor struct {
+	db *gorm.DB
+}
+
+func NewTransactor(db *gorm.DB) Transactor {
+	return &GormTransactor{db: db}
+}
+
+func (t *GormTransactor) WithinTransaction(fn func(tx *gorm.DB) error) error {
+	var hooks []func()
+	err := t.db.Transaction(func(tx *gorm.DB) error {
+		key := tx.Statement.ConnPool
+		afterCommit.begin(key)
+		defer func() {
+			hooks = afterCommit.end(key)
+		}()
+		return fn(tx)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range hooks {
+		hook()
+	}
+	return nil
+}
+
+// AfterCommit runs fn once the transaction db belongs to has been committed,
+// and never if it is rolled back. Outside a Transactor transaction fn runs
+// immediately. Use it for side effects such as cache or index updates that
+// must not observe uncommitted data.
+func AfterCommit(db *gorm.DB, fn func()) {
+	if !afterCommit.add(db.Statement.ConnPool, fn) {
+		fn()
+	}
+}
+
+var afterCommit = &commitHooks{pending: make(map[gorm.ConnPool][]func())}
+
+// commitHooks tracks the callbacks of open transactions, keyed by the
+// connection of the transaction.
+type commitHooks struct {
+	mu      sync.Mutex
+	pending map[gorm.ConnPool][]func()
+}
+
+func (h *commitHooks) begin(key gorm.ConnPool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.pending[key] = nil
+}
+
+func (h *commitHooks) add(key gorm.ConnPool, fn func()) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	hooks, ok := h.pending[key]
+	if !ok {
+		return false
+	}
+	h.pending[key] = append(hooks, fn)
+	return true
+}
+
+func (h *commitHooks) end(key gorm.ConnPool) []func() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	hooks := h.pending[key]
+	delete(h.pending, key)
+	return hooks
+}
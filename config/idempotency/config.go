@@ -0,0 +1,37 @@
+package idempotency
+
+import (
+	"os"
+	"time"
+)
+
+const (
+	defaultRetention     = 24 * time.Hour
+	defaultPurgeInterval = time.Hour
+)
+
+type IdempotencyConfig struct {
+	// Retention is how long a response is replayed for retries with the same
+	// Idempotency-Key. Afterwards the key can be used for a new request.
+	Retention time.Duration
+	// PurgeInterval is how often expired keys are deleted.
+	PurgeInterval time.Duration
+}
+
+// LoadIdempotencyConfig reads IDEMPOTENCY_KEY_TTL and
+// IDEMPOTENCY_PURGE_INTERVAL as Go durations (e.g. "24h"), falling back to
+// defaults when unset or invalid.
+func LoadIdempotencyConfig() *IdempotencyConfig {
+	return &IdempotencyConfig{
+		Retention:     durationFromEnv("IDEMPOTENCY_KEY_TTL", defaultRetention),
+		PurgeInterval: durationFromEnv("IDEMPOTENCY_PURGE_INTERVAL", defaultPurgeInterval),
+	}
+}
+
+func durationFromEnv(key string, def time.Duration) time.Duration {
+	d, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || d <= 0 {
+		return def
+	}
+	return d
+}
@@ -0,0 +1,70 @@
+package notification
+
+import (
+	"fmt"
+	"go-online-store/pkg/notifier"
+	"os"
+	"time"
+)
+
+const (
+	NotifierFile = "file"
+	NotifierSMTP = "smtp"
+
+	defaultDispatchInterval = 30 * time.Second
+)
+
+type NotificationConfig struct {
+	// Notifier selects the delivery backend: "file" (default) or "smtp".
+	Notifier string
+	// FilePath is where the file notifier writes messages.
+	FilePath string
+	// DispatchInterval is how often queued notifications are sent.
+	DispatchInterval time.Duration
+
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+}
+
+// LoadNotificationConfig reads the notification settings. Without any, queued
+// notifications are written to notifications/outbox.jsonl.
+func LoadNotificationConfig() *NotificationConfig {
+	cfg := &NotificationConfig{
+		Notifier:     os.Getenv("NOTIFIER"),
+		FilePath:     os.Getenv("NOTIFICATION_FILE"),
+		SMTPHost:     os.Getenv("SMTP_HOST"),
+		SMTPPort:     os.Getenv("SMTP_PORT"),
+		SMTPUsername: os.Getenv("SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+		SMTPFrom:     os.Getenv("SMTP_FROM"),
+	}
+	if cfg.Notifier == "" {
+		cfg.Notifier = NotifierFile
+	}
+	if cfg.FilePath == "" {
+		cfg.FilePath = "notifications/outbox.jsonl"
+	}
+	if cfg.SMTPPort == "" {
+		cfg.SMTPPort = "25"
+	}
+
+	cfg.DispatchInterval = defaultDispatchInterval
+	if d, err := time.ParseDuration(os.Getenv("NOTIFICATION_DISPATCH_INTERVAL")); err == nil && d > 0 {
+		cfg.DispatchInterval = d
+	}
+	return cfg
+}
+
+// NewNotifier builds the delivery backend selected by the config.
+func (cfg *NotificationConfig) NewNotifier() (notifier.Notifier, error) {
+	switch cfg.Notifier {
+	case NotifierSMTP:
+		return notifier.NewSMTPNotifier(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPFrom, cfg.SMTPUsername, cfg.SMTPPassword), nil
+	case NotifierFile:
+		return notifier.NewFileNotifier(cfg.FilePath)
+	}
+	return nil, fmt.Errorf("unknown notifier %q", cfg.Notifier)
+}
@@ -0,0 +1,35 @@
+package order
+
+import (
+	"os"
+	"time"
+)
+
+const (
+	defaultReservationTTL   = 15 * time.Minute
+	defaultReservationSweep = time.Minute
+)
+
+type OrderConfig struct {
+	// ReservationTTL is how long checkout holds stock while waiting for payment.
+	ReservationTTL time.Duration
+	// ReservationSweepInterval is how often expired reservations are released.
+	ReservationSweepInterval time.Duration
+}
+
+// LoadOrderConfig reads RESERVATION_TTL and RESERVATION_SWEEP_INTERVAL as Go
+// durations (e.g. "15m"), falling back to defaults when unset or invalid.
+func LoadOrderConfig() *OrderConfig {
+	return &OrderConfig{
+		ReservationTTL:           durationFromEnv("RESERVATION_TTL", defaultReservationTTL),
+		ReservationSweepInterval: durationFromEnv("RESERVATION_SWEEP_INTERVAL", defaultReservationSweep),
+	}
+}
+
+func durationFromEnv(key string, def time.Duration) time.Duration {
+	d, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || d <= 0 {
+		return def
+	}
+	return d
+}
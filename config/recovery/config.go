@@ -0,0 +1,56 @@
+package recovery
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultAbandonedAfter = 24 * time.Hour
+	defaultScanInterval   = time.Hour
+	defaultCodeTTL        = 7 * 24 * time.Hour
+	defaultCartURL        = "http://localhost:8080/cart"
+)
+
+type RecoveryConfig struct {
+	// AbandonedAfter is how long a cart with items must go untouched before
+	// its customer is reminded about it.
+	AbandonedAfter time.Duration
+	// ScanInterval is how often carts are scanned.
+	ScanInterval time.Duration
+	// CartURL is the storefront page the reminder links to.
+	CartURL string
+	// DiscountRate is taken off the subtotal by the one-time code sent with
+	// the reminder, e.g. 0.1 for 10%. Zero sends no code.
+	DiscountRate float64
+	// CodeTTL is how long the discount code can be used.
+	CodeTTL time.Duration
+}
+
+// LoadRecoveryConfig reads CART_ABANDONED_AFTER, CART_RECOVERY_INTERVAL and
+// CART_RECOVERY_CODE_TTL as Go durations, CART_RECOVERY_URL and
+// CART_RECOVERY_DISCOUNT, falling back to defaults when unset or invalid.
+func LoadRecoveryConfig() *RecoveryConfig {
+	cfg := &RecoveryConfig{
+		AbandonedAfter: durationFromEnv("CART_ABANDONED_AFTER", defaultAbandonedAfter),
+		ScanInterval:   durationFromEnv("CART_RECOVERY_INTERVAL", defaultScanInterval),
+		CodeTTL:        durationFromEnv("CART_RECOVERY_CODE_TTL", defaultCodeTTL),
+		CartURL:        os.Getenv("CART_RECOVERY_URL"),
+	}
+	if cfg.CartURL == "" {
+		cfg.CartURL = defaultCartURL
+	}
+	if rate, err := strconv.ParseFloat(os.Getenv("CART_RECOVERY_DISCOUNT"), 64); err == nil && rate > 0 && rate < 1 {
+		cfg.DiscountRate = rate
+	}
+	return cfg
+}
+
+func durationFromEnv(key string, def time.Duration) time.Duration {
+	d, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || d <= 0 {
+		return def
+	}
+	return d
+}
@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"os"
+)
+
+type StorageConfig struct {
+	MediaRoot    string
+	MediaBaseURL string
+}
+
+// LoadStorageConfig reads the media storage settings, falling back to a
+// local ./media directory served under /media.
+func LoadStorageConfig() *StorageConfig {
+	cfg := &StorageConfig{
+		MediaRoot:    os.Getenv("MEDIA_ROOT"),
+		MediaBaseURL: os.Getenv("MEDIA_BASE_URL"),
+	}
+	if cfg.MediaRoot == "" {
+		cfg.MediaRoot = "media"
+	}
+	if cfg.MediaBaseURL == "" {
+		cfg.MediaBaseURL = "/media"
+	}
+	return cfg
+}
@@ -1,24 +1,75 @@
 package model
 
 import (
+	"fmt"
+	"go-online-store/internal/domain/pricing"
 	"go-online-store/internal/domain/product/model"
+	"go-online-store/pkg/constant"
+	customErrors "go-online-store/pkg/errors"
 	"time"
 )
 
+// Cart belongs to a customer, or to an anonymous visitor identified by
+// GuestID until they sign in. Guest carts have no customer.
 type Cart struct {
 	ID         uint       `json:"id" gorm:"primaryKey"`
 	CustomerID uint       `json:"customer_id" gorm:"not null"`
+	GuestID    *string    `json:"-" gorm:"size:64;uniqueIndex"`
 	Items      []CartItem `json:"items" gorm:"foreignKey:CartID"`
 	CreatedAt  time.Time  `json:"created_at"`
 	UpdatedAt  time.Time  `json:"updated_at"`
 }
 
 type CartItem struct {
-	ID        uint          `json:"id" gorm:"primaryKey"`
-	CartID    uint          `json:"cart_id" gorm:"not null"`
-	ProductID uint          `json:"product_id" gorm:"not null"`
-	Quantity  uint          `json:"quantity" gorm:"not null"`
-	Product   model.Product `json:"product" gorm:"foreignKey:ProductID"`
+	ID        uint  `json:"id" gorm:"primaryKey"`
+	CartID    uint  `json:"cart_id" gorm:"not null"`
+	ProductID uint  `json:"product_id" gorm:"not null"`
+	VariantID *uint `json:"variant_id"`
+	Quantity  uint  `json:"quantity" gorm:"not null"`
+	// UnitPrice is the price when the line was last added or changed. Lines
+	// from before prices were recorded have none.
+	UnitPrice *float64 `json:"unit_price"`
+	// SavedForLater lines stay with the cart but are not bought at checkout.
+	SavedForLater bool                  `json:"saved_for_later" gorm:"not null;default:false"`
+	Product       model.Product         `json:"product" gorm:"foreignKey:ProductID"`
+	Variant       *model.ProductVariant `json:"variant,omitempty" gorm:"foreignKey:VariantID"`
+}
+
+// CartSummary is a cart together with what it costs at current prices and
+// what changed since its lines were added. Items holds the lines to buy;
+// SavedItems the lines saved for later, which are neither priced nor checked.
+type CartSummary struct {
+	Cart
+	SavedItems []CartItem        `json:"saved_items"`
+	Pricing    pricing.Breakdown `json:"pricing"`
+	Warnings   []CartWarning     `json:"warnings"`
+}
+
+// CartWarning tells the customer a cart line changed since it was added:
+// its price moved, its stock ran out or shrank, or it can no longer be
+// bought.
+type CartWarning struct {
+	Code      string   `json:"code"`
+	ProductID uint     `json:"product_id"`
+	VariantID *uint    `json:"variant_id,omitempty"`
+	Message   string   `json:"message"`
+	OldPrice  *float64 `json:"old_price,omitempty"`
+	NewPrice  *float64 `json:"new_price,omitempty"`
+	Available *uint    `json:"available,omitempty"`
+}
+
+// ChangedError is returned by checkout when the cart has warnings the
+// customer has not confirmed yet.
+type ChangedError struct {
+	Warnings []CartWarning
+}
+
+func (e *ChangedError) Error() string {
+	return customErrors.ErrCartChanged.Error()
+}
+
+func (e *ChangedError) Unwrap() error {
+	return customErrors.ErrCartChanged
 }
 
 func (CartItem) TableName() string {
@@ -28,3 +79,102 @@ func (CartItem) TableName() string {
 func (Cart) TableName() string {
 	return "Cart"
 }
+
+// Active returns the lines to buy at checkout.
+func (c *Cart) Active() []CartItem {
+	items := []CartItem{}
+	for _, item := range c.Items {
+		if !item.SavedForLater {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Saved returns the lines saved for later.
+func (c *Cart) Saved() []CartItem {
+	items := []CartItem{}
+	for _, item := range c.Items {
+		if item.SavedForLater {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Quantity returns how many units of a product, or of one of its variants,
+// the cart holds, adding up duplicate lines.
+func (c *Cart) Quantity(productID uint, variantID *uint) uint {
+	var quantity uint
+	for _, item := range c.Items {
+		if item.Matches(productID, variantID) {
+			quantity += item.Quantity
+		}
+	}
+	return quantity
+}
+
+// Index returns the position of the first line of a product, or of one of
+// its variants, or -1 when the cart has none.
+func (c *Cart) Index(productID uint, variantID *uint) int {
+	for i := range c.Items {
+		if c.Items[i].Matches(productID, variantID) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Matches reports whether the line holds the product, or the given variant
+// of it.
+func (i *CartItem) Matches(productID uint, variantID *uint) bool {
+	if i.ProductID != productID {
+		return false
+	}
+	if i.VariantID == nil || variantID == nil {
+		return i.VariantID == nil && variantID == nil
+	}
+	return *i.VariantID == *variantID
+}
+
+// Check compares the line with the product as it is now; product is nil when
+// it has been deleted. It returns no warnings when the line can be bought as
+// it is.
+func (i *CartItem) Check(product *model.Product) []CartWarning {
+	warning := func(code string, message string) CartWarning {
+		return CartWarning{Code: code, ProductID: i.ProductID, VariantID: i.VariantID, Message: message}
+	}
+
+	if product == nil {
+		return []CartWarning{warning(constant.CART_WARNING_UNAVAILABLE, "product is no longer available")}
+	}
+	line, err := pricing.NewLine(product, i.VariantID, i.Quantity)
+	if err != nil {
+		return []CartWarning{warning(constant.CART_WARNING_UNAVAILABLE, product.Name+" is no longer available in this option")}
+	}
+
+	var warnings []CartWarning
+	if i.UnitPrice != nil && *i.UnitPrice != line.UnitPrice {
+		w := warning(constant.CART_WARNING_PRICE_CHANGED, fmt.Sprintf("price of %s changed from %.2f to %.2f", product.Name, *i.UnitPrice, line.UnitPrice))
+		oldPrice, newPrice := *i.UnitPrice, line.UnitPrice
+		w.OldPrice, w.NewPrice = &oldPrice, &newPrice
+		warnings = append(warnings, w)
+	}
+
+	available := product.Stok
+	if i.VariantID != nil {
+		variant, _ := product.Variant(*i.VariantID)
+		available = variant.Stok
+	}
+	switch {
+	case available == 0:
+		w := warning(constant.CART_WARNING_OUT_OF_STOCK, product.Name+" is out of stock")
+		w.Available = &available
+		warnings = append(warnings, w)
+	case available < i.Quantity:
+		w := warning(constant.CART_WARNING_INSUFFICIENT_STOCK, fmt.Sprintf("only %d of %s left", available, product.Name))
+		w.Available = &available
+		warnings = append(warnings, w)
+	}
+	return warnings
+}
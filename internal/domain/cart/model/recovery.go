@@ -0,0 +1,118 @@
+package model
+
+import (
+	"fmt"
+	"go-online-store/pkg/constant"
+	"go-online-store/pkg/notifier"
+	"net/url"
+	"time"
+)
+
+// MaxRecoveryAttempts is how often sending a reminder is tried before it is
+// marked as failed.
+const MaxRecoveryAttempts = 5
+
+// CartRecovery is the reminder sent to a customer about a cart they left with
+// items in it. A cart gets one reminder each time it is left: CartUpdatedAt
+// is the last change to the cart the reminder is about. The reminder may
+// carry a one-time discount code; redeeming it and paying for the cart are
+// tracked so the campaign can be measured.
+type CartRecovery struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	CartID        uint       `json:"cart_id" gorm:"not null;uniqueIndex:idx_cart_recovery"`
+	CartUpdatedAt time.Time  `json:"cart_updated_at" gorm:"not null;uniqueIndex:idx_cart_recovery"`
+	CustomerID    uint       `json:"customer_id" gorm:"not null;index"`
+	Recipient     string     `json:"-" gorm:"not null"`
+	Token         string     `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	DiscountCode  *string    `json:"discount_code,omitempty" gorm:"size:32;uniqueIndex"`
+	DiscountRate  float64    `json:"discount_rate"`
+	CodeExpiresAt *time.Time `json:"code_expires_at,omitempty"`
+	Status        string     `json:"status" gorm:"size:20;not null;index"`
+	Attempts      uint       `json:"attempts" gorm:"not null;default:0"`
+	LastError     string     `json:"last_error,omitempty"`
+	SentAt        *time.Time `json:"sent_at"`
+	OpenedAt      *time.Time `json:"opened_at"`
+	RedeemedAt    *time.Time `json:"redeemed_at"`
+	ConvertedAt   *time.Time `json:"converted_at"`
+	OrderID       *uint      `json:"order_id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func (CartRecovery) TableName() string {
+	return "CartRecovery"
+}
+
+// AbandonedCart is a customer's cart with items that has not changed for a
+// while.
+type AbandonedCart struct {
+	CartID     uint
+	CustomerID uint
+	Email      string
+	UpdatedAt  time.Time
+	ItemCount  uint
+}
+
+// RecoveryStats measures the reminders sent for abandoned carts.
+type RecoveryStats struct {
+	Sent           int64   `json:"sent"`
+	Failed         int64   `json:"failed"`
+	Opened         int64   `json:"opened"`
+	Redeemed       int64   `json:"redeemed"`
+	Converted      int64   `json:"converted"`
+	ConversionRate float64 `json:"conversion_rate"`
+}
+
+// Redeemable reports whether the discount code of the reminder can still be
+// used at now.
+func (r *CartRecovery) Redeemable(now time.Time) bool {
+	if r.DiscountCode == nil || r.RedeemedAt != nil || r.ConvertedAt != nil {
+		return false
+	}
+	return r.CodeExpiresAt == nil || now.Before(*r.CodeExpiresAt)
+}
+
+// Link is the deep link back to the cart carried by the reminder.
+func (r *CartRecovery) Link(cartURL string) string {
+	query := url.Values{"recovery": {r.Token}}
+	if r.DiscountCode != nil {
+		query.Set("code", *r.DiscountCode)
+	}
+	return cartURL + "?" + query.Encode()
+}
+
+// Message is the reminder addressed to the customer.
+func (r *CartRecovery) Message(cartURL string, itemCount uint) notifier.Message {
+	body := fmt.Sprintf("You left %d item(s) in your cart. Pick up where you left off: %s", itemCount, r.Link(cartURL))
+	if r.DiscountCode != nil {
+		body += fmt.Sprintf("\n\nUse code %s at checkout for %.0f%% off your order", *r.DiscountCode, r.DiscountRate*100)
+		if r.CodeExpiresAt != nil {
+			body += " until " + r.CodeExpiresAt.Format("2 Jan 2006")
+		}
+		body += "."
+	}
+
+	return notifier.Message{
+		To:      r.Recipient,
+		Subject: "You left something in your cart",
+		Body:    body,
+	}
+}
+
+// Failed records a failed attempt to send the reminder, giving up after
+// MaxRecoveryAttempts.
+func (r *CartRecovery) Failed(err error) {
+	r.Attempts++
+	r.LastError = err.Error()
+	if r.Attempts >= MaxRecoveryAttempts {
+		r.Status = constant.CART_RECOVERY_STATUS_FAILED
+	}
+}
+
+// Sent records that the reminder was delivered at now.
+func (r *CartRecovery) Sent(now time.Time) {
+	r.Attempts++
+	r.LastError = ""
+	r.Status = constant.CART_RECOVERY_STATUS_SENT
+	r.SentAt = &now
+}
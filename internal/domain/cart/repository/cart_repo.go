@@ -1,10 +1,13 @@
 package repository
 
 import (
+	"errors"
 	mysql "go-online-store/config/database/my_sql_db"
 	"go-online-store/internal/domain/cart/model"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CartRepository struct {
@@ -13,50 +16,223 @@ type CartRepository struct {
 
 type CartRepositoryImpl interface {
 	GetCartByCustomerID(customerID uint) (*model.Cart, error)
+	GetCartByGuestID(guestID string) (*model.Cart, error)
+	MergeCarts(guestCartID, customerID uint, limit QuantityLimit) error
 	ClearCart(cartID uint) error
 	CreateCart(cart *model.Cart) error
-	CreateCartItem(cartID, productID uint, quantity uint) error
-	DeleteCartItem(cartID, productID uint) error
+	DeleteCartItem(cartID, productID uint, variantID *uint) error
+	SetCartItemQuantity(cartID, productID uint, variantID *uint, quantity uint, unitPrice *float64) error
+	SetCartItemSaved(cartID, productID uint, variantID *uint, saved bool) error
+	WithTx(tx *gorm.DB) CartRepositoryImpl
 }
 
+// QuantityLimit returns how many of quantity units of a product, or of one
+// of its variants, a cart may keep.
+type QuantityLimit func(productID uint, variantID *uint, quantity uint) uint
+
 func NewCartRepository() (CartRepositoryImpl, error) {
 	db, err := mysql.ConnectDatabase()
 	if err != nil {
 		return nil, err
 	}
 
+	db.AutoMigrate(&model.Cart{}, &model.CartItem{})
 	return &CartRepository{db: db}, nil
 }
 
+// WithTx returns a repository that runs its statements in tx.
+func (cartRepo *CartRepository) WithTx(tx *gorm.DB) CartRepositoryImpl {
+	return &CartRepository{db: tx}
+}
+
 // GetCartByCustomerID retrieves a cart by customer ID.
 func (cartRepo *CartRepository) GetCartByCustomerID(customerID uint) (*model.Cart, error) {
 	var cart model.Cart
 
 	// Preload both items and their associated products
-	if err := cartRepo.db.Preload("Items").Preload("Items.Product").Where("customer_id = ?", customerID).First(&cart).Error; err != nil {
+	if err := cartRepo.db.Preload("Items").Preload("Items.Product").Preload("Items.Variant").Where("customer_id = ? AND guest_id IS NULL", customerID).First(&cart).Error; err != nil {
+		return nil, err
+	}
+
+	return &cart, nil
+}
+
+// GetCartByGuestID retrieves the cart of an anonymous visitor.
+func (cartRepo *CartRepository) GetCartByGuestID(guestID string) (*model.Cart, error) {
+	var cart model.Cart
+
+	if err := cartRepo.db.Preload("Items").Preload("Items.Product").Preload("Items.Variant").Where("guest_id = ?", guestID).First(&cart).Error; err != nil {
 		return nil, err
 	}
 
 	return &cart, nil
 }
 
+// MergeCarts moves the lines of a guest cart into the customer's cart and
+// deletes the guest cart. A line for a product, or variant, the customer
+// already has is added to the customer's line; other lines move over as
+// they are. Each merged line to buy is cut down to limit, and dropped when
+// nothing is left, in the same transaction. The customer's cart is created
+// when they have none.
+func (cartRepo *CartRepository) MergeCarts(guestCartID, customerID uint, limit QuantityLimit) error {
+	return cartRepo.db.Transaction(func(tx *gorm.DB) error {
+		var guestItems []model.CartItem
+		if err := tx.Where("cart_id = ?", guestCartID).Order("id").Find(&guestItems).Error; err != nil {
+			return err
+		}
+
+		var cart model.Cart
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("customer_id = ? AND guest_id IS NULL", customerID).
+			First(&cart).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			cart = model.Cart{CustomerID: customerID}
+			err = tx.Create(&cart).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		var items []model.CartItem
+		if err := tx.Where("cart_id = ?", cart.ID).Find(&items).Error; err != nil {
+			return err
+		}
+		existing := &model.Cart{Items: items}
+		guest := &model.Cart{Items: guestItems}
+
+		txRepo := &CartRepository{db: tx}
+		for i, item := range guestItems {
+			// Duplicate guest lines were counted with the first one
+			if guest.Index(item.ProductID, item.VariantID) != i {
+				continue
+			}
+			quantity := existing.Quantity(item.ProductID, item.VariantID) + guest.Quantity(item.ProductID, item.VariantID)
+
+			// Lines new to the customer's cart stay saved for later
+			index := existing.Index(item.ProductID, item.VariantID)
+			newSaved := item.SavedForLater && index == -1
+			saved := newSaved || (index >= 0 && existing.Items[index].SavedForLater)
+			if !saved && limit != nil {
+				quantity = limit(item.ProductID, item.VariantID, quantity)
+			}
+
+			if err := txRepo.SetCartItemQuantity(cart.ID, item.ProductID, item.VariantID, quantity, item.UnitPrice); err != nil {
+				return err
+			}
+			if newSaved {
+				if err := txRepo.SetCartItemSaved(cart.ID, item.ProductID, item.VariantID, true); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := tx.Where("cart_id = ?", guestCartID).Delete(&model.CartItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Cart{}, guestCartID).Error
+	})
+}
+
 func (cartRepo *CartRepository) CreateCart(cart *model.Cart) error {
 	return cartRepo.db.Create(cart).Error
 }
 
-func (cartRepo *CartRepository) CreateCartItem(cartID, productID uint, quantity uint) error {
-	cartItem := &model.CartItem{
-		CartID:    cartID,
-		ProductID: productID,
-		Quantity:  quantity,
+// ClearCart removes the lines bought at checkout. Lines saved for later stay.
+func (cartRepo *CartRepository) ClearCart(cartID uint) error {
+	return cartRepo.db.Where("cart_id = ? AND saved_for_later = ?", cartID, false).Delete(&model.CartItem{}).Error
+}
+
+// DeleteCartItem removes the line of a product variant, or every line of the
+// product when variantID is nil.
+func (cartRepo *CartRepository) DeleteCartItem(cartID, productID uint, variantID *uint) error {
+	db := cartRepo.db.Where("cart_id = ? AND product_id = ?", cartID, productID)
+	if variantID != nil {
+		db = db.Where("variant_id = ?", *variantID)
+	}
+	if err := db.Delete(&model.CartItem{}).Error; err != nil {
+		return err
 	}
-	return cartRepo.db.Create(cartItem).Error
+	return touchCart(cartRepo.db, cartID)
 }
 
-func (cartRepo *CartRepository) ClearCart(cartID uint) error {
-	return cartRepo.db.Where("cart_id = ?", cartID).Delete(&model.CartItem{}).Error
+// SetCartItemQuantity sets the quantity of the line of a product, or of one
+// of its variants, creating the line when needed. New lines are not saved
+// for later; existing ones keep their section. Duplicate lines left by
+// earlier versions are merged into the oldest one. A zero quantity removes
+// the line. A unitPrice records the price the customer saw; nil keeps the
+// recorded one.
+func (cartRepo *CartRepository) SetCartItemQuantity(cartID, productID uint, variantID *uint, quantity uint, unitPrice *float64) error {
+	return cartRepo.db.Transaction(func(tx *gorm.DB) error {
+		lines := tx.Where("cart_id = ? AND product_id = ?", cartID, productID)
+		if variantID != nil {
+			lines = lines.Where("variant_id = ?", *variantID)
+		} else {
+			lines = lines.Where("variant_id IS NULL")
+		}
+
+		var items []model.CartItem
+		if err := lines.Clauses(clause.Locking{Strength: "UPDATE"}).Order("id").Find(&items).Error; err != nil {
+			return err
+		}
+		if err := touchCart(tx, cartID); err != nil {
+			return err
+		}
+
+		if quantity == 0 {
+			if len(items) == 0 {
+				return nil
+			}
+			return tx.Delete(&model.CartItem{}, itemIDs(items)).Error
+		}
+
+		if len(items) == 0 {
+			return tx.Create(&model.CartItem{
+				CartID:    cartID,
+				ProductID: productID,
+				VariantID: variantID,
+				Quantity:  quantity,
+				UnitPrice: unitPrice,
+			}).Error
+		}
+
+		updates := map[string]interface{}{"quantity": quantity}
+		if unitPrice != nil {
+			updates["unit_price"] = *unitPrice
+		}
+		if err := tx.Model(&model.CartItem{}).Where("id = ?", items[0].ID).Updates(updates).Error; err != nil {
+			return err
+		}
+		if len(items) > 1 {
+			return tx.Delete(&model.CartItem{}, itemIDs(items[1:])).Error
+		}
+		return nil
+	})
+}
+
+// SetCartItemSaved moves the line of a product, or of one of its variants,
+// to the saved for later section or back to the items to buy.
+func (cartRepo *CartRepository) SetCartItemSaved(cartID, productID uint, variantID *uint, saved bool) error {
+	lines := cartRepo.db.Model(&model.CartItem{}).Where("cart_id = ? AND product_id = ?", cartID, productID)
+	if variantID != nil {
+		lines = lines.Where("variant_id = ?", *variantID)
+	} else {
+		lines = lines.Where("variant_id IS NULL")
+	}
+	if err := lines.Update("saved_for_later", saved).Error; err != nil {
+		return err
+	}
+	return touchCart(cartRepo.db, cartID)
+}
+
+// touchCart marks the cart as changed now, so it does not count as abandoned.
+func touchCart(db *gorm.DB, cartID uint) error {
+	return db.Model(&model.Cart{}).Where("id = ?", cartID).Update("updated_at", time.Now()).Error
 }
 
-func (cartRepo *CartRepository) DeleteCartItem(cartID, productID uint) error {
-	return cartRepo.db.Delete(&model.CartItem{}, "cart_id = ? AND product_id = ?", cartID, productID).Error
+func itemIDs(items []model.CartItem) []uint {
+	ids := make([]uint, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	return ids
 }
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	mysql "go-online-store/config/database/my_sql_db"
+	"go-online-store/internal/domain/cart/model"
+	"go-online-store/pkg/constant"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RecoveryRepository struct {
+	db *gorm.DB
+}
+
+type RecoveryRepositoryImpl interface {
+	GetAbandonedCarts(before time.Time, limit int) ([]model.AbandonedCart, error)
+	GetRecovery(cartID uint, cartUpdatedAt time.Time) (*model.CartRecovery, error)
+	GetRecoveryByToken(token string) (*model.CartRecovery, error)
+	GetRecoveryByCode(code string) (*model.CartRecovery, error)
+	SaveRecovery(recovery *model.CartRecovery) error
+	MarkOpened(id uint, at time.Time) error
+	Redeem(id uint, at time.Time) (bool, error)
+	MarkConverted(cartID, orderID uint, at time.Time) error
+	GetRecoveryStats(since time.Time) (*model.RecoveryStats, error)
+	WithTx(tx *gorm.DB) RecoveryRepositoryImpl
+}
+
+func NewRecoveryRepository() (RecoveryRepositoryImpl, error) {
+	db, err := mysql.ConnectDatabase()
+	if err != nil {
+		return nil, err
+	}
+
+	db.AutoMigrate(&model.CartRecovery{})
+	return &RecoveryRepository{db: db}, nil
+}
+
+// WithTx returns a repository that runs its statements in tx.
+func (repo *RecoveryRepository) WithTx(tx *gorm.DB) RecoveryRepositoryImpl {
+	return &RecoveryRepository{db: tx}
+}
+
+// GetAbandonedCarts returns customer carts with items to buy that have not
+// changed since before, oldest first. Carts already reminded about since their last
+// change are left out; reminders still being retried are not.
+func (repo *RecoveryRepository) GetAbandonedCarts(before time.Time, limit int) ([]model.AbandonedCart, error) {
+	var carts []model.AbandonedCart
+	err := repo.db.Table("Cart").
+		Select("Cart.id AS cart_id, Cart.customer_id, Customer.email, Cart.updated_at, COUNT(CartItem.id) AS item_count").
+		Joins("JOIN CartItem ON CartItem.cart_id = Cart.id AND CartItem.saved_for_later = ?", false).
+		Joins("JOIN Customer ON Customer.id = Cart.customer_id").
+		Where("Cart.guest_id IS NULL AND Cart.updated_at < ?", before).
+		Where("NOT EXISTS (SELECT 1 FROM CartRecovery WHERE CartRecovery.cart_id = Cart.id AND CartRecovery.cart_updated_at = Cart.updated_at AND CartRecovery.status <> ?)",
+			constant.CART_RECOVERY_STATUS_PENDING).
+		Group("Cart.id, Cart.customer_id, Customer.email, Cart.updated_at").
+		Order("Cart.updated_at").
+		Limit(limit).
+		Scan(&carts).Error
+	if err != nil {
+		return nil, err
+	}
+	return carts, nil
+}
+
+func (repo *RecoveryRepository) GetRecovery(cartID uint, cartUpdatedAt time.Time) (*model.CartRecovery, error) {
+	var recovery model.CartRecovery
+	err := repo.db.Where("cart_id = ? AND cart_updated_at = ?", cartID, cartUpdatedAt).First(&recovery).Error
+	if err != nil {
+		return nil, err
+	}
+	return &recovery, nil
+}
+
+func (repo *RecoveryRepository) GetRecoveryByToken(token string) (*model.CartRecovery, error) {
+	var recovery model.CartRecovery
+	if err := repo.db.Where("token = ?", token).First(&recovery).Error; err != nil {
+		return nil, err
+	}
+	return &recovery, nil
+}
+
+func (repo *RecoveryRepository) GetRecoveryByCode(code string) (*model.CartRecovery, error) {
+	var recovery model.CartRecovery
+	if err := repo.db.Where("discount_code = ?", code).First(&recovery).Error; err != nil {
+		return nil, err
+	}
+	return &recovery, nil
+}
+
+func (repo *RecoveryRepository) SaveRecovery(recovery *model.CartRecovery) error {
+	return repo.db.Save(recovery).Error
+}
+
+// MarkOpened records the first time the link of a reminder was followed.
+func (repo *RecoveryRepository) MarkOpened(id uint, at time.Time) error {
+	return repo.db.Model(&model.CartRecovery{}).
+		Where("id = ? AND opened_at IS NULL", id).
+		Update("opened_at", at).Error
+}
+
+// Redeem uses up the discount code of a reminder. It reports false when the
+// code was already used, so a code is only ever redeemed once.
+func (repo *RecoveryRepository) Redeem(id uint, at time.Time) (bool, error) {
+	result := repo.db.Model(&model.CartRecovery{}).
+		Where("id = ? AND redeemed_at IS NULL", id).
+		Update("redeemed_at", at)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// MarkConverted credits the latest reminder sent about a cart with the order
+// that was paid for it.
+func (repo *RecoveryRepository) MarkConverted(cartID, orderID uint, at time.Time) error {
+	var recovery model.CartRecovery
+	err := repo.db.Where("cart_id = ? AND status = ? AND converted_at IS NULL AND sent_at <= ?", cartID, constant.CART_RECOVERY_STATUS_SENT, at).
+		Order("sent_at DESC").
+		First(&recovery).Error
+	if err != nil {
+		return err
+	}
+
+	return repo.db.Model(&recovery).Updates(map[string]interface{}{"converted_at": at, "order_id": orderID}).Error
+}
+
+// GetRecoveryStats counts the reminders created since a point in time by
+// what became of them.
+func (repo *RecoveryRepository) GetRecoveryStats(since time.Time) (*model.RecoveryStats, error) {
+	var stats model.RecoveryStats
+	err := repo.db.Model(&model.CartRecovery{}).
+		Select("COALESCE(SUM(status = ?), 0) AS sent, COALESCE(SUM(status = ?), 0) AS failed, "+
+			"COUNT(opened_at) AS opened, COUNT(redeemed_at) AS redeemed, COUNT(converted_at) AS converted",
+			constant.CART_RECOVERY_STATUS_SENT, constant.CART_RECOVERY_STATUS_FAILED).
+		Where("created_at >= ?", since).
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
@@ -5,7 +5,10 @@ import (
 	"errors"
 	"go-online-store/internal/domain/cart/model"
 	"go-online-store/internal/domain/cart/repository"
+	"go-online-store/internal/domain/pricing"
+	productModel "go-online-store/internal/domain/product/model"
 	repoProduct "go-online-store/internal/domain/product/repository"
+	promotionService "go-online-store/internal/domain/promotion/service"
 	"go-online-store/internal/middleware/jwt"
 	customErrors "go-online-store/pkg/errors"
 	"go-online-store/pkg/logger"
@@ -18,13 +21,20 @@ import (
 type CartService struct {
 	repoCart    repository.CartRepositoryImpl
 	repoProduct repoProduct.ProductRepositoryImpl
+	promotions  promotionService.PromotionServiceImpl
 	logger      *logger.Logger
 }
 
 type CartServiceImpl interface {
-	AddToCart(ctx context.Context, productID, quantity uint) error
+	AddToCart(ctx context.Context, productID uint, variantID *uint, quantity uint) error
 	GetCartByCustomerID(ctx context.Context) (*model.Cart, error)
-	RemoveFromCart(ctx context.Context, productID uint) error
+	RemoveFromCart(ctx context.Context, productID uint, variantID *uint) error
+	UpdateCartItem(ctx context.Context, productID uint, variantID *uint, quantity uint) error
+	MergeGuestCart(ctx context.Context, guestID string) error
+	GetCartSummary(ctx context.Context) (*model.CartSummary, error)
+	ConfirmCart(ctx context.Context) (*model.CartSummary, error)
+	SaveForLater(ctx context.Context, productID uint, variantID *uint) error
+	MoveToCart(ctx context.Context, productID uint, variantID *uint) error
 }
 
 func NewInstanceCartService() CartServiceImpl {
@@ -43,23 +53,29 @@ func NewInstanceCartService() CartServiceImpl {
 	return &CartService{
 		repoCart:    cartRepo,
 		repoProduct: productRepo,
+		promotions:  promotionService.NewInstancePromotionService(),
 		logger:      log,
 	}
 }
 
-// AddToCart adds a product to the customer's shopping cart.
-func (cartService *CartService) AddToCart(ctx context.Context, productID, quantity uint) error {
+// AddToCart adds a product to the customer's shopping cart. Products that
+// have variants must be added through one of their SKUs. Adding a product
+// that is already in the cart increases the quantity of its line, and moves
+// the line back from the saved for later section.
+func (cartService *CartService) AddToCart(ctx context.Context, productID uint, variantID *uint, quantity uint) error {
 	strPId := strconv.Itoa(int(productID))
 	strQt := strconv.Itoa(int(quantity))
 
 	cartService.logger.Info("Adding product to cart. ProductID:" + strPId + " Quantity:" + strQt)
-	customerCtx, ok := jwt.FromCustomer(ctx)
-	if !ok {
-		cartService.logger.Error("CustomerID not found on ctx")
-		return customErrors.ErrCustomerIDNotFound
+	if quantity == 0 {
+		return customErrors.ErrInvalidQuantity
 	}
 
-	cart, err := cartService.repoCart.GetCartByCustomerID(customerCtx.ID)
+	cart, err := cartService.findCart(ctx)
+	if errors.Is(err, customErrors.ErrCustomerIDNotFound) {
+		cartService.logger.Error("CustomerID not found on ctx")
+		return err
+	}
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		cartService.logger.Error("Failed to retrieve cart")
 		return customErrors.ErrFailedToRetrieveCart
@@ -67,8 +83,12 @@ func (cartService *CartService) AddToCart(ctx context.Context, productID, quanti
 
 	// If cart doesn't exist, create a new cart
 	if cart == nil {
-		newCart := &model.Cart{
-			CustomerID: customerCtx.ID,
+		newCart := &model.Cart{}
+		if customerCtx, ok := jwt.FromCustomer(ctx); ok {
+			newCart.CustomerID = customerCtx.ID
+		} else {
+			guestID, _ := jwt.FromGuest(ctx)
+			newCart.GuestID = &guestID
 		}
 		if err := cartService.repoCart.CreateCart(newCart); err != nil {
 			cartService.logger.Error("Failed to create cart")
@@ -77,37 +97,135 @@ func (cartService *CartService) AddToCart(ctx context.Context, productID, quanti
 		cart = newCart
 	}
 
-	// Check if product is available and add to cart
 	product, err := cartService.repoProduct.GetByID(productID)
 	if err != nil {
 		cartService.logger.Error("Failed to retrieve product")
 		return customErrors.ErrNotFound
 	}
 
-	if product.Stok < quantity {
-		cartService.logger.Error("Product stock not available")
-		return customErrors.ErrProductStockNotAvailable
+	// Adding a product that is already in the cart adds to its line
+	total := cart.Quantity(productID, variantID) + quantity
+	if err := cartService.checkQuantity(product, variantID, total); err != nil {
+		return err
 	}
 
-	// Add the product to the cart
-	if err := cartService.repoCart.CreateCartItem(cart.ID, productID, quantity); err != nil {
+	// The line is priced at what the customer sees now
+	if err := cartService.repoCart.SetCartItemQuantity(cart.ID, productID, variantID, total, currentPrice(product, variantID)); err != nil {
 		cartService.logger.Error("Failed to add product to cart")
 		return customErrors.ErrFailedToAddToCart
 	}
+	if i := cart.Index(productID, variantID); i >= 0 && cart.Items[i].SavedForLater {
+		if err := cartService.repoCart.SetCartItemSaved(cart.ID, productID, variantID, false); err != nil {
+			cartService.logger.Error("Failed to move cart item back to cart")
+			return customErrors.ErrFailedToAddToCart
+		}
+	}
+
+	return nil
+}
+
+// UpdateCartItem sets the quantity of a product, or of one of its variants,
+// that is already in the customer's cart. A zero quantity removes it. Lines
+// saved for later are not checked against stock until they are moved back.
+func (cartService *CartService) UpdateCartItem(ctx context.Context, productID uint, variantID *uint, quantity uint) error {
+	cartService.logger.Info("Updating cart item. ProductID:" + strconv.Itoa(int(productID)) + " Quantity:" + strconv.Itoa(int(quantity)))
+	cart, err := cartService.findCart(ctx)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrCustomerIDNotFound) {
+			cartService.logger.Error("CustomerID not found on ctx")
+			return err
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return customErrors.ErrNotFound
+		}
+		cartService.logger.Error("Failed to retrieve cart")
+		return customErrors.ErrFailedToRetrieveCart
+	}
+	index := cart.Index(productID, variantID)
+	if index == -1 {
+		return customErrors.ErrNotFound
+	}
+
+	var unitPrice *float64
+	if quantity > 0 {
+		product, err := cartService.repoProduct.GetByID(productID)
+		if err != nil {
+			cartService.logger.Error("Failed to retrieve product")
+			return customErrors.ErrNotFound
+		}
+		if cart.Items[index].SavedForLater {
+			if !product.WithinPurchaseLimit(quantity) {
+				return customErrors.ErrPurchaseLimitExceeded
+			}
+		} else if err := cartService.checkQuantity(product, variantID, quantity); err != nil {
+			return err
+		}
+		unitPrice = currentPrice(product, variantID)
+	}
+
+	if err := cartService.repoCart.SetCartItemQuantity(cart.ID, productID, variantID, quantity, unitPrice); err != nil {
+		cartService.logger.Error("Failed to update cart item")
+		return customErrors.ErrFailedToAddToCart
+	}
 
+	cartService.logger.Info("Cart item updated successfully")
+	return nil
+}
+
+// checkQuantity verifies the cart may hold quantity units of a product, or
+// of one of its variants: the stock must cover them and they must be within
+// the product's purchase limit. Products that have variants must be bought
+// through one of their SKUs.
+func (cartService *CartService) checkQuantity(product *productModel.Product, variantID *uint, quantity uint) error {
+	if quantity == 0 {
+		return customErrors.ErrInvalidQuantity
+	}
+
+	available, err := cartService.available(product, variantID)
+	if err != nil {
+		return err
+	}
+
+	if !product.WithinPurchaseLimit(quantity) {
+		cartService.logger.Error("Purchase limit exceeded")
+		return customErrors.ErrPurchaseLimitExceeded
+	}
+	if available < quantity {
+		cartService.logger.Error("Product stock not available")
+		return customErrors.ErrProductStockNotAvailable
+	}
 	return nil
 }
 
-// GetCartByCustomerID retrieves the cart for a specific customer.
+// available returns the stock of a product, or of one of its variants.
+func (cartService *CartService) available(product *productModel.Product, variantID *uint) (uint, error) {
+	if variantID != nil {
+		variant, ok := product.Variant(*variantID)
+		if !ok {
+			cartService.logger.Error("Variant does not belong to product")
+			return 0, customErrors.ErrInvalidVariant
+		}
+		return variant.Stok, nil
+	}
+	if product.HasVariants() {
+		cartService.logger.Error("Variant not selected")
+		return 0, customErrors.ErrVariantRequired
+	}
+	return product.Stok, nil
+}
+
+// GetCartByCustomerID retrieves the cart for a specific customer, or for the
+// guest in ctx. A guest who has not added anything yet gets an empty cart.
 func (cartService *CartService) GetCartByCustomerID(ctx context.Context) (*model.Cart, error) {
 	cartService.logger.Info("Retrieving cart for customer")
-	customerCtx, ok := jwt.FromCustomer(ctx)
-	if !ok {
+	cart, err := cartService.findCart(ctx)
+	if errors.Is(err, customErrors.ErrCustomerIDNotFound) {
 		cartService.logger.Error("CustomerID not found on ctx")
-		return nil, customErrors.ErrCustomerIDNotFound
+		return nil, err
+	}
+	if _, guest := jwt.FromGuest(ctx); guest && errors.Is(err, gorm.ErrRecordNotFound) {
+		return &model.Cart{Items: []model.CartItem{}}, nil
 	}
-
-	cart, err := cartService.repoCart.GetCartByCustomerID(customerCtx.ID)
 	if err != nil {
 		cartService.logger.Error("Failed to retrieve cart")
 		return nil, customErrors.ErrFailedToRetrieveCart
@@ -117,22 +235,110 @@ func (cartService *CartService) GetCartByCustomerID(ctx context.Context) (*model
 	return cart, nil
 }
 
+// GetCartSummary retrieves the cart of the customer, or guest, priced with
+// the same rules checkout charges, automatic promotions included, and warns
+// about every line that changed since it was added. Lines that can no longer
+// be bought are left out of the totals.
+func (cartService *CartService) GetCartSummary(ctx context.Context) (*model.CartSummary, error) {
+	cart, err := cartService.GetCartByCustomerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := cartService.summarize(ctx, cart)
+	if err != nil {
+		cartService.logger.Error("Failed to price cart: " + err.Error())
+		return nil, err
+	}
+	return summary, nil
+}
+
+// ConfirmCart accepts the changes reported for the cart: lines to buy are
+// repriced at current prices, cut down to the stock and purchase limit left,
+// and removed when they can no longer be bought. Lines saved for later are
+// left as they are.
+func (cartService *CartService) ConfirmCart(ctx context.Context) (*model.CartSummary, error) {
+	cartService.logger.Info("Confirming cart changes")
+	cart, err := cartService.GetCartByCustomerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range cart.Active() {
+		product, err := cartService.repoProduct.GetByID(item.ProductID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			cartService.logger.Error("Failed to retrieve product")
+			return nil, err
+		}
+		if len(item.Check(product)) == 0 {
+			continue
+		}
+
+		var unitPrice *float64
+		quantity := uint(0)
+		if product != nil {
+			unitPrice = currentPrice(product, item.VariantID)
+			quantity = cartService.allowedQuantity(item.ProductID, item.VariantID, item.Quantity)
+		}
+		if err := cartService.repoCart.SetCartItemQuantity(cart.ID, item.ProductID, item.VariantID, quantity, unitPrice); err != nil {
+			cartService.logger.Error("Failed to update cart item")
+			return nil, err
+		}
+	}
+
+	return cartService.GetCartSummary(ctx)
+}
+
+func (cartService *CartService) summarize(ctx context.Context, cart *model.Cart) (*model.CartSummary, error) {
+	summary := &model.CartSummary{
+		Cart:       *cart,
+		SavedItems: cart.Saved(),
+		Warnings:   []model.CartWarning{},
+	}
+	summary.Items = cart.Active()
+
+	lines := make([]pricing.Line, 0, len(summary.Items))
+	for _, item := range summary.Items {
+		product, err := cartService.repoProduct.GetByID(item.ProductID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+
+		summary.Warnings = append(summary.Warnings, item.Check(product)...)
+		if product == nil {
+			continue
+		}
+		line, err := pricing.NewLine(product, item.VariantID, item.Quantity)
+		if err != nil {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	summary.Pricing = pricing.Calculate(lines)
+	if cartService.promotions != nil {
+		if err := cartService.promotions.ApplyPromotions(ctx, cart.CustomerID, &summary.Pricing, nil); err != nil {
+			return nil, err
+		}
+	}
+	return summary, nil
+}
+
 // RemoveFromCart removes a product from the customer's shopping cart.
-func (cartService *CartService) RemoveFromCart(ctx context.Context, productID uint) error {
+// Without a variant every line of the product is removed.
+func (cartService *CartService) RemoveFromCart(ctx context.Context, productID uint, variantID *uint) error {
 	cartService.logger.Info("Removing product from cart")
-	customerCtx, ok := jwt.FromCustomer(ctx)
-	if !ok {
+	cart, err := cartService.findCart(ctx)
+	if errors.Is(err, customErrors.ErrCustomerIDNotFound) {
 		cartService.logger.Error("CustomerID not found on ctx")
-		return customErrors.ErrCustomerIDNotFound
+		return err
 	}
-
-	cart, err := cartService.repoCart.GetCartByCustomerID(customerCtx.ID)
 	if err != nil {
 		cartService.logger.Error("Failed to retrieve cart")
 		return customErrors.ErrFailedToRetrieveCart
 	}
 
-	if err := cartService.repoCart.DeleteCartItem(cart.ID, productID); err != nil {
+	if err := cartService.repoCart.DeleteCartItem(cart.ID, productID, variantID); err != nil {
 		cartService.logger.Error("Failed to remove product from cart")
 		return customErrors.ErrFailedToRemoveFromCart
 	}
@@ -140,3 +346,152 @@ func (cartService *CartService) RemoveFromCart(ctx context.Context, productID ui
 	cartService.logger.Info("Product removed from cart successfully")
 	return nil
 }
+
+// SaveForLater moves the line of a product, or of one of its variants, out
+// of the items to buy into the saved for later section.
+func (cartService *CartService) SaveForLater(ctx context.Context, productID uint, variantID *uint) error {
+	cartService.logger.Info("Saving cart item for later. ProductID:" + strconv.Itoa(int(productID)))
+	cart, index, err := cartService.findCartItem(ctx, productID, variantID)
+	if err != nil {
+		return err
+	}
+	if cart.Items[index].SavedForLater {
+		return nil
+	}
+
+	if err := cartService.repoCart.SetCartItemSaved(cart.ID, productID, variantID, true); err != nil {
+		cartService.logger.Error("Failed to save cart item for later")
+		return err
+	}
+	return nil
+}
+
+// MoveToCart moves a line saved for later back to the items to buy. Its
+// quantity is cut down to the stock and purchase limit left; a product that
+// is out of stock stays saved.
+func (cartService *CartService) MoveToCart(ctx context.Context, productID uint, variantID *uint) error {
+	cartService.logger.Info("Moving saved item to cart. ProductID:" + strconv.Itoa(int(productID)))
+	cart, index, err := cartService.findCartItem(ctx, productID, variantID)
+	if err != nil {
+		return err
+	}
+	if !cart.Items[index].SavedForLater {
+		return nil
+	}
+
+	quantity := cart.Quantity(productID, variantID)
+	allowed := cartService.allowedQuantity(productID, variantID, quantity)
+	if allowed == 0 {
+		return customErrors.ErrProductStockNotAvailable
+	}
+	if allowed != quantity {
+		if err := cartService.repoCart.SetCartItemQuantity(cart.ID, productID, variantID, allowed, nil); err != nil {
+			cartService.logger.Error("Failed to update cart item")
+			return err
+		}
+	}
+
+	if err := cartService.repoCart.SetCartItemSaved(cart.ID, productID, variantID, false); err != nil {
+		cartService.logger.Error("Failed to move saved item to cart")
+		return err
+	}
+	return nil
+}
+
+// findCartItem returns the cart of the customer, or guest, in ctx and the
+// position of the line of a product, or of one of its variants.
+func (cartService *CartService) findCartItem(ctx context.Context, productID uint, variantID *uint) (*model.Cart, int, error) {
+	cart, err := cartService.findCart(ctx)
+	if err != nil {
+		if errors.Is(err, customErrors.ErrCustomerIDNotFound) {
+			cartService.logger.Error("CustomerID not found on ctx")
+			return nil, 0, err
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, 0, customErrors.ErrNotFound
+		}
+		cartService.logger.Error("Failed to retrieve cart")
+		return nil, 0, customErrors.ErrFailedToRetrieveCart
+	}
+
+	index := cart.Index(productID, variantID)
+	if index == -1 {
+		return nil, 0, customErrors.ErrNotFound
+	}
+	return cart, index, nil
+}
+
+// MergeGuestCart moves the cart a visitor built before signing in into the
+// signed-in customer's cart. Lines for a product, or variant, already in the
+// customer's cart are added together; each merged line is then cut down to
+// the stock and purchase limit of its product and dropped when nothing is
+// left. Having no guest cart is not an error.
+func (cartService *CartService) MergeGuestCart(ctx context.Context, guestID string) error {
+	cartService.logger.Info("Merging guest cart")
+	customerCtx, ok := jwt.FromCustomer(ctx)
+	if !ok {
+		cartService.logger.Error("CustomerID not found on ctx")
+		return customErrors.ErrCustomerIDNotFound
+	}
+
+	guestCart, err := cartService.repoCart.GetCartByGuestID(guestID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		cartService.logger.Error("Failed to retrieve guest cart: " + err.Error())
+		return customErrors.ErrFailedToRetrieveCart
+	}
+
+	// The limits are applied inside the merge, so the customer's cart never
+	// holds more than can be bought
+	if err := cartService.repoCart.MergeCarts(guestCart.ID, customerCtx.ID, cartService.allowedQuantity); err != nil {
+		cartService.logger.Error("Failed to merge guest cart: " + err.Error())
+		return err
+	}
+
+	cartService.logger.Info("Guest cart merged successfully")
+	return nil
+}
+
+// allowedQuantity returns how many of quantity units of a product, or of one
+// of its variants, the cart may keep. It is zero when the product or variant
+// is gone.
+func (cartService *CartService) allowedQuantity(productID uint, variantID *uint, quantity uint) uint {
+	product, err := cartService.repoProduct.GetByID(productID)
+	if err != nil {
+		return 0
+	}
+	available, err := cartService.available(product, variantID)
+	if err != nil {
+		return 0
+	}
+
+	allowed := min(quantity, available)
+	if product.PurchaseLimit > 0 {
+		allowed = min(allowed, product.PurchaseLimit)
+	}
+	return allowed
+}
+
+// currentPrice returns the price of a product, or of one of its variants, or
+// nil when it cannot be bought as given.
+func currentPrice(product *productModel.Product, variantID *uint) *float64 {
+	line, err := pricing.NewLine(product, variantID, 1)
+	if err != nil {
+		return nil
+	}
+	return &line.UnitPrice
+}
+
+// findCart returns the cart of the signed-in customer, or of the guest in
+// ctx, failing with gorm.ErrRecordNotFound when they have none yet.
+func (cartService *CartService) findCart(ctx context.Context) (*model.Cart, error) {
+	if customerCtx, ok := jwt.FromCustomer(ctx); ok {
+		return cartService.repoCart.GetCartByCustomerID(customerCtx.ID)
+	}
+	if guestID, ok := jwt.FromGuest(ctx); ok {
+		return cartService.repoCart.GetCartByGuestID(guestID)
+	}
+	return nil, customErrors.ErrCustomerIDNotFound
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	notificationConfig "go-online-store/config/notification"
+	recoveryConfig "go-online-store/config/recovery"
+	"go-online-store/internal/domain/cart/model"
+	"go-online-store/internal/domain/cart/repository"
+	"go-online-store/pkg/constant"
+	customErrors "go-online-store/pkg/errors"
+	"go-online-store/pkg/logger"
+	"go-online-store/pkg/notifier"
+	"go-online-store/pkg/scheduler"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// recoveryBatchSize is how many abandoned carts one run reminds about.
+const recoveryBatchSize = 100
+
+type RecoveryService struct {
+	repoRecovery repository.RecoveryRepositoryImpl
+	notifier     notifier.Notifier
+	config       *recoveryConfig.RecoveryConfig
+	logger       *logger.Logger
+}
+
+type RecoveryServiceImpl interface {
+	RecoverAbandonedCarts(ctx context.Context) (int, error)
+	StartRecovery(ctx context.Context, sched scheduler.Scheduler)
+	OpenRecovery(ctx context.Context, token string) (*model.CartRecovery, error)
+	GetRecoveryStats(ctx context.Context, since time.Time) (*model.RecoveryStats, error)
+}
+
+func NewInstanceRecoveryService() RecoveryServiceImpl {
+	log := logger.NewLogger(os.Stdout, "Service [CartRecovery] :")
+	recoveryRepo, err := repository.NewRecoveryRepository()
+	if err != nil {
+		log.Error("Failed to initialize cart recovery repository: " + err.Error())
+		return nil
+	}
+
+	sink, err := notificationConfig.LoadNotificationConfig().NewNotifier()
+	if err != nil {
+		log.Error("Failed to initialize notifier: " + err.Error())
+		return nil
+	}
+
+	return NewRecoveryService(recoveryRepo, sink, recoveryConfig.LoadRecoveryConfig(), log)
+}
+
+// NewRecoveryService builds a cart recovery service that reminds customers
+// through any notifier.
+func NewRecoveryService(recoveryRepo repository.RecoveryRepositoryImpl, sink notifier.Notifier, cfg *recoveryConfig.RecoveryConfig, log *logger.Logger) *RecoveryService {
+	return &RecoveryService{
+		repoRecovery: recoveryRepo,
+		notifier:     sink,
+		config:       cfg,
+		logger:       log,
+	}
+}
+
+// RecoverAbandonedCarts reminds customers about carts with items they have
+// not touched for the configured time and returns how many reminders were
+// sent. A failed reminder is retried on the next run until
+// MaxRecoveryAttempts is reached.
+func (recoveryService *RecoveryService) RecoverAbandonedCarts(ctx context.Context) (int, error) {
+	now := time.Now()
+	carts, err := recoveryService.repoRecovery.GetAbandonedCarts(now.Add(-recoveryService.config.AbandonedAfter), recoveryBatchSize)
+	if err != nil {
+		recoveryService.logger.Error("Failed to find abandoned carts: " + err.Error())
+		return 0, err
+	}
+
+	sent := 0
+	for _, cart := range carts {
+		recovery, err := recoveryService.repoRecovery.GetRecovery(cart.CartID, cart.UpdatedAt)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			recovery, err = recoveryService.newRecovery(cart, now)
+		}
+		if err != nil {
+			recoveryService.logger.Error("Failed to prepare cart reminder: " + err.Error())
+			continue
+		}
+
+		if err := recoveryService.notifier.Send(ctx, recovery.Message(recoveryService.config.CartURL, cart.ItemCount)); err != nil {
+			recoveryService.logger.Error(fmt.Sprintf("Failed to remind about cart %d: %s", cart.CartID, err.Error()))
+			recovery.Failed(err)
+		} else {
+			recovery.Sent(now)
+			sent++
+		}
+
+		if err := recoveryService.repoRecovery.SaveRecovery(recovery); err != nil {
+			recoveryService.logger.Error("Failed to save cart reminder: " + err.Error())
+		}
+	}
+
+	if sent > 0 {
+		recoveryService.logger.Info(fmt.Sprintf("Sent %d abandoned cart reminders", sent))
+	}
+	return sent, nil
+}
+
+// StartRecovery scans for abandoned carts on sched every configured interval
+// until ctx is done.
+func (recoveryService *RecoveryService) StartRecovery(ctx context.Context, sched scheduler.Scheduler) {
+	sched.Every(ctx, recoveryService.config.ScanInterval, func(ctx context.Context) {
+		recoveryService.RecoverAbandonedCarts(ctx)
+	})
+}
+
+// OpenRecovery records that the link of a reminder was followed and returns
+// the reminder, so the storefront can offer its discount code.
+func (recoveryService *RecoveryService) OpenRecovery(ctx context.Context, token string) (*model.CartRecovery, error) {
+	recoveryService.logger.Info("Opening cart reminder")
+	if token == "" {
+		return nil, customErrors.ErrNotFound
+	}
+
+	recovery, err := recoveryService.repoRecovery.GetRecoveryByToken(token)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, customErrors.ErrNotFound
+		}
+		recoveryService.logger.Error("Failed to fetch cart reminder: " + err.Error())
+		return nil, err
+	}
+	if recovery.Status != constant.CART_RECOVERY_STATUS_SENT {
+		return nil, customErrors.ErrNotFound
+	}
+
+	if recovery.OpenedAt == nil {
+		now := time.Now()
+		if err := recoveryService.repoRecovery.MarkOpened(recovery.ID, now); err != nil {
+			recoveryService.logger.Error("Failed to record opened reminder: " + err.Error())
+			return nil, err
+		}
+		recovery.OpenedAt = &now
+	}
+	return recovery, nil
+}
+
+// GetRecoveryStats measures the reminders sent since a point in time.
+func (recoveryService *RecoveryService) GetRecoveryStats(ctx context.Context, since time.Time) (*model.RecoveryStats, error) {
+	recoveryService.logger.Info("Fetching cart recovery stats")
+	stats, err := recoveryService.repoRecovery.GetRecoveryStats(since)
+	if err != nil {
+		recoveryService.logger.Error("Failed to fetch cart recovery stats: " + err.Error())
+		return nil, err
+	}
+
+	if stats.Sent > 0 {
+		stats.ConversionRate = float64(stats.Converted) / float64(stats.Sent)
+	}
+	return stats, nil
+}
+
+// newRecovery prepares the reminder about an abandoned cart, with a discount
+// code when the campaign offers one.
+func (recoveryService *RecoveryService) newRecovery(cart model.AbandonedCart, now time.Time) (*model.CartRecovery, error) {
+	token, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	recovery := &model.CartRecovery{
+		CartID:        cart.CartID,
+		CartUpdatedAt: cart.UpdatedAt,
+		CustomerID:    cart.CustomerID,
+		Recipient:     cart.Email,
+		Token:         token,
+		Status:        constant.CART_RECOVERY_STATUS_PENDING,
+	}
+
+	if rate := recoveryService.config.DiscountRate; rate > 0 {
+		code, err := discountCode()
+		if err != nil {
+			return nil, err
+		}
+		expiresAt := now.Add(recoveryService.config.CodeTTL)
+		recovery.DiscountCode = &code
+		recovery.DiscountRate = rate
+		recovery.CodeExpiresAt = &expiresAt
+	}
+	return recovery, nil
+}
+
+func randomToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// discountCode returns a code customers can type, e.g. "BACK-K7QX2MZD".
+func discountCode() (string, error) {
+	buf := make([]byte, 5)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "BACK-" + base32.StdEncoding.EncodeToString(buf), nil
+}
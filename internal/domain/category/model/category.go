@@ -0,0 +1,104 @@
+package model
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type Category struct {
+	ID        uint        `json:"id" gorm:"primaryKey"`
+	ParentID  *uint       `json:"parent_id" gorm:"column:parent_id;index"`
+	Name      string      `json:"name" gorm:"column:name;not null"`
+	Slug      string      `json:"slug" gorm:"column:slug;size:191;not null;uniqueIndex"`
+	SortOrder int         `json:"sort_order" gorm:"column:sort_order;not null;default:0"`
+	Children  []*Category `json:"children,omitempty" gorm:"-"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+func (Category) TableName() string {
+	return "Category"
+}
+
+// Slugify turns a category name into its URL slug, so that "Shoes",
+// " shoes " and "SHOES" all map to "shoes".
+func Slugify(name string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && sb.Len() > 0 {
+			sb.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "-")
+}
+
+// BuildTree links flat categories into trees and returns the roots, with
+// siblings ordered by sort order then name.
+func BuildTree(categories []*Category) []*Category {
+	byID := make(map[uint]*Category, len(categories))
+	for _, category := range categories {
+		category.Children = nil
+		byID[category.ID] = category
+	}
+
+	var roots []*Category
+	for _, category := range categories {
+		if category.ParentID != nil {
+			if parent, ok := byID[*category.ParentID]; ok {
+				parent.Children = append(parent.Children, category)
+				continue
+			}
+		}
+		roots = append(roots, category)
+	}
+
+	sortSiblings(roots)
+	return roots
+}
+
+// DescendantIDs returns rootID and the IDs of every category below it.
+func DescendantIDs(categories []*Category, rootID uint) []uint {
+	children := make(map[uint][]uint)
+	for _, category := range categories {
+		if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category.ID)
+		}
+	}
+
+	ids := []uint{rootID}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, children[ids[i]]...)
+	}
+	return ids
+}
+
+// IsDescendant reports whether candidateID is rootID or lies below it.
+func IsDescendant(categories []*Category, rootID, candidateID uint) bool {
+	for _, id := range DescendantIDs(categories, rootID) {
+		if id == candidateID {
+			return true
+		}
+	}
+	return false
+}
+
+func sortSiblings(categories []*Category) {
+	sort.SliceStable(categories, func(i, j int) bool {
+		if categories[i].SortOrder != categories[j].SortOrder {
+			return categories[i].SortOrder < categories[j].SortOrder
+		}
+		return categories[i].Name < categories[j].Name
+	})
+	for _, category := range categories {
+		sortSiblings(category.Children)
+	}
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	mysql "go-online-store/config/database/my_sql_db"
+	"go-online-store/internal/domain/category/model"
+
+	"gorm.io/gorm"
+)
+
+type CategoryRepository struct {
+	db *gorm.DB
+}
+
+type CategoryRepositoryImpl interface {
+	Create(category *model.Category) error
+	Update(category *model.Category) error
+	Delete(id uint) error
+	GetByID(id uint) (*model.Category, error)
+	GetBySlug(slug string) (*model.Category, error)
+	GetAll() ([]*model.Category, error)
+	ReparentChildren(fromID uint, toID *uint) error
+}
+
+func NewCategoryRepository() (CategoryRepositoryImpl, error) {
+	db, err := mysql.ConnectDatabase()
+	if err != nil {
+		return nil, err
+	}
+
+	db.AutoMigrate(&model.Category{})
+	return &CategoryRepository{db: db}, nil
+}
+
+func (repo *CategoryRepository) Create(category *model.Category) error {
+	return repo.db.Create(category).Error
+}
+
+func (repo *CategoryRepository) Update(category *model.Category) error {
+	return repo.db.Save(category).Error
+}
+
+func (repo *CategoryRepository) Delete(id uint) error {
+	return repo.db.Delete(&model.Category{}, id).Error
+}
+
+func (repo *CategoryRepository) GetByID(id uint) (*model.Category, error) {
+	var category model.Category
+	if err := repo.db.First(&category, id).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (repo *CategoryRepository) GetBySlug(slug string) (*model.Category, error) {
+	var category model.Category
+	if err := repo.db.Where("slug = ?", slug).First(&category).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (repo *CategoryRepository) GetAll() ([]*model.Category, error) {
+	var categories []*model.Category
+	if err := repo.db.Order("sort_order, name").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// ReparentChildren moves the direct children of a category under another parent.
+func (repo *CategoryRepository) ReparentChildren(fromID uint, toID *uint) error {
+	return repo.db.Model(&model.Category{}).
+		Where("parent_id = ?", fromID).
+		Update("parent_id", toID).Error
+}
@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-online-store/internal/domain/category/model"
+	"go-online-store/internal/domain/category/repository"
+	repoProduct "go-online-store/internal/domain/product/repository"
+	customErrors "go-online-store/pkg/errors"
+	"go-online-store/pkg/logger"
+	"os"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type CategoryService struct {
+	repoCategory repository.CategoryRepositoryImpl
+	repoProduct  repoProduct.ProductRepositoryImpl
+	logger       *logger.Logger
+}
+
+type CategoryServiceImpl interface {
+	GetCategoryTree(ctx context.Context) ([]*model.Category, error)
+	GetCategory(ctx context.Context, id uint) (*model.Category, error)
+	CreateCategory(ctx context.Context, category model.Category) (*model.Category, error)
+	UpdateCategory(ctx context.Context, category model.Category) (*model.Category, error)
+	DeleteCategory(ctx context.Context, id uint) error
+	MergeCategory(ctx context.Context, sourceID uint, targetID uint) (*model.Category, error)
+	MigrateLegacyCategories(ctx context.Context) error
+}
+
+func NewInstanceCategoryService() CategoryServiceImpl {
+	log := logger.NewLogger(os.Stdout, "Service [Category] :")
+	categoryRepo, err := repository.NewCategoryRepository()
+	if err != nil {
+		log.Error("Failed to initialize category repository: " + err.Error())
+		return nil
+	}
+
+	productRepo, err := repoProduct.NewProductRepository()
+	if err != nil {
+		log.Error("Failed to initialize product repository: " + err.Error())
+		return nil
+	}
+
+	categoryService := &CategoryService{
+		repoCategory: categoryRepo,
+		repoProduct:  productRepo,
+		logger:       log,
+	}
+
+	if err := categoryService.MigrateLegacyCategories(context.Background()); err != nil {
+		log.Error("Failed to migrate legacy categories: " + err.Error())
+	}
+
+	return categoryService
+}
+
+// GetCategoryTree returns every root category with its nested children.
+func (categoryService *CategoryService) GetCategoryTree(ctx context.Context) ([]*model.Category, error) {
+	categories, err := categoryService.repoCategory.GetAll()
+	if err != nil {
+		categoryService.logger.Error("Failed to fetch categories: " + err.Error())
+		return nil, err
+	}
+
+	return model.BuildTree(categories), nil
+}
+
+// GetCategory returns a category with its subtree.
+func (categoryService *CategoryService) GetCategory(ctx context.Context, id uint) (*model.Category, error) {
+	categories, err := categoryService.repoCategory.GetAll()
+	if err != nil {
+		categoryService.logger.Error("Failed to fetch categories: " + err.Error())
+		return nil, err
+	}
+
+	model.BuildTree(categories)
+	for _, category := range categories {
+		if category.ID == id {
+			return category, nil
+		}
+	}
+	return nil, customErrors.ErrNotFound
+}
+
+func (categoryService *CategoryService) CreateCategory(ctx context.Context, category model.Category) (*model.Category, error) {
+	categoryService.logger.Info("Creating category: " + category.Name)
+	category.ID = 0
+	if err := categoryService.prepare(&category, nil); err != nil {
+		return nil, err
+	}
+
+	if err := categoryService.repoCategory.Create(&category); err != nil {
+		categoryService.logger.Error("Failed to create category: " + err.Error())
+		return nil, err
+	}
+	return &category, nil
+}
+
+// UpdateCategory replaces the name, slug, parent and sort order of a category.
+func (categoryService *CategoryService) UpdateCategory(ctx context.Context, category model.Category) (*model.Category, error) {
+	categoryService.logger.Info("Updating category with ID: " + fmt.Sprint(category.ID))
+	existing, err := categoryService.getByID(category.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	category.CreatedAt = existing.CreatedAt
+	if err := categoryService.prepare(&category, existing); err != nil {
+		return nil, err
+	}
+
+	if err := categoryService.repoCategory.Update(&category); err != nil {
+		categoryService.logger.Error("Failed to update category: " + err.Error())
+		return nil, err
+	}
+
+	if category.Name != existing.Name {
+		if err := categoryService.repoProduct.RenameCategory(category.ID, category.Name); err != nil {
+			categoryService.logger.Error("Failed to rename category on products: " + err.Error())
+			return nil, err
+		}
+	}
+	return &category, nil
+}
+
+// DeleteCategory removes an empty category.
+func (categoryService *CategoryService) DeleteCategory(ctx context.Context, id uint) error {
+	categoryService.logger.Info("Deleting category with ID: " + fmt.Sprint(id))
+	categories, err := categoryService.repoCategory.GetAll()
+	if err != nil {
+		return err
+	}
+
+	if _, err := categoryService.getByID(id); err != nil {
+		return err
+	}
+	if len(model.DescendantIDs(categories, id)) > 1 {
+		return customErrors.ErrCategoryInUse
+	}
+
+	count, err := categoryService.repoProduct.CountByCategoryIDs([]uint{id})
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return customErrors.ErrCategoryInUse
+	}
+
+	return categoryService.repoCategory.Delete(id)
+}
+
+// MergeCategory moves the products and subcategories of source into target
+// and deletes source. It is used to fold duplicates such as "Footwear" into
+// "Shoes".
+func (categoryService *CategoryService) MergeCategory(ctx context.Context, sourceID uint, targetID uint) (*model.Category, error) {
+	categoryService.logger.Info(fmt.Sprintf("Merging category %d into %d", sourceID, targetID))
+	if sourceID == targetID {
+		return nil, customErrors.ErrInvalidCategory
+	}
+
+	if _, err := categoryService.getByID(sourceID); err != nil {
+		return nil, err
+	}
+	target, err := categoryService.getByID(targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := categoryService.repoCategory.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	if model.IsDescendant(categories, sourceID, targetID) {
+		return nil, customErrors.ErrInvalidCategory
+	}
+
+	if err := categoryService.repoProduct.ReassignCategory(sourceID, targetID, target.Name); err != nil {
+		categoryService.logger.Error("Failed to move products: " + err.Error())
+		return nil, err
+	}
+	if err := categoryService.repoCategory.ReparentChildren(sourceID, &targetID); err != nil {
+		categoryService.logger.Error("Failed to move subcategories: " + err.Error())
+		return nil, err
+	}
+	if err := categoryService.repoCategory.Delete(sourceID); err != nil {
+		categoryService.logger.Error("Failed to delete merged category: " + err.Error())
+		return nil, err
+	}
+
+	return categoryService.GetCategory(ctx, targetID)
+}
+
+// MigrateLegacyCategories creates a category for every distinct free-text
+// product category and links the products to it. Strings that only differ
+// in case, spacing or punctuation share one category. It is safe to run
+// repeatedly; only unlinked products are touched.
+func (categoryService *CategoryService) MigrateLegacyCategories(ctx context.Context) error {
+	legacy, err := categoryService.repoProduct.LegacyCategories()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range legacy {
+		slug := model.Slugify(name)
+		if slug == "" {
+			continue
+		}
+
+		category, err := categoryService.repoCategory.GetBySlug(slug)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			category = &model.Category{Name: strings.TrimSpace(name), Slug: slug}
+			err = categoryService.repoCategory.Create(category)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := categoryService.repoProduct.AssignLegacyCategory(name, category.ID, category.Name); err != nil {
+			return err
+		}
+		categoryService.logger.Info("Migrated legacy category " + name + " to " + category.Slug)
+	}
+
+	return nil
+}
+
+// prepare normalizes the slug and checks slug uniqueness and the parent.
+func (categoryService *CategoryService) prepare(category *model.Category, existing *model.Category) error {
+	category.Name = strings.TrimSpace(category.Name)
+	if category.Name == "" {
+		return customErrors.ErrInvalidCategory
+	}
+
+	if category.Slug == "" {
+		category.Slug = model.Slugify(category.Name)
+	} else {
+		category.Slug = model.Slugify(category.Slug)
+	}
+	if category.Slug == "" {
+		return customErrors.ErrInvalidCategory
+	}
+
+	other, err := categoryService.repoCategory.GetBySlug(category.Slug)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if other != nil && other.ID != category.ID {
+		return customErrors.ErrDuplicateSlug
+	}
+
+	if category.ParentID == nil {
+		return nil
+	}
+
+	if _, err := categoryService.getByID(*category.ParentID); err != nil {
+		return customErrors.ErrInvalidCategory
+	}
+
+	// A category cannot be moved below itself
+	if existing != nil {
+		categories, err := categoryService.repoCategory.GetAll()
+		if err != nil {
+			return err
+		}
+		if model.IsDescendant(categories, existing.ID, *category.ParentID) {
+			return customErrors.ErrInvalidCategory
+		}
+	}
+
+	return nil
+}
+
+func (categoryService *CategoryService) getByID(id uint) (*model.Category, error) {
+	category, err := categoryService.repoCategory.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, customErrors.ErrNotFound
+		}
+		return nil, err
+	}
+	return category, nil
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"go-online-store/pkg/constant"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
@@ -18,6 +19,7 @@ type Customer struct {
 	PostalCode       string    `gorm:"column:postal_code" json:"postal_code"`
 	Country          string    `gorm:"column:country" json:"country"`
 	DateOfBirth      time.Time `gorm:"column:date_of_birth" json:"date_of_birth"`
+	Role             string    `gorm:"column:role;not null;default:CUSTOMER" json:"role"`
 	RegistrationDate time.Time `json:"registration_date"`
 }
 
@@ -34,6 +36,11 @@ func (u *Customer) CheckPassword(password string) error {
 	return comparePasswords(u.Password, password)
 }
 
+// IsAdmin reports whether the customer has the admin role.
+func (u *Customer) IsAdmin() bool {
+	return u.Role == constant.ROLE_ADMIN
+}
+
 func (Customer) TableName() string {
 	return "Customer"
 }
@@ -3,6 +3,7 @@ package repository
 import (
 	mysql "go-online-store/config/database/my_sql_db"
 	"go-online-store/internal/domain/customer/model"
+	"go-online-store/pkg/constant"
 	"time"
 
 	"gorm.io/gorm"
@@ -39,6 +40,9 @@ func (customerSql *UserRepository) GetUserByEmail(email string) (model.Customer,
 
 func (customerSql *UserRepository) CreateUser(customer model.Customer) (model.Customer, error) {
 	customer.RegistrationDate = time.Now()
+	if customer.Role == "" {
+		customer.Role = constant.ROLE_CUSTOMER
+	}
 	err := customerSql.db.Create(&customer).Error
 	if err != nil {
 		return customer, err
@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+// MaxKeyLength is the longest Idempotency-Key accepted.
+const MaxKeyLength = 255
+
+// IdempotencyKey is a request a customer sent with an Idempotency-Key header
+// and, once it has been handled, the response to replay for retries. Keys
+// belong to one customer; RequestHash fingerprints the method, URL and body
+// so a key reused for a different request can be told apart from a retry.
+type IdempotencyKey struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	CustomerID  uint      `json:"customer_id" gorm:"not null;uniqueIndex:idx_idempotency_key"`
+	Key         string    `json:"key" gorm:"column:idempotency_key;size:255;not null;uniqueIndex:idx_idempotency_key"`
+	RequestHash string    `json:"request_hash" gorm:"size:64;not null"`
+	StatusCode  int       `json:"status_code" gorm:"not null;default:0"`
+	ContentType string    `json:"content_type" gorm:"size:100"`
+	Body        []byte    `json:"-"`
+	ExpiresAt   time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (IdempotencyKey) TableName() string {
+	return "IdempotencyKey"
+}
+
+// Completed reports whether the request has been handled and its response
+// stored.
+func (k *IdempotencyKey) Completed() bool {
+	return k.StatusCode != 0
+}
+
+// Expired reports whether the key is past its retention window at now.
+func (k *IdempotencyKey) Expired(now time.Time) bool {
+	return !now.Before(k.ExpiresAt)
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	mysql "go-online-store/config/database/my_sql_db"
+	"go-online-store/internal/domain/idempotency/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IdempotencyRepository struct {
+	db *gorm.DB
+}
+
+type IdempotencyRepositoryImpl interface {
+	CreateKey(key *model.IdempotencyKey) (bool, error)
+	GetKey(customerID uint, key string) (*model.IdempotencyKey, error)
+	SaveKey(key *model.IdempotencyKey) error
+	DeleteKey(id uint) error
+	DeleteExpiredKeys(now time.Time) (int64, error)
+}
+
+func NewIdempotencyRepository() (IdempotencyRepositoryImpl, error) {
+	db, err := mysql.ConnectDatabase()
+	if err != nil {
+		return nil, err
+	}
+
+	db.AutoMigrate(&model.IdempotencyKey{})
+	return &IdempotencyRepository{db: db}, nil
+}
+
+// CreateKey stores a new key and reports false without storing anything when
+// the customer already has that key, e.g. because a retry raced this request.
+func (repo *IdempotencyRepository) CreateKey(key *model.IdempotencyKey) (bool, error) {
+	result := repo.db.Clauses(clause.OnConflict{DoNothing: true}).Create(key)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (repo *IdempotencyRepository) GetKey(customerID uint, key string) (*model.IdempotencyKey, error) {
+	var record model.IdempotencyKey
+	if err := repo.db.Where("customer_id = ? AND idempotency_key = ?", customerID, key).First(&record).Error; err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (repo *IdempotencyRepository) SaveKey(key *model.IdempotencyKey) error {
+	return repo.db.Save(key).Error
+}
+
+func (repo *IdempotencyRepository) DeleteKey(id uint) error {
+	return repo.db.Delete(&model.IdempotencyKey{}, id).Error
+}
+
+// DeleteExpiredKeys deletes the keys past their retention window and returns
+// how many were deleted.
+func (repo *IdempotencyRepository) DeleteExpiredKeys(now time.Time) (int64, error) {
+	result := repo.db.Where("expires_at <= ?", now).Delete(&model.IdempotencyKey{})
+	return result.RowsAffected, result.Error
+}
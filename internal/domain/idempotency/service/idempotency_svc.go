@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	idempotencyConfig "go-online-store/config/idempotency"
+	"go-online-store/internal/domain/idempotency/model"
+	"go-online-store/internal/domain/idempotency/repository"
+	customErrors "go-online-store/pkg/errors"
+	"go-online-store/pkg/logger"
+	"go-online-store/pkg/scheduler"
+	"os"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type IdempotencyService struct {
+	repoIdempotency repository.IdempotencyRepositoryImpl
+	config          *idempotencyConfig.IdempotencyConfig
+	logger          *logger.Logger
+}
+
+type IdempotencyServiceImpl interface {
+	Begin(ctx context.Context, customerID uint, key string, requestHash string) (*model.IdempotencyKey, error)
+	Complete(ctx context.Context, record *model.IdempotencyKey, statusCode int, contentType string, body []byte) error
+	Release(ctx context.Context, record *model.IdempotencyKey) error
+	PurgeExpiredKeys(ctx context.Context) (int64, error)
+	StartPurger(ctx context.Context, sched scheduler.Scheduler)
+}
+
+func NewInstanceIdempotencyService() IdempotencyServiceImpl {
+	log := logger.NewLogger(os.Stdout, "Service [Idempotency] :")
+	idempotencyRepo, err := repository.NewIdempotencyRepository()
+	if err != nil {
+		log.Error("Failed to initialize idempotency repository: " + err.Error())
+		return nil
+	}
+
+	return NewIdempotencyService(idempotencyRepo, idempotencyConfig.LoadIdempotencyConfig(), log)
+}
+
+// NewIdempotencyService builds an idempotency service on any key repository.
+func NewIdempotencyService(idempotencyRepo repository.IdempotencyRepositoryImpl, cfg *idempotencyConfig.IdempotencyConfig, log *logger.Logger) *IdempotencyService {
+	return &IdempotencyService{
+		repoIdempotency: idempotencyRepo,
+		config:          cfg,
+		logger:          log,
+	}
+}
+
+// Begin claims key for a request of the customer fingerprinted by
+// requestHash. It returns a new, incomplete record when the request should be
+// handled and the stored record when it is a retry of a completed request
+// whose response should be replayed. A key used for a different request
+// fails with ErrIdempotencyKeyReused; one whose request is still being
+// handled with ErrIdempotencyKeyInUse.
+func (svc *IdempotencyService) Begin(ctx context.Context, customerID uint, key string, requestHash string) (*model.IdempotencyKey, error) {
+	key = strings.TrimSpace(key)
+	if key == "" || len(key) > model.MaxKeyLength {
+		return nil, customErrors.ErrInvalidIdempotencyKey
+	}
+
+	now := time.Now()
+	record := &model.IdempotencyKey{
+		CustomerID:  customerID,
+		Key:         key,
+		RequestHash: requestHash,
+		ExpiresAt:   now.Add(svc.config.Retention),
+	}
+
+	// A second attempt covers a key that expired or was released between
+	// the insert and the lookup
+	for attempt := 0; attempt < 2; attempt++ {
+		created, err := svc.repoIdempotency.CreateKey(record)
+		if err != nil {
+			svc.logger.Error("Failed to store idempotency key: " + err.Error())
+			return nil, err
+		}
+		if created {
+			return record, nil
+		}
+
+		existing, err := svc.repoIdempotency.GetKey(customerID, key)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			svc.logger.Error("Failed to retrieve idempotency key: " + err.Error())
+			return nil, err
+		}
+		if existing.Expired(now) {
+			if err := svc.repoIdempotency.DeleteKey(existing.ID); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if existing.RequestHash != requestHash {
+			return nil, customErrors.ErrIdempotencyKeyReused
+		}
+		if !existing.Completed() {
+			return nil, customErrors.ErrIdempotencyKeyInUse
+		}
+		return existing, nil
+	}
+	return nil, customErrors.ErrIdempotencyKeyInUse
+}
+
+// Complete stores the response to a request claimed with Begin so retries
+// replay it.
+func (svc *IdempotencyService) Complete(ctx context.Context, record *model.IdempotencyKey, statusCode int, contentType string, body []byte) error {
+	record.StatusCode = statusCode
+	record.ContentType = contentType
+	record.Body = body
+	if err := svc.repoIdempotency.SaveKey(record); err != nil {
+		svc.logger.Error("Failed to store idempotent response: " + err.Error())
+		return err
+	}
+	return nil
+}
+
+// Release gives up a key claimed with Begin without storing a response, so a
+// retry handles the request again.
+func (svc *IdempotencyService) Release(ctx context.Context, record *model.IdempotencyKey) error {
+	if err := svc.repoIdempotency.DeleteKey(record.ID); err != nil {
+		svc.logger.Error("Failed to release idempotency key: " + err.Error())
+		return err
+	}
+	return nil
+}
+
+// PurgeExpiredKeys deletes the keys past their retention window and returns
+// how many were deleted.
+func (svc *IdempotencyService) PurgeExpiredKeys(ctx context.Context) (int64, error) {
+	purged, err := svc.repoIdempotency.DeleteExpiredKeys(time.Now())
+	if err != nil {
+		svc.logger.Error("Failed to purge idempotency keys: " + err.Error())
+		return 0, err
+	}
+	if purged > 0 {
+		svc.logger.Info(fmt.Sprintf("Purged %d expired idempotency keys", purged))
+	}
+	return purged, nil
+}
+
+// StartPurger purges expired keys on sched every configured interval until
+// ctx is done.
+func (svc *IdempotencyService) StartPurger(ctx context.Context, sched scheduler.Scheduler) {
+	sched.Every(ctx, svc.config.PurgeInterval, func(ctx context.Context) {
+		svc.PurgeExpiredKeys(ctx)
+	})
+}
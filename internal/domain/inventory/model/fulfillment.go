@@ -0,0 +1,129 @@
+package model
+
+import (
+	"errors"
+	"sort"
+)
+
+var ErrUnfulfillable = errors.New("not enough stock at any warehouse")
+
+// StockKey identifies the stock of a product or variant at a warehouse.
+type StockKey struct {
+	WarehouseID uint
+	ProductID   uint
+	VariantID   uint
+}
+
+// NewStockKey returns the key of a stock level; variantID is nil for
+// products without variants.
+func NewStockKey(warehouseID uint, productID uint, variantID *uint) StockKey {
+	key := StockKey{WarehouseID: warehouseID, ProductID: productID}
+	if variantID != nil {
+		key.VariantID = *variantID
+	}
+	return key
+}
+
+// StockLevels indexes warehouse stock by StockKey.
+func StockLevels(stock []WarehouseStock) map[StockKey]int {
+	levels := make(map[StockKey]int, len(stock))
+	for _, s := range stock {
+		levels[NewStockKey(s.WarehouseID, s.ProductID, s.VariantID)] += s.Quantity
+	}
+	return levels
+}
+
+// FulfillmentLine is one line of an order to be fulfilled.
+type FulfillmentLine struct {
+	ProductID uint
+	VariantID *uint
+	Quantity  uint
+}
+
+// Allocation assigns part or all of a line to a warehouse. Line is the index
+// of the line it fulfills.
+type Allocation struct {
+	Line        int
+	WarehouseID uint
+	Quantity    uint
+}
+
+// Allocate picks the warehouses that fulfill each line. Warehouses are tried
+// closest to the location first; among equally close ones, those that can
+// ship more of the order in full come first to keep shipments together, then
+// higher priority. A line is shipped from a single warehouse when one has
+// enough stock and split across warehouses otherwise. It fails with
+// ErrUnfulfillable when the active warehouses together hold too little.
+func Allocate(lines []FulfillmentLine, warehouses []Warehouse, levels map[StockKey]int, location Location) ([]Allocation, error) {
+	available := make(map[StockKey]int, len(levels))
+	for key, quantity := range levels {
+		available[key] = quantity
+	}
+
+	active := make([]Warehouse, 0, len(warehouses))
+	coverage := make(map[uint]int)
+	for _, w := range warehouses {
+		if !w.Active {
+			continue
+		}
+		active = append(active, w)
+		for _, line := range lines {
+			if available[NewStockKey(w.ID, line.ProductID, line.VariantID)] >= int(line.Quantity) {
+				coverage[w.ID]++
+			}
+		}
+	}
+
+	sort.SliceStable(active, func(i, j int) bool {
+		a, b := active[i], active[j]
+		if pa, pb := a.Proximity(location), b.Proximity(location); pa != pb {
+			return pa > pb
+		}
+		if coverage[a.ID] != coverage[b.ID] {
+			return coverage[a.ID] > coverage[b.ID]
+		}
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		return a.ID < b.ID
+	})
+
+	var allocations []Allocation
+	for i, line := range lines {
+		whole := false
+		for _, w := range active {
+			key := NewStockKey(w.ID, line.ProductID, line.VariantID)
+			if available[key] >= int(line.Quantity) {
+				available[key] -= int(line.Quantity)
+				allocations = append(allocations, Allocation{Line: i, WarehouseID: w.ID, Quantity: line.Quantity})
+				whole = true
+				break
+			}
+		}
+		if whole {
+			continue
+		}
+
+		remaining := int(line.Quantity)
+		var split []Allocation
+		for _, w := range active {
+			key := NewStockKey(w.ID, line.ProductID, line.VariantID)
+			take := min(available[key], remaining)
+			if take <= 0 {
+				continue
+			}
+			available[key] -= take
+			remaining -= take
+			split = append(split, Allocation{Line: i, WarehouseID: w.ID, Quantity: uint(take)})
+			if remaining == 0 {
+				break
+			}
+		}
+		if remaining > 0 {
+			return nil, ErrUnfulfillable
+		}
+		allocations = append(allocations, split...)
+	}
+
+	return allocations, nil
+}
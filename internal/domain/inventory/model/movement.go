@@ -0,0 +1,92 @@
+package model
+
+import "time"
+
+// StockMovement is one append-only entry of the inventory ledger. Quantity is
+// signed: positive entries add stock, negative entries remove it.
+//
+// The stock of a variant is the sum of the movements with its VariantID. The
+// stock of a product is the sum of all of its movements, including those of
+// its variants, since product stock is the total of its SKUs. The stock held
+// at a warehouse is the sum of the movements with its WarehouseID.
+type StockMovement struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	ProductID   uint      `json:"product_id" gorm:"column:product_id;not null;index"`
+	VariantID   *uint     `json:"variant_id,omitempty" gorm:"column:variant_id;index"`
+	WarehouseID *uint     `json:"warehouse_id,omitempty" gorm:"column:warehouse_id;index"`
+	Quantity    int       `json:"quantity" gorm:"column:quantity;not null"`
+	Reason      string    `json:"reason" gorm:"column:reason;size:16;not null"`
+	Actor       string    `json:"actor" gorm:"column:actor"`
+	Reference   string    `json:"reference" gorm:"column:reference;index"`
+	Note        string    `json:"note" gorm:"column:note"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (StockMovement) TableName() string {
+	return "StockMovement"
+}
+
+// StockChange attributes the movements of a stock write: who made it, the
+// order, delivery or document it belongs to and the warehouse it happens at.
+// The reason is set by the kind of write. Without a warehouse, added stock
+// goes to the default warehouse and removed stock is taken from the
+// warehouses that hold it, the default one first.
+type StockChange struct {
+	Actor       string
+	Reference   string
+	Note        string
+	WarehouseID *uint
+}
+
+// Movement returns a ledger entry for this change.
+func (c StockChange) Movement(productID uint, variantID *uint, quantity int, reason string) StockMovement {
+	return StockMovement{
+		ProductID:   productID,
+		VariantID:   variantID,
+		WarehouseID: c.WarehouseID,
+		Quantity:    quantity,
+		Reason:      reason,
+		Actor:       c.Actor,
+		Reference:   c.Reference,
+		Note:        c.Note,
+	}
+}
+
+// MovementQuery selects the stock history of a product, or of one variant.
+type MovementQuery struct {
+	ProductID uint
+	VariantID *uint
+	Offset    int
+	Limit     int
+}
+
+// StockHistory is a page of the movements of a product or variant along with
+// its stored stock and the stock derived from the ledger.
+type StockHistory struct {
+	ProductID   uint            `json:"product_id"`
+	VariantID   *uint           `json:"variant_id,omitempty"`
+	Stock       uint            `json:"stock"`
+	LedgerStock int             `json:"ledger_stock"`
+	Movements   []StockMovement `json:"movements"`
+	Total       int64           `json:"-"`
+}
+
+// StockDiscrepancy is a product or variant whose stored stock differs from
+// the sum of its ledger.
+type StockDiscrepancy struct {
+	ProductID   uint   `json:"product_id"`
+	VariantID   *uint  `json:"variant_id,omitempty"`
+	Name        string `json:"name"`
+	SKU         string `json:"sku,omitempty"`
+	Stock       uint   `json:"stock"`
+	LedgerStock int    `json:"ledger_stock"`
+	Difference  int    `json:"difference"`
+}
+
+// ReconcileReport lists the discrepancies found by a reconciliation run.
+// Fixed is set when adjustments were recorded to close them.
+type ReconcileReport struct {
+	Checked       int                `json:"checked"`
+	Discrepancies []StockDiscrepancy `json:"discrepancies"`
+	Fixed         bool               `json:"fixed"`
+}
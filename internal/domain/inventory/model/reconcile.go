@@ -0,0 +1,135 @@
+package model
+
+import (
+	productModel "go-online-store/internal/domain/product/model"
+	"go-online-store/pkg/constant"
+	"sort"
+)
+
+// StockAdjustment is a manual stock movement such as a delivery, a count
+// correction or a customer return.
+type StockAdjustment struct {
+	VariantID   *uint
+	WarehouseID *uint
+	Quantity    int
+	Reason      string
+	Reference   string
+	Note        string
+}
+
+// ManualReasons are the reasons a stock adjustment may be recorded with; the
+// others are only recorded by checkout and payment.
+var ManualReasons = []string{
+	constant.STOCK_REASON_RESTOCK,
+	constant.STOCK_REASON_ADJUSTMENT,
+	constant.STOCK_REASON_RETURN,
+}
+
+// IsManualReason reports whether reason may be used for a stock adjustment.
+func IsManualReason(reason string) bool {
+	for _, r := range ManualReasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// OpeningBalances returns the movements that start the ledger of a product
+// that has none, so that its ledger matches its current stock: one per
+// variant and one for the product-level remainder.
+func OpeningBalances(product *productModel.Product, variants []productModel.ProductVariant) []StockMovement {
+	change := StockChange{Actor: constant.STOCK_ACTOR_SYSTEM, Note: "opening balance"}
+
+	var movements []StockMovement
+	rest := int(product.Stok)
+	for i := range variants {
+		variant := &variants[i]
+		if variant.Stok == 0 {
+			continue
+		}
+		movements = append(movements, change.Movement(product.ID, &variant.ID, int(variant.Stok), constant.STOCK_REASON_OPENING))
+		rest -= int(variant.Stok)
+	}
+	if rest != 0 {
+		movements = append(movements, change.Movement(product.ID, nil, rest, constant.STOCK_REASON_OPENING))
+	}
+	return movements
+}
+
+// FindDiscrepancies compares the stored stock of every product and variant
+// with its ledger balance. Results are ordered by product, with the
+// product-level entry before its variants.
+func FindDiscrepancies(products []*productModel.Product, variants []productModel.ProductVariant, productBalances map[uint]int, variantBalances map[uint]int) []StockDiscrepancy {
+	names := make(map[uint]string, len(products))
+	discrepancies := make([]StockDiscrepancy, 0)
+	for _, product := range products {
+		names[product.ID] = product.Name
+		if balance := productBalances[product.ID]; balance != int(product.Stok) {
+			discrepancies = append(discrepancies, StockDiscrepancy{
+				ProductID:   product.ID,
+				Name:        product.Name,
+				Stock:       product.Stok,
+				LedgerStock: balance,
+				Difference:  int(product.Stok) - balance,
+			})
+		}
+	}
+
+	for i := range variants {
+		variant := variants[i]
+		name, ok := names[variant.ProductID]
+		if !ok {
+			continue
+		}
+		if balance := variantBalances[variant.ID]; balance != int(variant.Stok) {
+			discrepancies = append(discrepancies, StockDiscrepancy{
+				ProductID:   variant.ProductID,
+				VariantID:   &variant.ID,
+				Name:        name,
+				SKU:         variant.SKU,
+				Stock:       variant.Stok,
+				LedgerStock: balance,
+				Difference:  int(variant.Stok) - balance,
+			})
+		}
+	}
+
+	sort.SliceStable(discrepancies, func(i, j int) bool {
+		a, b := discrepancies[i], discrepancies[j]
+		if a.ProductID != b.ProductID {
+			return a.ProductID < b.ProductID
+		}
+		return a.VariantID == nil && b.VariantID != nil
+	})
+	return discrepancies
+}
+
+// Corrections returns the adjustments that make the ledger match the stored
+// stock again. Variant movements also count toward their product, so the
+// product-level correction is what remains after the variant corrections.
+func Corrections(discrepancies []StockDiscrepancy, change StockChange) []StockMovement {
+	productDiff := make(map[uint]int)
+	var order []uint
+	var movements []StockMovement
+
+	for _, d := range discrepancies {
+		if _, seen := productDiff[d.ProductID]; !seen {
+			productDiff[d.ProductID] = 0
+			order = append(order, d.ProductID)
+		}
+		if d.VariantID == nil {
+			productDiff[d.ProductID] += d.Difference
+			continue
+		}
+		productDiff[d.ProductID] -= d.Difference
+		movements = append(movements, change.Movement(d.ProductID, d.VariantID, d.Difference, constant.STOCK_REASON_ADJUSTMENT))
+	}
+
+	for _, productID := range order {
+		if diff := productDiff[productID]; diff != 0 {
+			movements = append(movements, change.Movement(productID, nil, diff, constant.STOCK_REASON_ADJUSTMENT))
+		}
+	}
+	return movements
+}
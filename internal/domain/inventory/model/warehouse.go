@@ -0,0 +1,73 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Warehouse is a location stock is held at and shipped from. Priority breaks
+// ties between equally close warehouses; higher wins.
+type Warehouse struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	Code       string    `json:"code" gorm:"column:code;size:32;not null;uniqueIndex"`
+	Name       string    `json:"name" gorm:"column:name;not null"`
+	City       string    `json:"city" gorm:"column:city"`
+	PostalCode string    `json:"postal_code" gorm:"column:postal_code;size:16"`
+	Priority   int       `json:"priority" gorm:"column:priority;not null"`
+	IsDefault  bool      `json:"is_default" gorm:"column:is_default;not null"`
+	Active     bool      `json:"active" gorm:"column:active;not null"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (Warehouse) TableName() string {
+	return "Warehouse"
+}
+
+// WarehouseStock is the stock of a product, or of one of its variants, held
+// at a warehouse. It is kept in step with the ledger: every movement updates
+// the row of its warehouse in the same transaction.
+type WarehouseStock struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	WarehouseID uint      `json:"warehouse_id" gorm:"column:warehouse_id;not null;index:idx_warehouse_stock_item"`
+	ProductID   uint      `json:"product_id" gorm:"column:product_id;not null;index:idx_warehouse_stock_item"`
+	VariantID   *uint     `json:"variant_id,omitempty" gorm:"column:variant_id;index:idx_warehouse_stock_item"`
+	Quantity    int       `json:"quantity" gorm:"column:quantity;not null"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (WarehouseStock) TableName() string {
+	return "WarehouseStock"
+}
+
+// StockTransfer moves stock of a product or variant between two warehouses.
+type StockTransfer struct {
+	ProductID       uint
+	VariantID       *uint
+	FromWarehouseID uint
+	ToWarehouseID   uint
+	Quantity        uint
+	Reference       string
+	Note            string
+}
+
+// Location is where an order ships to.
+type Location struct {
+	City       string
+	PostalCode string
+}
+
+// Proximity scores how close the warehouse is to a location; higher is
+// closer. Postal codes are hierarchical, so every leading digit they share
+// counts for more than a matching city name.
+func (w Warehouse) Proximity(location Location) int {
+	score := 0
+	a, b := strings.TrimSpace(w.PostalCode), strings.TrimSpace(location.PostalCode)
+	for i := 0; i < len(a) && i < len(b) && a[i] == b[i]; i++ {
+		score += 10
+	}
+	if location.City != "" && strings.EqualFold(strings.TrimSpace(w.City), strings.TrimSpace(location.City)) {
+		score += 5
+	}
+	return score
+}
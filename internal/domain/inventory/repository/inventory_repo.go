@@ -0,0 +1,272 @@
+package repository
+
+import (
+	"errors"
+	mysql "go-online-store/config/database/my_sql_db"
+	"go-online-store/internal/domain/inventory/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InventoryRepository struct {
+	db *gorm.DB
+}
+
+type InventoryRepositoryImpl interface {
+	Record(movements ...model.StockMovement) error
+	GetMovements(query model.MovementQuery) ([]model.StockMovement, int64, error)
+	ProductBalances() (map[uint]int, error)
+	VariantBalances() (map[uint]int, error)
+	ProductBalance(productID uint) (int, error)
+	VariantBalance(variantID uint) (int, error)
+	TrackedProductIDs() ([]uint, error)
+	StockLevels(productID uint, variantID *uint) ([]model.WarehouseStock, error)
+	GetWarehouseStock(productIDs []uint) ([]model.WarehouseStock, error)
+	GetStockByWarehouse(warehouseID uint) ([]model.WarehouseStock, error)
+	AssignUnlocatedMovements(warehouseID uint) (int64, error)
+	RebuildWarehouseStock() error
+	WithTx(tx *gorm.DB) InventoryRepositoryImpl
+}
+
+func NewInventoryRepository() (InventoryRepositoryImpl, error) {
+	db, err := mysql.ConnectDatabase()
+	if err != nil {
+		return nil, err
+	}
+
+	db.AutoMigrate(&model.StockMovement{}, &model.Warehouse{}, &model.WarehouseStock{})
+	return &InventoryRepository{db: db}, nil
+}
+
+// WithTx returns a repository that runs its statements in tx.
+func (repo *InventoryRepository) WithTx(tx *gorm.DB) InventoryRepositoryImpl {
+	return &InventoryRepository{db: tx}
+}
+
+// Record appends movements to the ledger and applies them to the stock of
+// their warehouses. Movements without a warehouse are placed at the default
+// warehouse. Entries are never updated or deleted; corrections are recorded
+// as new adjustments.
+func (repo *InventoryRepository) Record(movements ...model.StockMovement) error {
+	if len(movements) == 0 {
+		return nil
+	}
+
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		defaultID, err := defaultWarehouseID(tx)
+		if err != nil {
+			return err
+		}
+		// Without a default warehouse stock stays unlocated
+		for i := range movements {
+			if movements[i].WarehouseID == nil {
+				movements[i].WarehouseID = defaultID
+			}
+		}
+
+		if err := tx.Create(&movements).Error; err != nil {
+			return err
+		}
+		for _, movement := range movements {
+			if movement.WarehouseID == nil {
+				continue
+			}
+			if err := applyWarehouseStock(tx, movement); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func defaultWarehouseID(tx *gorm.DB) (*uint, error) {
+	var warehouse model.Warehouse
+	err := tx.Select("id").Where("is_default = ?", true).First(&warehouse).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &warehouse.ID, nil
+}
+
+func applyWarehouseStock(tx *gorm.DB, movement model.StockMovement) error {
+	var stock model.WarehouseStock
+	err := scopeStock(tx.Clauses(clause.Locking{Strength: "UPDATE"}), movement.ProductID, movement.VariantID).
+		Where("warehouse_id = ?", *movement.WarehouseID).
+		First(&stock).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return tx.Create(&model.WarehouseStock{
+			WarehouseID: *movement.WarehouseID,
+			ProductID:   movement.ProductID,
+			VariantID:   movement.VariantID,
+			Quantity:    movement.Quantity,
+		}).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Model(&model.WarehouseStock{}).
+		Where("id = ?", stock.ID).
+		Update("quantity", gorm.Expr("quantity + ?", movement.Quantity)).Error
+}
+
+// scopeStock narrows a query to the stock of a product, or of one variant;
+// variantID is nil for product-level stock.
+func scopeStock(db *gorm.DB, productID uint, variantID *uint) *gorm.DB {
+	db = db.Where("product_id = ?", productID)
+	if variantID == nil {
+		return db.Where("variant_id IS NULL")
+	}
+	return db.Where("variant_id = ?", *variantID)
+}
+
+// GetMovements returns a page of stock history, newest first, and the total
+// number of matching movements.
+func (repo *InventoryRepository) GetMovements(query model.MovementQuery) ([]model.StockMovement, int64, error) {
+	db := repo.db.Model(&model.StockMovement{}).Where("product_id = ?", query.ProductID)
+	if query.VariantID != nil {
+		db = db.Where("variant_id = ?", *query.VariantID)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var movements []model.StockMovement
+	err := db.Order("id DESC").Offset(query.Offset).Limit(query.Limit).Find(&movements).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return movements, total, nil
+}
+
+type balanceRow struct {
+	ID      uint
+	Balance int
+}
+
+// ProductBalances returns the ledger stock of every tracked product.
+func (repo *InventoryRepository) ProductBalances() (map[uint]int, error) {
+	var rows []balanceRow
+	err := repo.db.Model(&model.StockMovement{}).
+		Select("product_id AS id, COALESCE(SUM(quantity), 0) AS balance").
+		Group("product_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return balanceMap(rows), nil
+}
+
+// VariantBalances returns the ledger stock of every tracked variant.
+func (repo *InventoryRepository) VariantBalances() (map[uint]int, error) {
+	var rows []balanceRow
+	err := repo.db.Model(&model.StockMovement{}).
+		Select("variant_id AS id, COALESCE(SUM(quantity), 0) AS balance").
+		Where("variant_id IS NOT NULL").
+		Group("variant_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return balanceMap(rows), nil
+}
+
+func (repo *InventoryRepository) ProductBalance(productID uint) (int, error) {
+	var balance int
+	err := repo.db.Model(&model.StockMovement{}).
+		Select("COALESCE(SUM(quantity), 0)").
+		Where("product_id = ?", productID).
+		Scan(&balance).Error
+	return balance, err
+}
+
+func (repo *InventoryRepository) VariantBalance(variantID uint) (int, error) {
+	var balance int
+	err := repo.db.Model(&model.StockMovement{}).
+		Select("COALESCE(SUM(quantity), 0)").
+		Where("variant_id = ?", variantID).
+		Scan(&balance).Error
+	return balance, err
+}
+
+// TrackedProductIDs returns the products that have at least one movement.
+func (repo *InventoryRepository) TrackedProductIDs() ([]uint, error) {
+	var ids []uint
+	err := repo.db.Model(&model.StockMovement{}).Distinct().Pluck("product_id", &ids).Error
+	return ids, err
+}
+
+// StockLevels returns the stock of a product or variant at every warehouse
+// that has a record of it, the default warehouse first.
+func (repo *InventoryRepository) StockLevels(productID uint, variantID *uint) ([]model.WarehouseStock, error) {
+	var stock []model.WarehouseStock
+	err := scopeStock(repo.db.Model(&model.WarehouseStock{}), productID, variantID).
+		Joins("JOIN Warehouse ON Warehouse.id = WarehouseStock.warehouse_id").
+		Order("Warehouse.is_default DESC, WarehouseStock.warehouse_id").
+		Find(&stock).Error
+	return stock, err
+}
+
+// GetWarehouseStock returns the stock of the given products, and of their
+// variants, at every warehouse.
+func (repo *InventoryRepository) GetWarehouseStock(productIDs []uint) ([]model.WarehouseStock, error) {
+	var stock []model.WarehouseStock
+	if len(productIDs) == 0 {
+		return stock, nil
+	}
+	err := repo.db.Where("product_id IN ?", productIDs).Order("warehouse_id, product_id, variant_id").Find(&stock).Error
+	return stock, err
+}
+
+func (repo *InventoryRepository) GetStockByWarehouse(warehouseID uint) ([]model.WarehouseStock, error) {
+	var stock []model.WarehouseStock
+	err := repo.db.Where("warehouse_id = ? AND quantity <> 0", warehouseID).Order("product_id, variant_id").Find(&stock).Error
+	return stock, err
+}
+
+// AssignUnlocatedMovements places the movements recorded before warehouses
+// existed at the given warehouse. This is the one write to existing ledger
+// entries: it fills in a column they were recorded without.
+func (repo *InventoryRepository) AssignUnlocatedMovements(warehouseID uint) (int64, error) {
+	result := repo.db.Model(&model.StockMovement{}).
+		Where("warehouse_id IS NULL").
+		Update("warehouse_id", warehouseID)
+	return result.RowsAffected, result.Error
+}
+
+// RebuildWarehouseStock recomputes the stock of every warehouse from the
+// ledger.
+func (repo *InventoryRepository) RebuildWarehouseStock() error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		var stock []model.WarehouseStock
+		if err := tx.Model(&model.StockMovement{}).
+			Select("warehouse_id, product_id, variant_id, SUM(quantity) AS quantity").
+			Where("warehouse_id IS NOT NULL").
+			Group("warehouse_id, product_id, variant_id").
+			Scan(&stock).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("1 = 1").Delete(&model.WarehouseStock{}).Error; err != nil {
+			return err
+		}
+		if len(stock) == 0 {
+			return nil
+		}
+		return tx.Create(&stock).Error
+	})
+}
+
+func balanceMap(rows []balanceRow) map[uint]int {
+	balances := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		balances[row.ID] = row.Balance
+	}
+	return balances
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	mysql "go-online-store/config/database/my_sql_db"
+	"go-online-store/internal/domain/inventory/model"
+
+	"gorm.io/gorm"
+)
+
+type WarehouseRepository struct {
+	db *gorm.DB
+}
+
+type WarehouseRepositoryImpl interface {
+	Create(warehouse *model.Warehouse) error
+	Update(warehouse *model.Warehouse) error
+	GetByID(id uint) (*model.Warehouse, error)
+	GetByCode(code string) (*model.Warehouse, error)
+	GetAll() ([]model.Warehouse, error)
+	GetDefault() (*model.Warehouse, error)
+	SetDefault(id uint) error
+}
+
+func NewWarehouseRepository() (WarehouseRepositoryImpl, error) {
+	db, err := mysql.ConnectDatabase()
+	if err != nil {
+		return nil, err
+	}
+
+	db.AutoMigrate(&model.Warehouse{}, &model.WarehouseStock{})
+	return &WarehouseRepository{db: db}, nil
+}
+
+func (repo *WarehouseRepository) Create(warehouse *model.Warehouse) error {
+	return repo.db.Create(warehouse).Error
+}
+
+func (repo *WarehouseRepository) Update(warehouse *model.Warehouse) error {
+	return repo.db.Save(warehouse).Error
+}
+
+func (repo *WarehouseRepository) GetByID(id uint) (*model.Warehouse, error) {
+	var warehouse model.Warehouse
+	if err := repo.db.First(&warehouse, id).Error; err != nil {
+		return nil, err
+	}
+	return &warehouse, nil
+}
+
+func (repo *WarehouseRepository) GetByCode(code string) (*model.Warehouse, error) {
+	var warehouse model.Warehouse
+	if err := repo.db.Where("code = ?", code).First(&warehouse).Error; err != nil {
+		return nil, err
+	}
+	return &warehouse, nil
+}
+
+func (repo *WarehouseRepository) GetAll() ([]model.Warehouse, error) {
+	var warehouses []model.Warehouse
+	if err := repo.db.Order("id").Find(&warehouses).Error; err != nil {
+		return nil, err
+	}
+	return warehouses, nil
+}
+
+func (repo *WarehouseRepository) GetDefault() (*model.Warehouse, error) {
+	var warehouse model.Warehouse
+	if err := repo.db.Where("is_default = ?", true).First(&warehouse).Error; err != nil {
+		return nil, err
+	}
+	return &warehouse, nil
+}
+
+// SetDefault makes a warehouse the only default one.
+func (repo *WarehouseRepository) SetDefault(id uint) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Warehouse{}).Where("id <> ?", id).Update("is_default", false).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Warehouse{}).Where("id = ?", id).Update("is_default", true).Error
+	})
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-online-store/internal/domain/inventory/model"
+	"go-online-store/internal/domain/inventory/repository"
+	repoProduct "go-online-store/internal/domain/product/repository"
+	"go-online-store/internal/middleware/jwt"
+	customErrors "go-online-store/pkg/errors"
+	"go-online-store/pkg/logger"
+	"go-online-store/pkg/pagination"
+	"os"
+
+	"gorm.io/gorm"
+)
+
+type InventoryService struct {
+	repoInventory repository.InventoryRepositoryImpl
+	repoProduct   repoProduct.ProductRepositoryImpl
+	logger        *logger.Logger
+}
+
+type InventoryServiceImpl interface {
+	GetStockHistory(ctx context.Context, productID uint, variantID *uint, params pagination.Params) (*model.StockHistory, error)
+	AdjustStock(ctx context.Context, productID uint, adjustment model.StockAdjustment) (*model.StockHistory, error)
+	Reconcile(ctx context.Context, fix bool) (*model.ReconcileReport, error)
+	BackfillOpeningBalances(ctx context.Context) error
+}
+
+func NewInstanceInventoryService() InventoryServiceImpl {
+	log := logger.NewLogger(os.Stdout, "Service [Inventory] :")
+	inventoryRepo, err := repository.NewInventoryRepository()
+	if err != nil {
+		log.Error("Failed to initialize inventory repository: " + err.Error())
+		return nil
+	}
+
+	productRepo, err := repoProduct.NewProductRepository()
+	if err != nil {
+		log.Error("Failed to initialize product repository: " + err.Error())
+		return nil
+	}
+
+	inventoryService := &InventoryService{
+		repoInventory: inventoryRepo,
+		repoProduct:   productRepo,
+		logger:        log,
+	}
+
+	if err := inventoryService.BackfillOpeningBalances(context.Background()); err != nil {
+		log.Error("Failed to backfill opening balances: " + err.Error())
+	}
+
+	return inventoryService
+}
+
+// GetStockHistory returns a page of the stock movements of a product, or of
+// one of its variants, newest first.
+func (inventoryService *InventoryService) GetStockHistory(ctx context.Context, productID uint, variantID *uint, params pagination.Params) (*model.StockHistory, error) {
+	inventoryService.logger.Info("Fetching stock history of product with ID: " + fmt.Sprint(productID))
+	product, err := inventoryService.repoProduct.GetByID(productID)
+	if err != nil {
+		inventoryService.logger.Error("Failed to fetch product: " + err.Error())
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, customErrors.ErrNotFound
+		}
+		return nil, err
+	}
+
+	history := &model.StockHistory{ProductID: productID, VariantID: variantID, Stock: product.Stok}
+	if variantID != nil {
+		variant, ok := product.Variant(*variantID)
+		if !ok {
+			return nil, customErrors.ErrNotFound
+		}
+		history.Stock = variant.Stok
+		history.LedgerStock, err = inventoryService.repoInventory.VariantBalance(*variantID)
+	} else {
+		history.LedgerStock, err = inventoryService.repoInventory.ProductBalance(productID)
+	}
+	if err != nil {
+		inventoryService.logger.Error("Failed to compute ledger stock: " + err.Error())
+		return nil, err
+	}
+
+	params = params.Normalize()
+	history.Movements, history.Total, err = inventoryService.repoInventory.GetMovements(model.MovementQuery{
+		ProductID: productID,
+		VariantID: variantID,
+		Offset:    params.Offset(),
+		Limit:     params.Limit,
+	})
+	if err != nil {
+		inventoryService.logger.Error("Failed to fetch stock movements: " + err.Error())
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// AdjustStock records a manual stock movement, such as a delivery or a count
+// correction, attributed to the signed-in admin.
+func (inventoryService *InventoryService) AdjustStock(ctx context.Context, productID uint, adjustment model.StockAdjustment) (*model.StockHistory, error) {
+	inventoryService.logger.Info("Adjusting stock of product with ID: " + fmt.Sprint(productID))
+	if adjustment.Quantity == 0 || !model.IsManualReason(adjustment.Reason) {
+		return nil, customErrors.ErrInvalidStockMovement
+	}
+
+	product, err := inventoryService.repoProduct.GetByID(productID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, customErrors.ErrNotFound
+		}
+		return nil, err
+	}
+
+	// Stock of a variant product lives on its SKUs
+	if adjustment.VariantID == nil && product.HasVariants() {
+		return nil, customErrors.ErrVariantRequired
+	}
+	if adjustment.VariantID != nil {
+		if _, ok := product.Variant(*adjustment.VariantID); !ok {
+			return nil, customErrors.ErrNotFound
+		}
+	}
+
+	change := model.StockChange{WarehouseID: adjustment.WarehouseID, Reference: adjustment.Reference, Note: adjustment.Note}
+	if customer, ok := jwt.FromCustomer(ctx); ok {
+		change.Actor = customer.Email
+	}
+
+	err = inventoryService.repoProduct.WithStockChange(change).
+		AdjustStock(productID, adjustment.VariantID, adjustment.Quantity, adjustment.Reason)
+	if err != nil {
+		inventoryService.logger.Error("Failed to adjust stock: " + err.Error())
+		if errors.Is(err, repoProduct.ErrInsufficientStock) {
+			return nil, customErrors.ErrProductStockNotAvailable
+		}
+		return nil, err
+	}
+
+	return inventoryService.GetStockHistory(ctx, productID, adjustment.VariantID, pagination.Params{})
+}
+
+// Reconcile compares the stored stock of every product and variant with its
+// ledger. With fix set, adjustments are recorded so the ledger matches the
+// stored stock again; the discrepancies stay visible in the history.
+func (inventoryService *InventoryService) Reconcile(ctx context.Context, fix bool) (*model.ReconcileReport, error) {
+	inventoryService.logger.Info("Reconciling stock against the inventory ledger")
+	products, err := inventoryService.repoProduct.GetAll()
+	if err != nil {
+		inventoryService.logger.Error("Failed to fetch products: " + err.Error())
+		return nil, err
+	}
+
+	variants, err := inventoryService.repoProduct.GetAllVariants()
+	if err != nil {
+		inventoryService.logger.Error("Failed to fetch variants: " + err.Error())
+		return nil, err
+	}
+
+	productBalances, err := inventoryService.repoInventory.ProductBalances()
+	if err != nil {
+		return nil, err
+	}
+	variantBalances, err := inventoryService.repoInventory.VariantBalances()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &model.ReconcileReport{
+		Checked:       len(products) + len(variants),
+		Discrepancies: model.FindDiscrepancies(products, variants, productBalances, variantBalances),
+	}
+
+	if fix && len(report.Discrepancies) > 0 {
+		change := model.StockChange{Note: "reconciliation"}
+		if customer, ok := jwt.FromCustomer(ctx); ok {
+			change.Actor = customer.Email
+		}
+
+		if err := inventoryService.repoInventory.Record(model.Corrections(report.Discrepancies, change)...); err != nil {
+			inventoryService.logger.Error("Failed to record corrections: " + err.Error())
+			return nil, err
+		}
+		report.Fixed = true
+	}
+
+	return report, nil
+}
+
+// BackfillOpeningBalances starts the ledger of every product that has no
+// movements yet with its current stock. It is safe to run repeatedly; only
+// untracked products are touched.
+func (inventoryService *InventoryService) BackfillOpeningBalances(ctx context.Context) error {
+	tracked, err := inventoryService.repoInventory.TrackedProductIDs()
+	if err != nil {
+		return err
+	}
+	isTracked := make(map[uint]bool, len(tracked))
+	for _, id := range tracked {
+		isTracked[id] = true
+	}
+
+	products, err := inventoryService.repoProduct.GetAll()
+	if err != nil {
+		return err
+	}
+
+	for _, product := range products {
+		if isTracked[product.ID] {
+			continue
+		}
+
+		variants, err := inventoryService.repoProduct.GetVariantsByProductID(product.ID)
+		if err != nil {
+			return err
+		}
+
+		movements := model.OpeningBalances(product, variants)
+		if len(movements) == 0 {
+			continue
+		}
+		if err := inventoryService.repoInventory.Record(movements...); err != nil {
+			return err
+		}
+		inventoryService.logger.Info("Recorded opening stock balance of product " + fmt.Sprint(product.ID))
+	}
+
+	return nil
+}
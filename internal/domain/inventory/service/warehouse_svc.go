@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-online-store/internal/domain/inventory/model"
+	"go-online-store/internal/domain/inventory/repository"
+	productModel "go-online-store/internal/domain/product/model"
+	repoProduct "go-online-store/internal/domain/product/repository"
+	"go-online-store/internal/middleware/jwt"
+	"go-online-store/pkg/constant"
+	customErrors "go-online-store/pkg/errors"
+	"go-online-store/pkg/logger"
+	"os"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type WarehouseService struct {
+	repoWarehouse repository.WarehouseRepositoryImpl
+	repoInventory repository.InventoryRepositoryImpl
+	repoProduct   repoProduct.ProductRepositoryImpl
+	logger        *logger.Logger
+}
+
+type WarehouseServiceImpl interface {
+	GetWarehouses(ctx context.Context) ([]model.Warehouse, error)
+	CreateWarehouse(ctx context.Context, warehouse model.Warehouse) (*model.Warehouse, error)
+	UpdateWarehouse(ctx context.Context, warehouse model.Warehouse) (*model.Warehouse, error)
+	GetWarehouseStock(ctx context.Context, warehouseID uint) ([]model.WarehouseStock, error)
+	GetProductStockLocations(ctx context.Context, productID uint) ([]model.WarehouseStock, error)
+	TransferStock(ctx context.Context, transfer model.StockTransfer) ([]model.WarehouseStock, error)
+	EnsureDefaultWarehouse(ctx context.Context) error
+}
+
+func NewInstanceWarehouseService() WarehouseServiceImpl {
+	log := logger.NewLogger(os.Stdout, "Service [Warehouse] :")
+	warehouseRepo, err := repository.NewWarehouseRepository()
+	if err != nil {
+		log.Error("Failed to initialize warehouse repository: " + err.Error())
+		return nil
+	}
+
+	inventoryRepo, err := repository.NewInventoryRepository()
+	if err != nil {
+		log.Error("Failed to initialize inventory repository: " + err.Error())
+		return nil
+	}
+
+	productRepo, err := repoProduct.NewProductRepository()
+	if err != nil {
+		log.Error("Failed to initialize product repository: " + err.Error())
+		return nil
+	}
+
+	warehouseService := &WarehouseService{
+		repoWarehouse: warehouseRepo,
+		repoInventory: inventoryRepo,
+		repoProduct:   productRepo,
+		logger:        log,
+	}
+
+	if err := warehouseService.EnsureDefaultWarehouse(context.Background()); err != nil {
+		log.Error("Failed to set up the default warehouse: " + err.Error())
+	}
+
+	return warehouseService
+}
+
+// EnsureDefaultWarehouse creates the default warehouse on first start and
+// places all stock recorded before warehouses existed there.
+func (warehouseService *WarehouseService) EnsureDefaultWarehouse(ctx context.Context) error {
+	warehouse, err := warehouseService.repoWarehouse.GetDefault()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		warehouse = &model.Warehouse{
+			Code:      constant.DEFAULT_WAREHOUSE_CODE,
+			Name:      constant.DEFAULT_WAREHOUSE_NAME,
+			IsDefault: true,
+			Active:    true,
+		}
+		warehouseService.logger.Info("Creating default warehouse " + warehouse.Code)
+		err = warehouseService.repoWarehouse.Create(warehouse)
+	}
+	if err != nil {
+		return err
+	}
+
+	assigned, err := warehouseService.repoInventory.AssignUnlocatedMovements(warehouse.ID)
+	if err != nil {
+		return err
+	}
+	if assigned == 0 {
+		return nil
+	}
+
+	warehouseService.logger.Info("Placed " + fmt.Sprint(assigned) + " stock movements at warehouse " + warehouse.Code)
+	return warehouseService.repoInventory.RebuildWarehouseStock()
+}
+
+func (warehouseService *WarehouseService) GetWarehouses(ctx context.Context) ([]model.Warehouse, error) {
+	warehouseService.logger.Info("Fetching all warehouses")
+	warehouses, err := warehouseService.repoWarehouse.GetAll()
+	if err != nil {
+		warehouseService.logger.Error("Failed to fetch warehouses: " + err.Error())
+		return nil, err
+	}
+	return warehouses, nil
+}
+
+// CreateWarehouse adds a stock location.
+func (warehouseService *WarehouseService) CreateWarehouse(ctx context.Context, warehouse model.Warehouse) (*model.Warehouse, error) {
+	warehouseService.logger.Info("Creating warehouse " + warehouse.Code)
+	warehouse.ID = 0
+	if err := warehouseService.validateWarehouse(&warehouse); err != nil {
+		return nil, err
+	}
+
+	isDefault := warehouse.IsDefault
+	warehouse.IsDefault = false
+	if err := warehouseService.repoWarehouse.Create(&warehouse); err != nil {
+		warehouseService.logger.Error("Failed to create warehouse: " + err.Error())
+		return nil, err
+	}
+
+	if isDefault {
+		if err := warehouseService.setDefault(&warehouse); err != nil {
+			return nil, err
+		}
+	}
+	return &warehouse, nil
+}
+
+// UpdateWarehouse replaces the stored fields of a warehouse. The default
+// warehouse cannot be deactivated or stop being the default; another
+// warehouse has to be made the default instead.
+func (warehouseService *WarehouseService) UpdateWarehouse(ctx context.Context, warehouse model.Warehouse) (*model.Warehouse, error) {
+	warehouseService.logger.Info("Updating warehouse with ID: " + fmt.Sprint(warehouse.ID))
+	existing, err := warehouseService.getWarehouse(warehouse.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := warehouseService.validateWarehouse(&warehouse); err != nil {
+		return nil, err
+	}
+	if existing.IsDefault && !warehouse.IsDefault {
+		return nil, customErrors.ErrInvalidWarehouse
+	}
+
+	makeDefault := warehouse.IsDefault && !existing.IsDefault
+	warehouse.IsDefault = existing.IsDefault
+	warehouse.CreatedAt = existing.CreatedAt
+	if err := warehouseService.repoWarehouse.Update(&warehouse); err != nil {
+		warehouseService.logger.Error("Failed to update warehouse: " + err.Error())
+		return nil, err
+	}
+
+	if makeDefault {
+		if err := warehouseService.setDefault(&warehouse); err != nil {
+			return nil, err
+		}
+	}
+	return &warehouse, nil
+}
+
+// GetWarehouseStock lists the stock held at a warehouse.
+func (warehouseService *WarehouseService) GetWarehouseStock(ctx context.Context, warehouseID uint) ([]model.WarehouseStock, error) {
+	warehouseService.logger.Info("Fetching stock of warehouse with ID: " + fmt.Sprint(warehouseID))
+	if _, err := warehouseService.getWarehouse(warehouseID); err != nil {
+		return nil, err
+	}
+
+	stock, err := warehouseService.repoInventory.GetStockByWarehouse(warehouseID)
+	if err != nil {
+		warehouseService.logger.Error("Failed to fetch warehouse stock: " + err.Error())
+		return nil, err
+	}
+	return stock, nil
+}
+
+// GetProductStockLocations lists where the stock of a product and its
+// variants is held.
+func (warehouseService *WarehouseService) GetProductStockLocations(ctx context.Context, productID uint) ([]model.WarehouseStock, error) {
+	warehouseService.logger.Info("Fetching stock locations of product with ID: " + fmt.Sprint(productID))
+	if _, err := warehouseService.getProduct(productID); err != nil {
+		return nil, err
+	}
+
+	stock, err := warehouseService.repoInventory.GetWarehouseStock([]uint{productID})
+	if err != nil {
+		warehouseService.logger.Error("Failed to fetch stock locations: " + err.Error())
+		return nil, err
+	}
+	return stock, nil
+}
+
+// TransferStock moves stock between two warehouses, attributed to the
+// signed-in admin, and returns the new stock locations of the product.
+func (warehouseService *WarehouseService) TransferStock(ctx context.Context, transfer model.StockTransfer) ([]model.WarehouseStock, error) {
+	warehouseService.logger.Info("Transferring stock of product with ID: " + fmt.Sprint(transfer.ProductID))
+	if transfer.Quantity == 0 || transfer.FromWarehouseID == transfer.ToWarehouseID {
+		return nil, customErrors.ErrInvalidStockMovement
+	}
+
+	product, err := warehouseService.getProduct(transfer.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.VariantID == nil && product.HasVariants() {
+		return nil, customErrors.ErrVariantRequired
+	}
+	if transfer.VariantID != nil {
+		if _, ok := product.Variant(*transfer.VariantID); !ok {
+			return nil, customErrors.ErrNotFound
+		}
+	}
+
+	for _, id := range []uint{transfer.FromWarehouseID, transfer.ToWarehouseID} {
+		if _, err := warehouseService.getWarehouse(id); err != nil {
+			if errors.Is(err, customErrors.ErrNotFound) {
+				return nil, customErrors.ErrInvalidWarehouse
+			}
+			return nil, err
+		}
+	}
+
+	var change model.StockChange
+	if customer, ok := jwt.FromCustomer(ctx); ok {
+		change.Actor = customer.Email
+	}
+
+	if err := warehouseService.repoProduct.WithStockChange(change).TransferStock(transfer); err != nil {
+		warehouseService.logger.Error("Failed to transfer stock: " + err.Error())
+		if errors.Is(err, repoProduct.ErrInsufficientStock) {
+			return nil, customErrors.ErrProductStockNotAvailable
+		}
+		return nil, err
+	}
+
+	return warehouseService.GetProductStockLocations(ctx, transfer.ProductID)
+}
+
+func (warehouseService *WarehouseService) validateWarehouse(warehouse *model.Warehouse) error {
+	warehouse.Code = strings.ToUpper(strings.TrimSpace(warehouse.Code))
+	warehouse.Name = strings.TrimSpace(warehouse.Name)
+	// The default warehouse is always active
+	if warehouse.Code == "" || warehouse.Name == "" || (warehouse.IsDefault && !warehouse.Active) {
+		return customErrors.ErrInvalidWarehouse
+	}
+
+	existing, err := warehouseService.repoWarehouse.GetByCode(warehouse.Code)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if existing != nil && existing.ID != warehouse.ID {
+		return customErrors.ErrInvalidWarehouse
+	}
+	return nil
+}
+
+// setDefault makes a warehouse the only default one.
+func (warehouseService *WarehouseService) setDefault(warehouse *model.Warehouse) error {
+	if err := warehouseService.repoWarehouse.SetDefault(warehouse.ID); err != nil {
+		warehouseService.logger.Error("Failed to set default warehouse: " + err.Error())
+		return err
+	}
+	warehouse.IsDefault = true
+	return nil
+}
+
+func (warehouseService *WarehouseService) getWarehouse(id uint) (*model.Warehouse, error) {
+	warehouse, err := warehouseService.repoWarehouse.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, customErrors.ErrNotFound
+		}
+		return nil, err
+	}
+	return warehouse, nil
+}
+
+func (warehouseService *WarehouseService) getProduct(id uint) (*productModel.Product, error) {
+	product, err := warehouseService.repoProduct.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, customErrors.ErrNotFound
+		}
+		return nil, err
+	}
+	return product, nil
+}
@@ -0,0 +1,96 @@
+package model
+
+import (
+	"fmt"
+	productModel "go-online-store/internal/domain/product/model"
+	"go-online-store/pkg/constant"
+	"time"
+)
+
+// MaxAttempts is how often delivery of a notification is tried before it is
+// marked as failed.
+const MaxAttempts = 5
+
+// Subscription asks for one notification about a product: when it is back in
+// stock, or when its price drops to the target price. Once the notification
+// is queued the subscription is no longer active; subscribing again re-arms it.
+type Subscription struct {
+	ID          uint                  `json:"id" gorm:"primaryKey"`
+	CustomerID  uint                  `json:"customer_id" gorm:"not null;uniqueIndex:idx_subscription"`
+	ProductID   uint                  `json:"product_id" gorm:"not null;uniqueIndex:idx_subscription;index"`
+	Kind        string                `json:"kind" gorm:"size:20;not null;uniqueIndex:idx_subscription"`
+	Email       string                `json:"-" gorm:"not null"`
+	TargetPrice *float64              `json:"target_price,omitempty"`
+	Active      bool                  `json:"active" gorm:"not null;default:true"`
+	NotifiedAt  *time.Time            `json:"notified_at"`
+	Product     *productModel.Product `json:"product,omitempty" gorm:"foreignKey:ProductID"`
+	CreatedAt   time.Time             `json:"created_at"`
+	UpdatedAt   time.Time             `json:"updated_at"`
+}
+
+// Notification is a message queued for delivery to a customer.
+type Notification struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	SubscriptionID uint       `json:"subscription_id" gorm:"not null;index"`
+	CustomerID     uint       `json:"customer_id" gorm:"not null;index"`
+	Recipient      string     `json:"recipient" gorm:"not null"`
+	Subject        string     `json:"subject" gorm:"not null"`
+	Body           string     `json:"body" gorm:"type:text;not null"`
+	Status         string     `json:"status" gorm:"size:20;not null;index"`
+	Attempts       uint       `json:"attempts" gorm:"not null;default:0"`
+	LastError      string     `json:"last_error,omitempty"`
+	SentAt         *time.Time `json:"sent_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func (Subscription) TableName() string {
+	return "ProductSubscription"
+}
+
+func (Notification) TableName() string {
+	return "Notification"
+}
+
+// Triggered reports whether product, as it is now, satisfies the
+// subscription: it has stock again, or its price is at or below the target.
+func (s *Subscription) Triggered(product *productModel.Product) bool {
+	if !s.Active || product == nil || product.ID != s.ProductID {
+		return false
+	}
+
+	switch s.Kind {
+	case constant.SUBSCRIPTION_BACK_IN_STOCK:
+		return product.Stok > 0
+	case constant.SUBSCRIPTION_PRICE_DROP:
+		return s.TargetPrice != nil && product.Price <= *s.TargetPrice
+	}
+	return false
+}
+
+// NewNotification builds the pending notification telling the subscriber
+// about product.
+func NewNotification(s *Subscription, product *productModel.Product) *Notification {
+	notification := &Notification{
+		SubscriptionID: s.ID,
+		CustomerID:     s.CustomerID,
+		Recipient:      s.Email,
+		Status:         constant.NOTIFICATION_STATUS_PENDING,
+	}
+
+	switch s.Kind {
+	case constant.SUBSCRIPTION_PRICE_DROP:
+		notification.Subject = product.Name + " is now " + formatPrice(product.Price)
+		notification.Body = fmt.Sprintf("Good news! The price of %s dropped to %s, at or below the %s you were waiting for.",
+			product.Name, formatPrice(product.Price), formatPrice(*s.TargetPrice))
+	default:
+		notification.Subject = product.Name + " is back in stock"
+		notification.Body = fmt.Sprintf("Good news! %s is back in stock with %d available. Order soon before it sells out again.",
+			product.Name, product.Stok)
+	}
+	return notification
+}
+
+func formatPrice(price float64) string {
+	return fmt.Sprintf("%.2f", price)
+}
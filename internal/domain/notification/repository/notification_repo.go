@@ -0,0 +1,130 @@
+package repository
+
+import (
+	mysql "go-online-store/config/database/my_sql_db"
+	"go-online-store/internal/domain/notification/model"
+	"go-online-store/pkg/constant"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+type NotificationRepositoryImpl interface {
+	GetSubscriptionsByCustomerID(customerID uint) ([]model.Subscription, error)
+	GetSubscriptionByID(id uint) (*model.Subscription, error)
+	GetActiveSubscriptions(productID uint) ([]model.Subscription, error)
+	GetSubscription(customerID uint, productID uint, kind string) (*model.Subscription, error)
+	SaveSubscription(subscription *model.Subscription) error
+	DeleteSubscription(id uint) error
+	DeleteProductSubscriptions(productID uint) error
+	Queue(notification *model.Notification) (bool, error)
+	GetPendingNotifications(limit int) ([]model.Notification, error)
+	UpdateNotification(notification *model.Notification) error
+}
+
+func NewNotificationRepository() (NotificationRepositoryImpl, error) {
+	db, err := mysql.ConnectDatabase()
+	if err != nil {
+		return nil, err
+	}
+
+	db.AutoMigrate(&model.Subscription{}, &model.Notification{})
+	return &NotificationRepository{db: db}, nil
+}
+
+func (repo *NotificationRepository) GetSubscriptionsByCustomerID(customerID uint) ([]model.Subscription, error) {
+	var subscriptions []model.Subscription
+	err := repo.db.Preload("Product").
+		Where("customer_id = ?", customerID).
+		Order("created_at DESC").
+		Find(&subscriptions).Error
+	if err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (repo *NotificationRepository) GetSubscriptionByID(id uint) (*model.Subscription, error) {
+	var subscription model.Subscription
+	if err := repo.db.First(&subscription, id).Error; err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func (repo *NotificationRepository) GetActiveSubscriptions(productID uint) ([]model.Subscription, error) {
+	var subscriptions []model.Subscription
+	err := repo.db.Where("product_id = ? AND active = ?", productID, true).
+		Order("id").
+		Find(&subscriptions).Error
+	if err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (repo *NotificationRepository) GetSubscription(customerID uint, productID uint, kind string) (*model.Subscription, error) {
+	var subscription model.Subscription
+	err := repo.db.Where("customer_id = ? AND product_id = ? AND kind = ?", customerID, productID, kind).
+		First(&subscription).Error
+	if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func (repo *NotificationRepository) SaveSubscription(subscription *model.Subscription) error {
+	return repo.db.Omit(clause.Associations).Save(subscription).Error
+}
+
+func (repo *NotificationRepository) DeleteSubscription(id uint) error {
+	return repo.db.Delete(&model.Subscription{}, id).Error
+}
+
+func (repo *NotificationRepository) DeleteProductSubscriptions(productID uint) error {
+	return repo.db.Where("product_id = ?", productID).Delete(&model.Subscription{}).Error
+}
+
+// Queue stores a pending notification and deactivates its subscription. It
+// reports false without queuing anything when the subscription was already
+// deactivated, so concurrent product writes notify a subscriber only once.
+func (repo *NotificationRepository) Queue(notification *model.Notification) (bool, error) {
+	queued := false
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&model.Subscription{}).
+			Where("id = ? AND active = ?", notification.SubscriptionID, true).
+			Updates(map[string]interface{}{"active": false, "notified_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		queued = true
+		return tx.Create(notification).Error
+	})
+	return queued, err
+}
+
+func (repo *NotificationRepository) GetPendingNotifications(limit int) ([]model.Notification, error) {
+	var notifications []model.Notification
+	err := repo.db.Where("status = ?", constant.NOTIFICATION_STATUS_PENDING).
+		Order("id").
+		Limit(limit).
+		Find(&notifications).Error
+	if err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+func (repo *NotificationRepository) UpdateNotification(notification *model.Notification) error {
+	return repo.db.Save(notification).Error
+}
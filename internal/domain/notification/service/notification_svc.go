@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	notificationConfig "go-online-store/config/notification"
+	"go-online-store/internal/domain/notification/model"
+	"go-online-store/internal/domain/notification/repository"
+	productModel "go-online-store/internal/domain/product/model"
+	repoProduct "go-online-store/internal/domain/product/repository"
+	"go-online-store/internal/middleware/jwt"
+	"go-online-store/pkg/constant"
+	customErrors "go-online-store/pkg/errors"
+	"go-online-store/pkg/logger"
+	"go-online-store/pkg/notifier"
+	"os"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// dispatchBatchSize is how many queued notifications one dispatch run sends.
+const dispatchBatchSize = 100
+
+var registerObserverOnce sync.Once
+
+type NotificationService struct {
+	repoNotification repository.NotificationRepositoryImpl
+	repoProduct      repoProduct.ProductRepositoryImpl
+	notifier         notifier.Notifier
+	logger           *logger.Logger
+}
+
+type NotificationServiceImpl interface {
+	Subscribe(ctx context.Context, productID uint, kind string, targetPrice *float64) (*model.Subscription, error)
+	GetSubscriptions(ctx context.Context) ([]model.Subscription, error)
+	Unsubscribe(ctx context.Context, subscriptionID uint) error
+	DispatchNotifications(ctx context.Context) (int, error)
+	StartDispatcher(ctx context.Context, interval time.Duration)
+}
+
+func NewInstanceNotificationService() NotificationServiceImpl {
+	log := logger.NewLogger(os.Stdout, "Service [Notification] :")
+	notificationRepo, err := repository.NewNotificationRepository()
+	if err != nil {
+		log.Error("Failed to initialize notification repository: " + err.Error())
+		return nil
+	}
+
+	productRepo, err := repoProduct.NewProductRepository()
+	if err != nil {
+		log.Error("Failed to initialize product repository: " + err.Error())
+		return nil
+	}
+
+	sink, err := notificationConfig.LoadNotificationConfig().NewNotifier()
+	if err != nil {
+		log.Error("Failed to initialize notifier: " + err.Error())
+		return nil
+	}
+
+	notificationService := NewNotificationService(notificationRepo, productRepo, sink, log)
+	// Product writes queue notifications for the life of the process
+	registerObserverOnce.Do(func() {
+		repoProduct.RegisterObserver(notificationService)
+	})
+	return notificationService
+}
+
+// NewNotificationService builds a notification service that delivers through
+// any notifier.
+func NewNotificationService(notificationRepo repository.NotificationRepositoryImpl, productRepo repoProduct.ProductRepositoryImpl, sink notifier.Notifier, log *logger.Logger) *NotificationService {
+	return &NotificationService{
+		repoNotification: notificationRepo,
+		repoProduct:      productRepo,
+		notifier:         sink,
+		logger:           log,
+	}
+}
+
+// Subscribe asks for a notification when a product is back in stock, or when
+// its price drops to targetPrice. Subscribing again to the same product and
+// kind replaces the earlier subscription.
+func (notificationService *NotificationService) Subscribe(ctx context.Context, productID uint, kind string, targetPrice *float64) (*model.Subscription, error) {
+	notificationService.logger.Info("Subscribing to " + kind + " of product with ID: " + fmt.Sprint(productID))
+	customer, ok := jwt.FromCustomer(ctx)
+	if !ok {
+		return nil, customErrors.ErrUnauthorized
+	}
+
+	product, err := notificationService.repoProduct.GetByID(productID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, customErrors.ErrNotFound
+		}
+		notificationService.logger.Error("Failed to fetch product: " + err.Error())
+		return nil, err
+	}
+
+	switch kind {
+	case constant.SUBSCRIPTION_BACK_IN_STOCK:
+		if product.Stok > 0 {
+			return nil, customErrors.ErrInvalidSubscription
+		}
+		targetPrice = nil
+	case constant.SUBSCRIPTION_PRICE_DROP:
+		if targetPrice == nil || *targetPrice <= 0 || *targetPrice >= product.Price {
+			return nil, customErrors.ErrInvalidSubscription
+		}
+	default:
+		return nil, customErrors.ErrInvalidSubscription
+	}
+
+	subscription, err := notificationService.repoNotification.GetSubscription(customer.ID, productID, kind)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			notificationService.logger.Error("Failed to fetch subscription: " + err.Error())
+			return nil, err
+		}
+		subscription = &model.Subscription{CustomerID: customer.ID, ProductID: productID, Kind: kind}
+	}
+
+	subscription.Email = customer.Email
+	subscription.TargetPrice = targetPrice
+	subscription.Active = true
+	subscription.NotifiedAt = nil
+	if err := notificationService.repoNotification.SaveSubscription(subscription); err != nil {
+		notificationService.logger.Error("Failed to save subscription: " + err.Error())
+		return nil, err
+	}
+
+	subscription.Product = product
+	return subscription, nil
+}
+
+// GetSubscriptions returns the subscriptions of the signed-in customer,
+// including the ones that have already been notified.
+func (notificationService *NotificationService) GetSubscriptions(ctx context.Context) ([]model.Subscription, error) {
+	notificationService.logger.Info("Fetching subscriptions of customer")
+	customer, ok := jwt.FromCustomer(ctx)
+	if !ok {
+		return nil, customErrors.ErrUnauthorized
+	}
+
+	subscriptions, err := notificationService.repoNotification.GetSubscriptionsByCustomerID(customer.ID)
+	if err != nil {
+		notificationService.logger.Error("Failed to fetch subscriptions: " + err.Error())
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// Unsubscribe removes one of the signed-in customer's subscriptions.
+func (notificationService *NotificationService) Unsubscribe(ctx context.Context, subscriptionID uint) error {
+	notificationService.logger.Info("Removing subscription with ID: " + fmt.Sprint(subscriptionID))
+	customer, ok := jwt.FromCustomer(ctx)
+	if !ok {
+		return customErrors.ErrUnauthorized
+	}
+
+	subscription, err := notificationService.repoNotification.GetSubscriptionByID(subscriptionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return customErrors.ErrNotFound
+		}
+		notificationService.logger.Error("Failed to fetch subscription: " + err.Error())
+		return err
+	}
+	if subscription.CustomerID != customer.ID {
+		return customErrors.ErrNotFound
+	}
+
+	if err := notificationService.repoNotification.DeleteSubscription(subscriptionID); err != nil {
+		notificationService.logger.Error("Failed to remove subscription: " + err.Error())
+		return err
+	}
+	return nil
+}
+
+// ProductChanged queues a notification for every active subscription the
+// committed product now satisfies, such as stock going from zero to
+// positive or the price dropping to a subscriber's target.
+func (notificationService *NotificationService) ProductChanged(product *productModel.Product) {
+	subscriptions, err := notificationService.repoNotification.GetActiveSubscriptions(product.ID)
+	if err != nil {
+		notificationService.logger.Error("Failed to fetch subscriptions: " + err.Error())
+		return
+	}
+
+	for i := range subscriptions {
+		subscription := &subscriptions[i]
+		if !subscription.Triggered(product) {
+			continue
+		}
+
+		queued, err := notificationService.repoNotification.Queue(model.NewNotification(subscription, product))
+		if err != nil {
+			notificationService.logger.Error("Failed to queue notification: " + err.Error())
+			continue
+		}
+		if queued {
+			notificationService.logger.Info("Queued " + subscription.Kind + " notification for subscription with ID: " + fmt.Sprint(subscription.ID))
+		}
+	}
+}
+
+// ProductDeleted drops the subscriptions to a deleted product.
+func (notificationService *NotificationService) ProductDeleted(productID uint) {
+	if err := notificationService.repoNotification.DeleteProductSubscriptions(productID); err != nil {
+		notificationService.logger.Error("Failed to remove subscriptions: " + err.Error())
+	}
+}
+
+// DispatchNotifications sends queued notifications through the notifier and
+// returns how many were delivered. A failed delivery is retried on the next
+// run until MaxAttempts is reached.
+func (notificationService *NotificationService) DispatchNotifications(ctx context.Context) (int, error) {
+	notifications, err := notificationService.repoNotification.GetPendingNotifications(dispatchBatchSize)
+	if err != nil {
+		notificationService.logger.Error("Failed to fetch queued notifications: " + err.Error())
+		return 0, err
+	}
+
+	sent := 0
+	for i := range notifications {
+		notification := &notifications[i]
+		err := notificationService.notifier.Send(ctx, notifier.Message{
+			To:      notification.Recipient,
+			Subject: notification.Subject,
+			Body:    notification.Body,
+		})
+
+		notification.Attempts++
+		if err != nil {
+			notificationService.logger.Error("Failed to send notification with ID: " + fmt.Sprint(notification.ID) + ": " + err.Error())
+			notification.LastError = err.Error()
+			if notification.Attempts >= model.MaxAttempts {
+				notification.Status = constant.NOTIFICATION_STATUS_FAILED
+			}
+		} else {
+			now := time.Now()
+			notification.Status = constant.NOTIFICATION_STATUS_SENT
+			notification.SentAt = &now
+			notification.LastError = ""
+			sent++
+		}
+
+		if err := notificationService.repoNotification.UpdateNotification(notification); err != nil {
+			notificationService.logger.Error("Failed to update notification: " + err.Error())
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+// StartDispatcher sends queued notifications every interval until ctx is
+// done.
+func (notificationService *NotificationService) StartDispatcher(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				notificationService.DispatchNotifications(ctx)
+			}
+		}
+	}()
+}
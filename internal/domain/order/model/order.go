@@ -5,38 +5,61 @@ import (
 )
 
 type Order struct {
-	ID              uint        `json:"id"`
-	CustomerID      uint        `json:"customer_id"`
-	OrderBy         string      `json:"order_by"`
-	OrderNumber     string      `json:"order_number"`
-	OrderDate       time.Time   `json:"order_date"`
-	Total           float64     `json:"total"`
-	ShippingFee     float64     `json:"shipping_fee"`
-	Subtotal        float64     `json:"subtotal"`
-	Tax             float64     `json:"tax"`
-	Discount        float64     `json:"discount"`
-	OrderStatus     string      `json:"order_status"`
-	PaymentID       uint        `json:"payment_id"`
-	PaymentDate     time.Time   `json:"payment_date"`
-	PaymentStatus   string      `json:"payment_status"`
-	ShippingAddress string      `json:"shipping_address"`
-	BillingAddress  string      `json:"billing_address"`
-	Currency        string      `json:"currency"`
-	Items           []OrderItem `json:"items"`
-	CreatedAt       time.Time   `json:"created_at"`
-	UpdatedAt       time.Time   `json:"updated_at"`
+	ID              uint                 `json:"id"`
+	CustomerID      uint                 `json:"customer_id"`
+	OrderBy         string               `json:"order_by"`
+	OrderNumber     string               `json:"order_number"`
+	OrderDate       time.Time            `json:"order_date"`
+	Total           float64              `json:"total"`
+	ShippingFee     float64              `json:"shipping_fee"`
+	Subtotal        float64              `json:"subtotal"`
+	Tax             float64              `json:"tax"`
+	Discount        float64              `json:"discount"`
+	OrderStatus     string               `json:"order_status"`
+	StatusUpdatedAt *time.Time           `json:"status_updated_at"`
+	CancelReason    string               `json:"cancel_reason,omitempty"`
+	CancelledAt     *time.Time           `json:"cancelled_at,omitempty"`
+	PaymentID       uint                 `json:"payment_id"`
+	PaymentDate     time.Time            `json:"payment_date"`
+	PaymentStatus   string               `json:"payment_status"`
+	PaymentDueAt    *time.Time           `json:"payment_due_at"`
+	ShippingAddress string               `json:"shipping_address"`
+	BillingAddress  string               `json:"billing_address"`
+	Currency        string               `json:"currency"`
+	Items           []OrderItem          `json:"items"`
+	Promotions      []OrderPromotion     `json:"promotions,omitempty" gorm:"foreignKey:OrderID"`
+	Transactions    []Transaction        `json:"transactions,omitempty" gorm:"foreignKey:OrderID"`
+	StatusHistory   []OrderStatusHistory `json:"status_history,omitempty" gorm:"foreignKey:OrderID"`
+	CreatedAt       time.Time            `json:"created_at"`
+	UpdatedAt       time.Time            `json:"updated_at"`
 }
 
 type OrderItem struct {
 	ID           uint    `json:"id"`
 	OrderID      uint    `json:"order_id"`
 	ProductID    uint    `json:"product_id"`
+	VariantID    *uint   `json:"variant_id"`
+	WarehouseID  *uint   `json:"warehouse_id"`
+	SKU          string  `json:"sku"`
 	ProductName  string  `json:"product_name"`
 	ProductPrice float64 `json:"product_price"`
 	Quantity     uint    `json:"quantity"`
 	Subtotal     float64 `json:"subtotal"` // Harga total untuk item ini (ProductPrice * Quantity)
 }
 
+// OrderPromotion is a promotion taken off an order, as it was when the order
+// was placed. PromotionID is empty for abandoned cart codes.
+type OrderPromotion struct {
+	ID          uint      `json:"id"`
+	OrderID     uint      `json:"order_id" gorm:"index"`
+	PromotionID *uint     `json:"promotion_id,omitempty" gorm:"index"`
+	Code        string    `json:"code,omitempty"`
+	Name        string    `json:"name"`
+	Type        string    `json:"type"`
+	Amount      float64   `json:"amount"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
 type Transaction struct {
 	ID            string    `gorm:"primary_key" json:"id"`
 	OrderID       uint      `json:"order_id"`
@@ -53,6 +76,9 @@ func (Order) TableName() string {
 func (OrderItem) TableName() string {
 	return "OrderItem"
 }
+func (OrderPromotion) TableName() string {
+	return "OrderPromotion"
+}
 func (Transaction) TableName() string {
 	return "Transaction"
 }
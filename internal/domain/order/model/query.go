@@ -0,0 +1,23 @@
+package model
+
+import (
+	"go-online-store/pkg/pagination"
+	"time"
+)
+
+// OrderQuery describes a paginated listing of one customer's orders, newest
+// first. An empty Status lists every status; From and To, when set, bound
+// the time the orders were placed, To exclusive.
+type OrderQuery struct {
+	CustomerID uint
+	Status     string
+	From       *time.Time
+	To         *time.Time
+	Pagination pagination.Params
+}
+
+// OrderPage is one page of an order listing.
+type OrderPage struct {
+	Orders []Order
+	Total  int64
+}
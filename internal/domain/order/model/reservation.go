@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// StockReservation holds stock taken out of availability for an unpaid
+// order. It is committed when the order is paid, or released back to stock
+// once ExpiresAt has passed.
+type StockReservation struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	OrderID     uint      `json:"order_id" gorm:"column:order_id;not null;index"`
+	ProductID   uint      `json:"product_id" gorm:"column:product_id;not null"`
+	VariantID   *uint     `json:"variant_id" gorm:"column:variant_id"`
+	WarehouseID *uint     `json:"warehouse_id" gorm:"column:warehouse_id"`
+	Quantity    uint      `json:"quantity" gorm:"column:quantity;not null"`
+	Status      string    `json:"status" gorm:"column:status;size:16;not null;index:idx_reservation_status_expiry"`
+	ExpiresAt   time.Time `json:"expires_at" gorm:"column:expires_at;not null;index:idx_reservation_status_expiry"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (StockReservation) TableName() string {
+	return "StockReservation"
+}
@@ -0,0 +1,126 @@
+package model
+
+import (
+	"go-online-store/pkg/constant"
+	customErrors "go-online-store/pkg/errors"
+	"time"
+)
+
+// OrderStatusHistory records one change of the status of an order: who made
+// it, acting in which role, and when.
+type OrderStatusHistory struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	OrderID    uint      `json:"order_id" gorm:"not null;index"`
+	FromStatus string    `json:"from_status" gorm:"size:20"`
+	ToStatus   string    `json:"to_status" gorm:"size:20;not null"`
+	Actor      string    `json:"actor" gorm:"not null"`
+	Role       string    `json:"role" gorm:"size:20;not null"`
+	Note       string    `json:"note,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (OrderStatusHistory) TableName() string {
+	return "OrderStatusHistory"
+}
+
+// transitions lists, for each status, the statuses an order may move to and
+// the roles allowed to make the change. Delivered, refunded and failed orders
+// are final. Only cancelling a paid order refunds it, since that is where the
+// refund is paid out and the stock returned.
+var transitions = map[string]map[string][]string{
+	constant.ORDER_STATUS_PENDING: {
+		constant.ORDER_STATUS_AWAITING_PAYMENT: {constant.ROLE_SYSTEM},
+		// Orders placed before payments were awaited explicitly
+		constant.ORDER_STATUS_PAID:      {constant.ROLE_SYSTEM},
+		constant.ORDER_STATUS_CANCELLED: {constant.ROLE_SYSTEM, constant.ROLE_CUSTOMER, constant.ROLE_ADMIN},
+		constant.ORDER_STATUS_FAILED:    {constant.ROLE_SYSTEM},
+	},
+	constant.ORDER_STATUS_AWAITING_PAYMENT: {
+		constant.ORDER_STATUS_PAID:      {constant.ROLE_SYSTEM},
+		constant.ORDER_STATUS_CANCELLED: {constant.ROLE_SYSTEM, constant.ROLE_CUSTOMER, constant.ROLE_ADMIN},
+		constant.ORDER_STATUS_FAILED:    {constant.ROLE_SYSTEM},
+	},
+	constant.ORDER_STATUS_PAID: {
+		constant.ORDER_STATUS_PROCESSING: {constant.ROLE_ADMIN},
+		constant.ORDER_STATUS_CANCELLED:  {constant.ROLE_CUSTOMER, constant.ROLE_ADMIN},
+	},
+	constant.ORDER_STATUS_PROCESSING: {
+		constant.ORDER_STATUS_SHIPPED:   {constant.ROLE_ADMIN},
+		constant.ORDER_STATUS_CANCELLED: {constant.ROLE_CUSTOMER, constant.ROLE_ADMIN},
+	},
+	constant.ORDER_STATUS_SHIPPED: {
+		constant.ORDER_STATUS_DELIVERED: {constant.ROLE_ADMIN},
+		constant.ORDER_STATUS_CANCELLED: {constant.ROLE_ADMIN},
+	},
+	constant.ORDER_STATUS_DELIVERED: {},
+	constant.ORDER_STATUS_CANCELLED: {
+		constant.ORDER_STATUS_REFUNDED: {constant.ROLE_SYSTEM},
+	},
+}
+
+// ValidCancelReason reports whether reason is one of the cancellation reason
+// codes.
+func ValidCancelReason(reason string) bool {
+	switch reason {
+	case constant.CANCEL_REASON_CHANGED_MIND, constant.CANCEL_REASON_ORDERED_BY_MISTAKE,
+		constant.CANCEL_REASON_FOUND_CHEAPER, constant.CANCEL_REASON_DELIVERY_TOO_SLOW,
+		constant.CANCEL_REASON_OUT_OF_STOCK, constant.CANCEL_REASON_PAYMENT_ISSUE,
+		constant.CANCEL_REASON_FRAUD_SUSPECTED, constant.CANCEL_REASON_OTHER:
+		return true
+	}
+	return false
+}
+
+// ValidOrderStatus reports whether status is one of the order statuses.
+func ValidOrderStatus(status string) bool {
+	if _, ok := transitions[status]; ok {
+		return true
+	}
+	return status == constant.ORDER_STATUS_REFUNDED || status == constant.ORDER_STATUS_FAILED
+}
+
+// CanTransition reports whether role may move an order from one status to
+// another.
+func CanTransition(from, to, role string) bool {
+	for _, allowed := range transitions[from][to] {
+		if allowed == role {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckTransition returns ErrInvalidTransition when the state machine does
+// not allow moving an order from one status to another and ErrForbidden when
+// role may not make that change.
+func CheckTransition(from, to, role string) error {
+	if _, ok := transitions[from][to]; !ok {
+		return customErrors.ErrInvalidTransition
+	}
+	if !CanTransition(from, to, role) {
+		return customErrors.ErrForbidden
+	}
+	return nil
+}
+
+// Transition moves the order to status to on behalf of actor, acting in
+// role, and returns the history entry to record. Changes CheckTransition
+// rejects fail with its error.
+func (o *Order) Transition(to, role, actor, note string, at time.Time) (*OrderStatusHistory, error) {
+	if err := CheckTransition(o.OrderStatus, to, role); err != nil {
+		return nil, err
+	}
+
+	history := &OrderStatusHistory{
+		OrderID:    o.ID,
+		FromStatus: o.OrderStatus,
+		ToStatus:   to,
+		Actor:      actor,
+		Role:       role,
+		Note:       note,
+		CreatedAt:  at,
+	}
+	o.OrderStatus = to
+	o.StatusUpdatedAt = &at
+	return history, nil
+}
@@ -3,8 +3,11 @@ package repository
 import (
 	mysql "go-online-store/config/database/my_sql_db"
 	"go-online-store/internal/domain/order/model"
+	"go-online-store/pkg/constant"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type OrderRepository struct {
@@ -15,9 +18,22 @@ type OrderRepositoryImpl interface {
 	CreateOrder(order *model.Order) error
 	UpdateOrder(order *model.Order) error
 	GetOrderById(id uint) (*model.Order, error)
+	ListCustomerOrders(query model.OrderQuery) (*model.OrderPage, error)
+	GetCustomerOrder(customerID, id uint) (*model.Order, error)
+	RecordStatusChange(history *model.OrderStatusHistory) error
 	CreateTransaction(transaction *model.Transaction) error
 	UpdateTransaction(transaction *model.Transaction) error
 	GetTransactionByID(id uint) (*model.Transaction, error)
+	GetTransactionByOrderID(orderID uint) (*model.Transaction, error)
+	CreateReservations(reservations []model.StockReservation) error
+	GetReservationsByOrderID(orderID uint) ([]model.StockReservation, error)
+	CommitReservations(orderID uint) (int64, error)
+	SetReservationStatus(orderID uint, from, to string) (int64, error)
+	ReleaseExpiredReservations(orderID uint, now time.Time) (int64, error)
+	GetOrderIDsWithExpiredReservations(now time.Time) ([]uint, error)
+	GetOrderForUpdate(id uint) (*model.Order, error)
+	GetPaidOrderWithProduct(customerID uint, productID uint) (*model.Order, error)
+	WithTx(tx *gorm.DB) OrderRepositoryImpl
 }
 
 func NewInstanceOrderRepository() (OrderRepositoryImpl, error) {
@@ -26,9 +42,15 @@ func NewInstanceOrderRepository() (OrderRepositoryImpl, error) {
 		return nil, err
 	}
 
+	db.AutoMigrate(&model.Order{}, &model.OrderItem{}, &model.Transaction{}, &model.StockReservation{}, &model.OrderStatusHistory{}, &model.OrderPromotion{})
 	return &OrderRepository{db: db}, nil
 }
 
+// WithTx returns a repository that runs its statements in tx.
+func (orderRepo *OrderRepository) WithTx(tx *gorm.DB) OrderRepositoryImpl {
+	return &OrderRepository{db: tx}
+}
+
 func (orderRepo *OrderRepository) GetOrderById(id uint) (*model.Order, error) {
 	var order model.Order
 	if err := orderRepo.db.First(&order, id).Error; err != nil {
@@ -37,12 +59,68 @@ func (orderRepo *OrderRepository) GetOrderById(id uint) (*model.Order, error) {
 	return &order, nil
 }
 
+// ListCustomerOrders returns one page of a customer's orders with their
+// items, newest first.
+func (orderRepo *OrderRepository) ListCustomerOrders(query model.OrderQuery) (*model.OrderPage, error) {
+	db := orderRepo.db.Model(&model.Order{}).Where("customer_id = ?", query.CustomerID)
+	if query.Status != "" {
+		db = db.Where("order_status = ?", query.Status)
+	}
+	if query.From != nil {
+		db = db.Where("created_at >= ?", *query.From)
+	}
+	if query.To != nil {
+		db = db.Where("created_at < ?", *query.To)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var orders []model.Order
+	params := query.Pagination
+	err := db.Preload("Items").
+		Order("created_at DESC, id DESC").
+		Offset(params.Offset()).
+		Limit(params.Limit).
+		Find(&orders).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.OrderPage{Orders: orders, Total: total}, nil
+}
+
+// GetCustomerOrder returns an order of a customer with its items,
+// promotions, transactions and status history. Orders of other customers are not found.
+func (orderRepo *OrderRepository) GetCustomerOrder(customerID, id uint) (*model.Order, error) {
+	var order model.Order
+	err := orderRepo.db.Preload("Items").
+		Preload("Promotions").
+		Preload("Transactions", func(db *gorm.DB) *gorm.DB { return db.Order("created_at") }).
+		Preload("StatusHistory", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Where("customer_id = ?", customerID).
+		First(&order, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
 func (orderRepo *OrderRepository) CreateOrder(order *model.Order) error {
 	return orderRepo.db.Create(order).Error
 }
 
+// UpdateOrder saves the order itself; its items, transactions and history
+// are left as they are.
 func (orderRepo *OrderRepository) UpdateOrder(order *model.Order) error {
-	return orderRepo.db.Save(order).Error
+	return orderRepo.db.Omit(clause.Associations).Save(order).Error
+}
+
+// RecordStatusChange appends an entry to the status history of an order.
+func (orderRepo *OrderRepository) RecordStatusChange(history *model.OrderStatusHistory) error {
+	return orderRepo.db.Create(history).Error
 }
 
 func (orderRepo *OrderRepository) CreateTransaction(transaction *model.Transaction) error {
@@ -60,3 +138,89 @@ func (orderRepo *OrderRepository) GetTransactionByID(id uint) (*model.Transactio
 	}
 	return &transaction, nil
 }
+
+func (orderRepo *OrderRepository) GetTransactionByOrderID(orderID uint) (*model.Transaction, error) {
+	var transaction model.Transaction
+	if err := orderRepo.db.Where("order_id = ?", orderID).Order("created_at DESC").First(&transaction).Error; err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+func (orderRepo *OrderRepository) CreateReservations(reservations []model.StockReservation) error {
+	if len(reservations) == 0 {
+		return nil
+	}
+	return orderRepo.db.Create(&reservations).Error
+}
+
+func (orderRepo *OrderRepository) GetReservationsByOrderID(orderID uint) ([]model.StockReservation, error) {
+	var reservations []model.StockReservation
+	if err := orderRepo.db.Where("order_id = ?", orderID).Find(&reservations).Error; err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+// CommitReservations makes the active reservations of an order final and
+// returns how many were committed. Expired reservations are left untouched.
+func (orderRepo *OrderRepository) CommitReservations(orderID uint) (int64, error) {
+	result := orderRepo.db.Model(&model.StockReservation{}).
+		Where("order_id = ? AND status = ?", orderID, constant.RESERVATION_STATUS_ACTIVE).
+		Update("status", constant.RESERVATION_STATUS_COMMITTED)
+	return result.RowsAffected, result.Error
+}
+
+// SetReservationStatus moves the reservations of an order in status from to
+// status to and returns how many were moved.
+func (orderRepo *OrderRepository) SetReservationStatus(orderID uint, from, to string) (int64, error) {
+	result := orderRepo.db.Model(&model.StockReservation{}).
+		Where("order_id = ? AND status = ?", orderID, from).
+		Update("status", to)
+	return result.RowsAffected, result.Error
+}
+
+// ReleaseExpiredReservations marks the expired active reservations of an
+// order as released and returns how many were released. The conditional
+// update makes it safe to race with CommitReservations.
+func (orderRepo *OrderRepository) ReleaseExpiredReservations(orderID uint, now time.Time) (int64, error) {
+	result := orderRepo.db.Model(&model.StockReservation{}).
+		Where("order_id = ? AND status = ? AND expires_at <= ?", orderID, constant.RESERVATION_STATUS_ACTIVE, now).
+		Update("status", constant.RESERVATION_STATUS_RELEASED)
+	return result.RowsAffected, result.Error
+}
+
+func (orderRepo *OrderRepository) GetOrderIDsWithExpiredReservations(now time.Time) ([]uint, error) {
+	var orderIDs []uint
+	err := orderRepo.db.Model(&model.StockReservation{}).
+		Where("status = ? AND expires_at <= ?", constant.RESERVATION_STATUS_ACTIVE, now).
+		Distinct().
+		Pluck("order_id", &orderIDs).Error
+	return orderIDs, err
+}
+
+// GetOrderForUpdate reads an order and locks its row until the surrounding
+// transaction ends, so concurrent status changes of one order are serialized.
+func (orderRepo *OrderRepository) GetOrderForUpdate(id uint) (*model.Order, error) {
+	var order model.Order
+	if err := orderRepo.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, id).Error; err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// GetPaidOrderWithProduct returns the earliest paid order of a customer that
+// contains the product.
+func (orderRepo *OrderRepository) GetPaidOrderWithProduct(customerID uint, productID uint) (*model.Order, error) {
+	var order model.Order
+	items := orderRepo.db.Model(&model.OrderItem{}).Select("order_id").Where("product_id = ?", productID)
+	err := orderRepo.db.
+		Where("customer_id = ? AND payment_status = ?", customerID, constant.PAYMENT_STATUS_PAID).
+		Where("id IN (?)", items).
+		Order("id").
+		First(&order).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
@@ -2,33 +2,59 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	mysql "go-online-store/config/database/my_sql_db"
+	orderConfig "go-online-store/config/order"
 	cartModel "go-online-store/internal/domain/cart/model"
 	repoCart "go-online-store/internal/domain/cart/repository"
+	repoCategory "go-online-store/internal/domain/category/repository"
+	inventoryModel "go-online-store/internal/domain/inventory/model"
+	inventoryRepo "go-online-store/internal/domain/inventory/repository"
 	"go-online-store/internal/domain/order/model"
 	repoOrder "go-online-store/internal/domain/order/repository"
+	"go-online-store/internal/domain/pricing"
 	repoProduct "go-online-store/internal/domain/product/repository"
+	promotionModel "go-online-store/internal/domain/promotion/model"
+	repoPromotion "go-online-store/internal/domain/promotion/repository"
+	promotionService "go-online-store/internal/domain/promotion/service"
 	"go-online-store/internal/middleware/jwt"
 	"go-online-store/pkg/constant"
 	customErrors "go-online-store/pkg/errors"
 	"go-online-store/pkg/logger"
 	"os"
+	"sort"
+	"strings"
 
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type OrderService struct {
-	repoOrder   repoOrder.OrderRepositoryImpl
-	repoCart    repoCart.CartRepositoryImpl
-	repoProduct repoProduct.ProductRepositoryImpl
-	logger      *logger.Logger
+	transactor     mysql.Transactor
+	repoOrder      repoOrder.OrderRepositoryImpl
+	repoCart       repoCart.CartRepositoryImpl
+	repoRecovery   repoCart.RecoveryRepositoryImpl
+	repoProduct    repoProduct.ProductRepositoryImpl
+	repoInventory  inventoryRepo.InventoryRepositoryImpl
+	repoWarehouse  inventoryRepo.WarehouseRepositoryImpl
+	repoPromotion  repoPromotion.PromotionRepositoryImpl
+	promotions     promotionService.PromotionServiceImpl
+	reservationTTL time.Duration
+	logger         *logger.Logger
 }
 
 type OrderServiceImpl interface {
-	Checkout(ctx context.Context) (*model.Order, error)
+	Checkout(ctx context.Context, discountCode string) (*model.Order, error)
 	UpdatePaymentStatus(ctx context.Context, orderID uint) error
+	GetOrders(ctx context.Context, query model.OrderQuery) (*model.OrderPage, error)
+	GetOrder(ctx context.Context, orderID uint) (*model.Order, error)
+	UpdateOrderStatus(ctx context.Context, orderID uint, status string, note string) (*model.Order, error)
+	CancelOrder(ctx context.Context, orderID uint, reason string, note string) (*model.Order, error)
+	ReleaseExpiredReservations(ctx context.Context) (int, error)
+	StartReservationSweeper(ctx context.Context, interval time.Duration)
 }
 
 func NewOrderService() (OrderServiceImpl, error) {
@@ -45,21 +71,76 @@ func NewOrderService() (OrderServiceImpl, error) {
 		return nil, err
 	}
 
+	recoveryRepo, err := repoCart.NewRecoveryRepository()
+	if err != nil {
+		log.Error("Failed to initialize cart recovery repository: " + err.Error())
+		return nil, err
+	}
+
 	productRepo, err := repoProduct.NewProductRepository()
 	if err != nil {
 		log.Error("Failed to initialize product repository: " + err.Error())
 		return nil, err
 	}
 
+	inventoryRepository, err := inventoryRepo.NewInventoryRepository()
+	if err != nil {
+		log.Error("Failed to initialize inventory repository: " + err.Error())
+		return nil, err
+	}
+
+	warehouseRepository, err := inventoryRepo.NewWarehouseRepository()
+	if err != nil {
+		log.Error("Failed to initialize warehouse repository: " + err.Error())
+		return nil, err
+	}
+
+	promotionRepo, err := repoPromotion.NewPromotionRepository()
+	if err != nil {
+		log.Error("Failed to initialize promotion repository: " + err.Error())
+		return nil, err
+	}
+
+	categoryRepo, err := repoCategory.NewCategoryRepository()
+	if err != nil {
+		log.Error("Failed to initialize category repository: " + err.Error())
+		return nil, err
+	}
+
+	db, err := mysql.ConnectDatabase()
+	if err != nil {
+		log.Error("Failed to connect database: " + err.Error())
+		return nil, err
+	}
+
+	promotions := promotionService.NewPromotionService(promotionRepo, categoryRepo, log)
+	return NewOrderServiceWith(mysql.NewTransactor(db), orderRepo, cartRepo, recoveryRepo, productRepo, inventoryRepository, warehouseRepository, promotionRepo, promotions, orderConfig.LoadOrderConfig().ReservationTTL, log), nil
+}
+
+// NewOrderServiceWith builds an order service on the given transactor,
+// repositories and promotion service. Unpaid orders hold their stock for
+// reservationTTL.
+func NewOrderServiceWith(transactor mysql.Transactor, orderRepo repoOrder.OrderRepositoryImpl, cartRepo repoCart.CartRepositoryImpl, recoveryRepo repoCart.RecoveryRepositoryImpl, productRepo repoProduct.ProductRepositoryImpl, inventoryRepository inventoryRepo.InventoryRepositoryImpl, warehouseRepository inventoryRepo.WarehouseRepositoryImpl, promotionRepo repoPromotion.PromotionRepositoryImpl, promotions promotionService.PromotionServiceImpl, reservationTTL time.Duration, log *logger.Logger) *OrderService {
 	return &OrderService{
-		repoOrder:   orderRepo,
-		repoCart:    cartRepo,
-		repoProduct: productRepo,
-		logger:      log,
-	}, nil
+		transactor:     transactor,
+		repoOrder:      orderRepo,
+		repoCart:       cartRepo,
+		repoRecovery:   recoveryRepo,
+		repoProduct:    productRepo,
+		repoInventory:  inventoryRepository,
+		repoWarehouse:  warehouseRepository,
+		repoPromotion:  promotionRepo,
+		promotions:     promotions,
+		reservationTTL: reservationTTL,
+		logger:         log,
+	}
 }
 
-func (svcOrder *OrderService) Checkout(ctx context.Context) (*model.Order, error) {
+// Checkout places an order for the customer's cart and reserves its stock
+// until the order is paid. The automatic promotions the cart qualifies for
+// are taken off the order, and so is discountCode, a store coupon or the code
+// of an abandoned cart reminder. Reminder codes are used up.
+func (svcOrder *OrderService) Checkout(ctx context.Context, discountCode string) (*model.Order, error) {
 	svcOrder.logger.Info("Executing Checkout method")
 	// Retrieve customer information from context
 	customerCtx, ok := jwt.FromCustomer(ctx)
@@ -75,93 +156,168 @@ func (svcOrder *OrderService) Checkout(ctx context.Context) (*model.Order, error
 		return nil, err
 	}
 
-	// Check if the cart is empty
-	if cart.Items == nil || len(cart.Items) == 0 {
+	// Check if the cart is empty; lines saved for later are not bought
+	items := cart.Active()
+	if len(items) == 0 {
 		svcOrder.logger.Error("Cart is empty")
 		return nil, customErrors.ErrCartIsEmpty
 	}
 
-	// Calculate subtotal
-	var subtotal float64
-	for _, item := range cart.Items {
-		product, err := svcOrder.repoProduct.GetByID(item.ProductID)
-		if err != nil {
-			svcOrder.logger.Error("Failed to retrieve product: " + err.Error())
-			return nil, err
-		}
-		subtotal += float64(item.Quantity) * product.Price
+	// Prices and stock may have moved since the items were added; the
+	// customer confirms the changes on the cart before ordering
+	warnings, err := svcOrder.checkCart(items)
+	if err != nil {
+		svcOrder.logger.Error("Failed to check cart: " + err.Error())
+		return nil, err
+	}
+	if len(warnings) > 0 {
+		svcOrder.logger.Info("Cart changed since items were added")
+		return nil, &cartModel.ChangedError{Warnings: warnings}
 	}
 
-	// Calculate shipping fee (optionally with discount)
-	shippingFee := calculateShippingFee(cart.Items, true) // Contoh: Apply discount if true
-
-	// Calculate total before tax and discount
-	total := subtotal + shippingFee
-
-	// Apply tax
-	tax := applyTax(subtotal)
-	total += tax
+	// Price the cart the same way the cart preview does
+	breakdown, err := svcOrder.priceCart(items)
+	if err != nil {
+		svcOrder.logger.Error("Failed to price cart: " + err.Error())
+		return nil, err
+	}
 
-	// Apply discount
-	discount := applyDiscount(subtotal)
-	total -= discount
+	coupon, recovery, err := svcOrder.coupon(ctx, customerCtx.ID, discountCode)
+	if err != nil {
+		return nil, err
+	}
+	if err := svcOrder.promotions.ApplyPromotions(ctx, customerCtx.ID, &breakdown, coupon); err != nil {
+		svcOrder.logger.Error("Failed to apply promotions: " + err.Error())
+		return nil, err
+	}
+	total := breakdown.Total
 
 	// Create the order object
+	now := time.Now()
 	order := &model.Order{
 		CustomerID:      customerCtx.ID,
 		OrderNumber:     generateOrderNumber(),
 		OrderBy:         customerCtx.Email,
 		OrderDate:       time.Now(),
 		Total:           total,
-		ShippingFee:     shippingFee,
-		Subtotal:        subtotal,
-		Tax:             tax,
-		Discount:        discount,
+		ShippingFee:     breakdown.ShippingFee,
+		Subtotal:        breakdown.Subtotal,
+		Tax:             breakdown.Tax,
+		Discount:        breakdown.Discount,
 		OrderStatus:     constant.ORDER_STATUS_PENDING,
+		StatusUpdatedAt: &now,
 		PaymentStatus:   constant.PAYMENT_STATUS_PENDING,
 		PaymentDate:     time.Now(),
 		ShippingAddress: customerCtx.Address,
 		BillingAddress:  customerCtx.Address,
-		Currency:        "IDR",
-		Items:           make([]model.OrderItem, 0, len(cart.Items)),
+		Currency:        breakdown.Currency,
+		Items:           make([]model.OrderItem, 0, len(breakdown.Lines)),
+		Promotions:      make([]model.OrderPromotion, 0, len(breakdown.Promotions)),
 	}
 
-	// Populate order items
-	for _, item := range cart.Items {
-		product, err := svcOrder.repoProduct.GetByID(item.ProductID)
-		if err != nil {
-			svcOrder.logger.Error("Failed to retrieve product: " + err.Error())
-			return nil, err
-		}
+	// Record the promotions as they were applied
+	for _, adjustment := range breakdown.Promotions {
+		order.Promotions = append(order.Promotions, model.OrderPromotion{
+			PromotionID: adjustment.PromotionID,
+			Code:        adjustment.Code,
+			Name:        adjustment.Name,
+			Type:        adjustment.Type,
+			Amount:      adjustment.Amount,
+		})
+	}
 
-		orderItem := model.OrderItem{
-			ProductID:    item.ProductID,
-			Quantity:     item.Quantity,
-			ProductName:  product.Name,
-			ProductPrice: product.Price,
-			Subtotal:     float64(item.Quantity) * product.Price,
-		}
-		order.Items = append(order.Items, orderItem)
+	// Populate order items
+	for _, line := range breakdown.Lines {
+		order.Items = append(order.Items, model.OrderItem{
+			ProductID:    line.ProductID,
+			VariantID:    line.VariantID,
+			SKU:          line.SKU,
+			Quantity:     line.Quantity,
+			ProductName:  line.Name,
+			ProductPrice: line.UnitPrice,
+			Subtotal:     line.Subtotal,
+		})
 	}
 
-	// Create the order in the database
-	err = svcOrder.repoOrder.CreateOrder(order)
+	// Pick the warehouses that ship each line; a line split across
+	// warehouses becomes one order item per warehouse
+	reservations, err := svcOrder.allocate(order, customerCtx)
 	if err != nil {
-		svcOrder.logger.Error("Failed to create order: " + err.Error())
 		return nil, err
 	}
 
+	paymentDueAt := time.Now().Add(svcOrder.reservationTTL)
+	order.PaymentDueAt = &paymentDueAt
+
 	transaction := &model.Transaction{
 		ID:            generatePaymentId(),
-		OrderID:       order.ID,
 		PaymentStatus: constant.PAYMENT_STATUS_PENDING,
 		PaymentDate:   time.Now(),
 		Amount:        total,
 	}
 
-	err = svcOrder.repoOrder.CreateTransaction(transaction)
+	// Reserve stock, create the order, its reservations and its transaction
+	// as one unit, so a failure at any step leaves nothing behind
+	err = svcOrder.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		orders := svcOrder.repoOrder.WithTx(tx)
+		products := svcOrder.repoProduct.WithTx(tx)
+		change := inventoryModel.StockChange{Actor: customerCtx.Email, Reference: order.OrderNumber}
+
+		if err := svcOrder.reserveStock(products, change, reservations); err != nil {
+			return err
+		}
+
+		if err := svcOrder.claimPromotions(tx, customerCtx.ID, order.Promotions); err != nil {
+			return err
+		}
+
+		if recovery != nil {
+			redeemed, err := svcOrder.repoRecovery.WithTx(tx).Redeem(recovery.ID, time.Now())
+			if err != nil {
+				svcOrder.logger.Error("Failed to redeem discount code: " + err.Error())
+				return err
+			}
+			if !redeemed {
+				return customErrors.ErrInvalidDiscountCode
+			}
+		}
+
+		// Create the order in the database
+		if err := orders.CreateOrder(order); err != nil {
+			svcOrder.logger.Error("Failed to create order: " + err.Error())
+			return err
+		}
+
+		for i := range reservations {
+			reservations[i].OrderID = order.ID
+			reservations[i].ExpiresAt = paymentDueAt
+		}
+		if err := orders.CreateReservations(reservations); err != nil {
+			svcOrder.logger.Error("Failed to create stock reservations: " + err.Error())
+			return err
+		}
+
+		transaction.OrderID = order.ID
+		if err := orders.CreateTransaction(transaction); err != nil {
+			svcOrder.logger.Error("Failed to create transaction: " + err.Error())
+			return err
+		}
+
+		// The order was placed by the customer and now waits for its payment
+		placed := &model.OrderStatusHistory{
+			OrderID:   order.ID,
+			ToStatus:  constant.ORDER_STATUS_PENDING,
+			Actor:     customerCtx.Email,
+			Role:      constant.ROLE_CUSTOMER,
+			CreatedAt: now,
+		}
+		if err := orders.RecordStatusChange(placed); err != nil {
+			svcOrder.logger.Error("Failed to record order status: " + err.Error())
+			return err
+		}
+		return svcOrder.transition(orders, order, constant.ORDER_STATUS_AWAITING_PAYMENT, constant.ROLE_SYSTEM, constant.ORDER_ACTOR_SYSTEM, "")
+	})
 	if err != nil {
-		svcOrder.logger.Error("Failed to create transaction: " + err.Error())
 		return nil, err
 	}
 
@@ -171,106 +327,671 @@ func (svcOrder *OrderService) Checkout(ctx context.Context) (*model.Order, error
 
 func (svcOrder *OrderService) UpdatePaymentStatus(ctx context.Context, orderID uint) error {
 	svcOrder.logger.Info("Updating payment status")
-	order, err := svcOrder.repoOrder.GetOrderById(orderID)
+	customerCtx, ok := jwt.FromCustomer(ctx)
+	if !ok {
+		return customErrors.ErrCustomerIDNotFound
+	}
+
+	cart, err := svcOrder.repoCart.GetCartByCustomerID(customerCtx.ID)
 	if err != nil {
-		svcOrder.logger.Error("Failed to retrieve order: " + err.Error())
-		return fmt.Errorf("failed to retrieve order: %w", err)
+		return err
 	}
 
-	//baypass
-	order.PaymentStatus = constant.PAYMENT_STATUS_PAID
-	order.PaymentDate = time.Now()
-	order.OrderDate = time.Now()
+	// Confirm the order, its reservations and its transaction and clear the
+	// cart as one unit
+	err = svcOrder.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		orders := svcOrder.repoOrder.WithTx(tx)
 
-	if order.PaymentStatus != constant.PAYMENT_STATUS_PAID && order.PaymentStatus != constant.PAYMENT_STATUS_PENDING && order.PaymentStatus != constant.PAYMENT_STATUS_FAILED {
-		svcOrder.logger.Error("Invalid payment status: " + order.PaymentStatus)
-		return fmt.Errorf("invalid payment status: %s", order.PaymentStatus)
+		// Lock the order so a concurrent confirmation or the reservation
+		// sweeper waits for this one to finish
+		order, err := orders.GetOrderForUpdate(orderID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return customErrors.ErrNotFound
+			}
+			svcOrder.logger.Error("Failed to retrieve order: " + err.Error())
+			return fmt.Errorf("failed to retrieve order: %w", err)
+		}
+		if order.CustomerID != customerCtx.ID {
+			return customErrors.ErrNotFound
+		}
+
+		if order.PaymentStatus == constant.PAYMENT_STATUS_PAID {
+			svcOrder.logger.Info("Order already paid")
+			return nil
+		}
+		if order.PaymentStatus != constant.PAYMENT_STATUS_PENDING {
+			svcOrder.logger.Error("Invalid payment status: " + order.PaymentStatus)
+			return customErrors.ErrReservationExpired
+		}
+
+		// Make the stock reservations final. If none are left active the
+		// reservation window has passed and the stock went back on sale.
+		committed, err := orders.CommitReservations(order.ID)
+		if err != nil {
+			svcOrder.logger.Error("Failed to commit stock reservations: " + err.Error())
+			return fmt.Errorf("failed to commit stock reservations: %w", err)
+		}
+		reservations, err := orders.GetReservationsByOrderID(order.ID)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve stock reservations: %w", err)
+		}
+		if committed == 0 {
+			for _, reservation := range reservations {
+				if reservation.Status == constant.RESERVATION_STATUS_RELEASED {
+					svcOrder.logger.Error("Stock reservation expired for order " + order.OrderNumber)
+					return customErrors.ErrReservationExpired
+				}
+			}
+		}
+
+		// Record the reserved units as sold in the inventory ledger
+		products := svcOrder.repoProduct.WithTx(tx)
+		change := inventoryModel.StockChange{Actor: customerCtx.Email, Reference: order.OrderNumber}
+		for _, reservation := range reservations {
+			if reservation.Status != constant.RESERVATION_STATUS_COMMITTED {
+				continue
+			}
+			change.WarehouseID = reservation.WarehouseID
+			err := products.WithStockChange(change).RecordSale(reservation.ProductID, reservation.VariantID, reservation.Quantity)
+			if err != nil {
+				svcOrder.logger.Error("Failed to record sale: " + err.Error())
+				return err
+			}
+		}
+
+		//baypass
+		order.PaymentStatus = constant.PAYMENT_STATUS_PAID
+		order.PaymentDate = time.Now()
+		order.OrderDate = time.Now()
+
+		if err := svcOrder.transition(orders, order, constant.ORDER_STATUS_PAID, constant.ROLE_SYSTEM, constant.ORDER_ACTOR_SYSTEM, "payment received"); err != nil {
+			svcOrder.logger.Error("Failed to update order: " + err.Error())
+			return fmt.Errorf("failed to update order: %w", err)
+		}
+
+		transaction, err := orders.GetTransactionByOrderID(order.ID)
+		if err != nil {
+			svcOrder.logger.Error("Failed to retrieve transaction: " + err.Error())
+			return fmt.Errorf("failed to retrieve transaction: %w", err)
+		}
+
+		//baypass
+		transaction.PaymentStatus = constant.PAYMENT_STATUS_PAID
+		transaction.PaymentDate = time.Now()
+
+		if err := orders.UpdateTransaction(transaction); err != nil {
+			svcOrder.logger.Error("Failed to update transaction: " + err.Error())
+			return fmt.Errorf("failed to update transaction: %w", err)
+		}
+
+		// Clear the customer's cart after successful checkout
+		return svcOrder.repoCart.WithTx(tx).ClearCart(cart.ID)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Credit the abandoned cart reminder that brought the customer back
+	if err := svcOrder.repoRecovery.MarkConverted(cart.ID, orderID, time.Now()); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		svcOrder.logger.Error("Failed to record cart recovery conversion: " + err.Error())
 	}
 
-	err = svcOrder.repoOrder.UpdateOrder(order)
+	svcOrder.logger.Info("Payment status updated successfully")
+	return nil
+}
+
+// GetOrders returns one page of the signed-in customer's orders, newest
+// first, optionally limited to one status and a time range.
+func (svcOrder *OrderService) GetOrders(ctx context.Context, query model.OrderQuery) (*model.OrderPage, error) {
+	svcOrder.logger.Info("Fetching order history")
+	customerCtx, ok := jwt.FromCustomer(ctx)
+	if !ok {
+		return nil, customErrors.ErrCustomerIDNotFound
+	}
+	if query.Status != "" && !model.ValidOrderStatus(query.Status) {
+		return nil, customErrors.ErrBadRequest
+	}
+	if query.From != nil && query.To != nil && !query.From.Before(*query.To) {
+		return nil, customErrors.ErrBadRequest
+	}
+
+	query.CustomerID = customerCtx.ID
+	query.Pagination = query.Pagination.Normalize()
+	page, err := svcOrder.repoOrder.ListCustomerOrders(query)
 	if err != nil {
-		svcOrder.logger.Error("Failed to update order: " + err.Error())
-		return fmt.Errorf("failed to update order: %w", err)
+		svcOrder.logger.Error("Failed to fetch orders: " + err.Error())
+		return nil, err
 	}
+	return page, nil
+}
 
-	transaction, err := svcOrder.repoOrder.GetTransactionByID(order.PaymentID)
+// GetOrder returns an order of the signed-in customer with its items and
+// transactions.
+func (svcOrder *OrderService) GetOrder(ctx context.Context, orderID uint) (*model.Order, error) {
+	svcOrder.logger.Info("Fetching order with ID: " + fmt.Sprint(orderID))
+	customerCtx, ok := jwt.FromCustomer(ctx)
+	if !ok {
+		return nil, customErrors.ErrCustomerIDNotFound
+	}
+
+	order, err := svcOrder.repoOrder.GetCustomerOrder(customerCtx.ID, orderID)
 	if err != nil {
-		svcOrder.logger.Error("Failed to retrieve transaction: " + err.Error())
-		return fmt.Errorf("failed to retrieve transaction: %w", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, customErrors.ErrNotFound
+		}
+		svcOrder.logger.Error("Failed to fetch order: " + err.Error())
+		return nil, err
+	}
+	return order, nil
+}
+
+// UpdateOrderStatus moves an order along its lifecycle on behalf of the
+// signed-in admin, e.g. from paid to processing or from shipped to
+// delivered. Cancelling returns stock and refunds, so it goes through
+// CancelOrder.
+func (svcOrder *OrderService) UpdateOrderStatus(ctx context.Context, orderID uint, status string, note string) (*model.Order, error) {
+	svcOrder.logger.Info("Updating status of order with ID: " + fmt.Sprint(orderID) + " to " + status)
+	customerCtx, ok := jwt.FromCustomer(ctx)
+	if !ok {
+		return nil, customErrors.ErrCustomerIDNotFound
+	}
+	if !model.ValidOrderStatus(status) {
+		return nil, customErrors.ErrBadRequest
+	}
+	if status == constant.ORDER_STATUS_CANCELLED || status == constant.ORDER_STATUS_REFUNDED {
+		return nil, customErrors.ErrInvalidTransition
 	}
 
-	//baypass
-	transaction.PaymentStatus = constant.PAYMENT_STATUS_PAID
-	transaction.PaymentDate = time.Now()
+	role := constant.ROLE_CUSTOMER
+	if customerCtx.IsAdmin() {
+		role = constant.ROLE_ADMIN
+	}
+
+	var order *model.Order
+	err := svcOrder.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		orders := svcOrder.repoOrder.WithTx(tx)
 
-	err = svcOrder.repoOrder.UpdateTransaction(transaction)
+		var err error
+		order, err = orders.GetOrderForUpdate(orderID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return customErrors.ErrNotFound
+			}
+			svcOrder.logger.Error("Failed to retrieve order: " + err.Error())
+			return err
+		}
+		return svcOrder.transition(orders, order, status, role, customerCtx.Email, strings.TrimSpace(note))
+	})
 	if err != nil {
-		svcOrder.logger.Error("Failed to update transaction: " + err.Error())
-		return fmt.Errorf("failed to update transaction: %w", err)
+		return nil, err
 	}
+	return order, nil
+}
 
+// CancelOrder cancels an order for reason on behalf of the signed-in
+// customer or admin. Customers may cancel their own orders until they ship,
+// admins any order until it is delivered. Reserved stock is released, sold
+// stock is returned, an unpaid transaction is voided and a paid order is
+// refunded.
+func (svcOrder *OrderService) CancelOrder(ctx context.Context, orderID uint, reason string, note string) (*model.Order, error) {
+	svcOrder.logger.Info("Cancelling order with ID: " + fmt.Sprint(orderID))
 	customerCtx, ok := jwt.FromCustomer(ctx)
 	if !ok {
-		return customErrors.ErrCustomerIDNotFound
+		return nil, customErrors.ErrCustomerIDNotFound
+	}
+	if !model.ValidCancelReason(reason) {
+		return nil, customErrors.ErrBadRequest
 	}
 
-	cart, err := svcOrder.repoCart.GetCartByCustomerID(customerCtx.ID)
+	role := constant.ROLE_CUSTOMER
+	if customerCtx.IsAdmin() {
+		role = constant.ROLE_ADMIN
+	}
+
+	var order *model.Order
+	err := svcOrder.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		orders := svcOrder.repoOrder.WithTx(tx)
+
+		var err error
+		order, err = orders.GetOrderForUpdate(orderID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return customErrors.ErrNotFound
+			}
+			svcOrder.logger.Error("Failed to retrieve order: " + err.Error())
+			return err
+		}
+		if role == constant.ROLE_CUSTOMER && order.CustomerID != customerCtx.ID {
+			return customErrors.ErrNotFound
+		}
+		if err := model.CheckTransition(order.OrderStatus, constant.ORDER_STATUS_CANCELLED, role); err != nil {
+			return err
+		}
+
+		// Put the stock of the order back on sale
+		reservations, err := orders.GetReservationsByOrderID(order.ID)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve stock reservations: %w", err)
+		}
+		if _, err := orders.SetReservationStatus(order.ID, constant.RESERVATION_STATUS_ACTIVE, constant.RESERVATION_STATUS_RELEASED); err != nil {
+			return fmt.Errorf("failed to release stock reservations: %w", err)
+		}
+		if _, err := orders.SetReservationStatus(order.ID, constant.RESERVATION_STATUS_COMMITTED, constant.RESERVATION_STATUS_RETURNED); err != nil {
+			return fmt.Errorf("failed to return stock reservations: %w", err)
+		}
+
+		products := svcOrder.repoProduct.WithTx(tx)
+		change := inventoryModel.StockChange{
+			Actor:     customerCtx.Email,
+			Reference: order.OrderNumber,
+			Note:      "order cancelled",
+		}
+		for _, reservation := range reservations {
+			change.WarehouseID = reservation.WarehouseID
+			stock := products.WithStockChange(change)
+
+			switch reservation.Status {
+			case constant.RESERVATION_STATUS_ACTIVE:
+				err = stock.ReleaseStock(reservation.ProductID, reservation.VariantID, reservation.Quantity)
+			case constant.RESERVATION_STATUS_COMMITTED:
+				err = stock.AdjustStock(reservation.ProductID, reservation.VariantID, int(reservation.Quantity), constant.STOCK_REASON_RETURN)
+			default:
+				continue
+			}
+			if err != nil {
+				svcOrder.logger.Error("Failed to return stock: " + err.Error())
+				return err
+			}
+		}
+
+		// Void the pending payment or pay back the paid one
+		paid := order.PaymentStatus == constant.PAYMENT_STATUS_PAID
+		transaction, err := orders.GetTransactionByOrderID(order.ID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			svcOrder.logger.Error("Failed to retrieve transaction: " + err.Error())
+			return fmt.Errorf("failed to retrieve transaction: %w", err)
+		}
+		if transaction != nil {
+			switch transaction.PaymentStatus {
+			case constant.PAYMENT_STATUS_PENDING:
+				transaction.PaymentStatus = constant.PAYMENT_STATUS_VOIDED
+				if err := orders.UpdateTransaction(transaction); err != nil {
+					return fmt.Errorf("failed to void transaction: %w", err)
+				}
+				order.PaymentStatus = constant.PAYMENT_STATUS_VOIDED
+			case constant.PAYMENT_STATUS_PAID:
+				refund := &model.Transaction{
+					ID:            generatePaymentId(),
+					OrderID:       order.ID,
+					PaymentStatus: constant.PAYMENT_STATUS_REFUNDED,
+					PaymentDate:   time.Now(),
+					Amount:        -transaction.Amount,
+				}
+				if err := orders.CreateTransaction(refund); err != nil {
+					svcOrder.logger.Error("Failed to create refund: " + err.Error())
+					return fmt.Errorf("failed to create refund: %w", err)
+				}
+				order.PaymentStatus = constant.PAYMENT_STATUS_REFUNDED
+			}
+		}
+
+		now := time.Now()
+		order.CancelReason = reason
+		order.CancelledAt = &now
+		cancelNote := reason
+		if note = strings.TrimSpace(note); note != "" {
+			cancelNote += ": " + note
+		}
+		if err := svcOrder.transition(orders, order, constant.ORDER_STATUS_CANCELLED, role, customerCtx.Email, cancelNote); err != nil {
+			return err
+		}
+		if paid {
+			return svcOrder.transition(orders, order, constant.ORDER_STATUS_REFUNDED, constant.ROLE_SYSTEM, constant.ORDER_ACTOR_SYSTEM, "refund issued")
+		}
+		return nil
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Update stock on products and delete cart items
-	for _, item := range cart.Items {
-		product, err := svcOrder.repoProduct.GetByID(item.ProductID)
+	svcOrder.logger.Info("Order " + order.OrderNumber + " cancelled")
+	return order, nil
+}
+
+// ReleaseExpiredReservations returns the stock of unpaid orders whose
+// reservation window has passed and cancels those orders. It returns the
+// number of orders released.
+func (svcOrder *OrderService) ReleaseExpiredReservations(ctx context.Context) (int, error) {
+	now := time.Now()
+	orderIDs, err := svcOrder.repoOrder.GetOrderIDsWithExpiredReservations(now)
+	if err != nil {
+		svcOrder.logger.Error("Failed to find expired reservations: " + err.Error())
+		return 0, err
+	}
+
+	released := 0
+	for _, orderID := range orderIDs {
+		expired, err := svcOrder.expireOrder(orderID, now)
+		if err != nil {
+			svcOrder.logger.Error("Failed to release expired order: " + err.Error())
+			continue
+		}
+		if expired {
+			released++
+		}
+	}
+
+	if released > 0 {
+		svcOrder.logger.Info(fmt.Sprintf("Released stock of %d expired orders", released))
+	}
+	return released, nil
+}
+
+// StartReservationSweeper releases expired reservations every interval
+// until ctx is done.
+func (svcOrder *OrderService) StartReservationSweeper(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				svcOrder.ReleaseExpiredReservations(ctx)
+			}
+		}
+	}()
+}
+
+// expireOrder releases the expired reservations of an order, returns their
+// stock and cancels the order in one transaction. It reports false when the
+// order was paid in the meantime.
+func (svcOrder *OrderService) expireOrder(orderID uint, now time.Time) (bool, error) {
+	expired := false
+	err := svcOrder.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		orders := svcOrder.repoOrder.WithTx(tx)
+
+		order, err := orders.GetOrderForUpdate(orderID)
 		if err != nil {
 			return err
 		}
+		products := svcOrder.repoProduct.WithTx(tx)
+		change := inventoryModel.StockChange{
+			Actor:     constant.STOCK_ACTOR_SYSTEM,
+			Reference: order.OrderNumber,
+			Note:      "payment window expired",
+		}
 
-		if product.Stok < item.Quantity {
-			return customErrors.ErrProductStockNotAvailable
+		count, err := orders.ReleaseExpiredReservations(orderID, now)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return nil
 		}
 
-		newStock := product.Stok - item.Quantity
-		err = svcOrder.repoProduct.UpdateStock(product.ID, newStock)
+		reservations, err := orders.GetReservationsByOrderID(orderID)
 		if err != nil {
 			return err
 		}
+		for _, reservation := range reservations {
+			if reservation.Status != constant.RESERVATION_STATUS_RELEASED {
+				continue
+			}
+			change.WarehouseID = reservation.WarehouseID
+			err := products.WithStockChange(change).ReleaseStock(reservation.ProductID, reservation.VariantID, reservation.Quantity)
+			if err != nil {
+				return err
+			}
+		}
+
+		order.PaymentStatus = constant.PAYMENT_STATUS_EXPIRED
+		if err := svcOrder.transition(orders, order, constant.ORDER_STATUS_CANCELLED, constant.ROLE_SYSTEM, constant.ORDER_ACTOR_SYSTEM, "payment window expired"); err != nil {
+			return err
+		}
 
-		err = svcOrder.repoCart.DeleteCartItem(cart.ID, product.ID)
+		transaction, err := orders.GetTransactionByOrderID(orderID)
 		if err != nil {
 			return err
 		}
+		transaction.PaymentStatus = constant.PAYMENT_STATUS_EXPIRED
+		if err := orders.UpdateTransaction(transaction); err != nil {
+			return err
+		}
+
+		expired = true
+		return nil
+	})
+	return expired, err
+}
+
+// allocate assigns the items of an order to the warehouses that fulfill
+// them, closest to the customer first, and returns the stock reservations to
+// make. Items split across warehouses are replaced by one item per
+// warehouse. Without warehouses stock is reserved unlocated.
+func (svcOrder *OrderService) allocate(order *model.Order, customer jwt.Customer) ([]model.StockReservation, error) {
+	warehouses, err := svcOrder.repoWarehouse.GetAll()
+	if err != nil {
+		svcOrder.logger.Error("Failed to retrieve warehouses: " + err.Error())
+		return nil, err
 	}
 
-	// Clear the customer's cart after successful checkout
-	err = svcOrder.repoCart.ClearCart(customerCtx.ID)
+	if len(warehouses) == 0 {
+		reservations := make([]model.StockReservation, 0, len(order.Items))
+		for _, item := range order.Items {
+			reservations = append(reservations, model.StockReservation{
+				ProductID: item.ProductID,
+				VariantID: item.VariantID,
+				Quantity:  item.Quantity,
+				Status:    constant.RESERVATION_STATUS_ACTIVE,
+			})
+		}
+		return reservations, nil
+	}
+
+	lines := make([]inventoryModel.FulfillmentLine, 0, len(order.Items))
+	productIDs := make([]uint, 0, len(order.Items))
+	for _, item := range order.Items {
+		lines = append(lines, inventoryModel.FulfillmentLine{
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			Quantity:  item.Quantity,
+		})
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	stock, err := svcOrder.repoInventory.GetWarehouseStock(productIDs)
 	if err != nil {
-		return err
+		svcOrder.logger.Error("Failed to retrieve warehouse stock: " + err.Error())
+		return nil, err
 	}
 
-	svcOrder.logger.Info("Payment status updated successfully")
+	location := inventoryModel.Location{City: customer.City, PostalCode: customer.PostalCode}
+	allocations, err := inventoryModel.Allocate(lines, warehouses, inventoryModel.StockLevels(stock), location)
+	if err != nil {
+		svcOrder.logger.Error("No warehouse can fulfill the order: " + err.Error())
+		return nil, customErrors.ErrProductStockNotAvailable
+	}
+
+	items := make([]model.OrderItem, 0, len(allocations))
+	reservations := make([]model.StockReservation, 0, len(allocations))
+	for _, allocation := range allocations {
+		warehouseID := allocation.WarehouseID
+
+		item := order.Items[allocation.Line]
+		item.WarehouseID = &warehouseID
+		item.Quantity = allocation.Quantity
+		item.Subtotal = float64(allocation.Quantity) * item.ProductPrice
+		items = append(items, item)
+
+		reservations = append(reservations, model.StockReservation{
+			ProductID:   item.ProductID,
+			VariantID:   item.VariantID,
+			WarehouseID: &warehouseID,
+			Quantity:    allocation.Quantity,
+			Status:      constant.RESERVATION_STATUS_ACTIVE,
+		})
+	}
+	order.Items = items
+
+	return reservations, nil
+}
+
+// reserveStock takes the reserved quantities out of available stock at their
+// warehouses. Products are reserved in ID order so concurrent checkouts lock
+// rows in the same order and cannot deadlock.
+func (svcOrder *OrderService) reserveStock(products repoProduct.ProductRepositoryImpl, change inventoryModel.StockChange, reservations []model.StockReservation) error {
+	sorted := make([]model.StockReservation, len(reservations))
+	copy(sorted, reservations)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ProductID < sorted[j].ProductID
+	})
+
+	for _, reservation := range sorted {
+		change.WarehouseID = reservation.WarehouseID
+		err := products.WithStockChange(change).ReserveStock(reservation.ProductID, reservation.VariantID, reservation.Quantity)
+		if err != nil {
+			if errors.Is(err, repoProduct.ErrInsufficientStock) {
+				svcOrder.logger.Error("Product stock not available for product " + fmt.Sprint(reservation.ProductID))
+				return customErrors.ErrProductStockNotAvailable
+			}
+			svcOrder.logger.Error("Failed to reserve stock: " + err.Error())
+			return err
+		}
+	}
 	return nil
 }
 
-// Function to calculate shipping fee based on business logic
-func calculateShippingFee(cartItems []cartModel.CartItem, applyDiscount bool) float64 {
-	// Example: Business logic to calculate shipping fee
-	baseShippingFee := 10000.00
-	if applyDiscount {
-		baseShippingFee -= 2000 // Example discount
+// checkCart returns the warnings for cart items whose price or stock changed
+// since they were added, or that can no longer be bought.
+func (svcOrder *OrderService) checkCart(items []cartModel.CartItem) ([]cartModel.CartWarning, error) {
+	var warnings []cartModel.CartWarning
+	for _, item := range items {
+		product, err := svcOrder.repoProduct.GetByID(item.ProductID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		warnings = append(warnings, item.Check(product)...)
 	}
+	return warnings, nil
+}
 
-	return baseShippingFee
+// priceCart prices the items of a cart at their current catalog prices.
+func (svcOrder *OrderService) priceCart(items []cartModel.CartItem) (pricing.Breakdown, error) {
+	lines := make([]pricing.Line, 0, len(items))
+	for _, item := range items {
+		product, err := svcOrder.repoProduct.GetByID(item.ProductID)
+		if err != nil {
+			return pricing.Breakdown{}, err
+		}
+		line, err := pricing.NewLine(product, item.VariantID, item.Quantity)
+		if err != nil {
+			return pricing.Breakdown{}, err
+		}
+		lines = append(lines, line)
+	}
+	return pricing.Calculate(lines), nil
 }
 
-// Function to apply tax based on business logic
-func applyTax(subtotal float64) float64 {
-	return 0.1 * subtotal // Example: 10% tax
+// coupon returns the promotion a discount code stands for: a store coupon or
+// the code of an abandoned cart reminder, which is returned too so checkout
+// can use it up. Reminder codes stack with automatic promotions.
+func (svcOrder *OrderService) coupon(ctx context.Context, customerID uint, code string) (*promotionModel.Promotion, *cartModel.CartRecovery, error) {
+	code = promotionModel.NormalizeCode(code)
+	if code == "" {
+		return nil, nil, nil
+	}
+
+	coupon, err := svcOrder.promotions.GetCoupon(ctx, customerID, code)
+	if !errors.Is(err, customErrors.ErrNotFound) {
+		return coupon, nil, err
+	}
+
+	recovery, err := svcOrder.recoveryDiscount(customerID, code)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &promotionModel.Promotion{
+		Name:      "Abandoned cart reminder",
+		Code:      recovery.DiscountCode,
+		Type:      constant.PROMOTION_TYPE_PERCENTAGE,
+		Value:     recovery.DiscountRate,
+		Stackable: true,
+		Active:    true,
+	}, recovery, nil
+}
+
+// claimPromotions locks the store promotions taken off an order, in ID
+// order, and checks they may still be used, so concurrent checkouts cannot
+// use a promotion more often than its limits allow.
+func (svcOrder *OrderService) claimPromotions(tx *gorm.DB, customerID uint, applied []model.OrderPromotion) error {
+	ids := make([]uint, 0, len(applied))
+	for _, promotion := range applied {
+		if promotion.PromotionID != nil {
+			ids = append(ids, *promotion.PromotionID)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	promotions := svcOrder.repoPromotion.WithTx(tx)
+	for _, id := range ids {
+		promotion, err := promotions.GetForUpdate(id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return customErrors.ErrPromotionUnavailable
+			}
+			return err
+		}
+
+		available, err := promotionService.Available(promotions, promotion, customerID)
+		if err != nil {
+			svcOrder.logger.Error("Failed to count promotion uses: " + err.Error())
+			return err
+		}
+		if !available || !promotion.Live(time.Now()) {
+			return customErrors.ErrPromotionUnavailable
+		}
+	}
+	return nil
 }
 
-// Function to apply discount based on business logic
-func applyDiscount(subtotal float64) float64 {
-	return 0.05 * subtotal // Example: 5% discount
+// recoveryDiscount returns the abandoned cart reminder a discount code was
+// sent with, when the customer may still use it.
+func (svcOrder *OrderService) recoveryDiscount(customerID uint, code string) (*cartModel.CartRecovery, error) {
+	recovery, err := svcOrder.repoRecovery.GetRecoveryByCode(strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, customErrors.ErrInvalidDiscountCode
+		}
+		svcOrder.logger.Error("Failed to fetch discount code: " + err.Error())
+		return nil, err
+	}
+	if recovery.CustomerID != customerID || !recovery.Redeemable(time.Now()) {
+		return nil, customErrors.ErrInvalidDiscountCode
+	}
+	return recovery, nil
+}
+
+// transition moves an order to status to, saves it and records the change
+// in its status history.
+func (svcOrder *OrderService) transition(orders repoOrder.OrderRepositoryImpl, order *model.Order, to, role, actor, note string) error {
+	history, err := order.Transition(to, role, actor, note, time.Now())
+	if err != nil {
+		svcOrder.logger.Error("Order " + order.OrderNumber + " cannot move from " + order.OrderStatus + " to " + to)
+		return err
+	}
+
+	if err := orders.UpdateOrder(order); err != nil {
+		return err
+	}
+	if err := orders.RecordStatusChange(history); err != nil {
+		svcOrder.logger.Error("Failed to record order status: " + err.Error())
+		return err
+	}
+	return nil
 }
 
 func generateOrderNumber() string {
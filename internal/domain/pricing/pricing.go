@@ -0,0 +1,116 @@
+// Package pricing computes what a customer pays for a set of cart lines. The
+// cart preview and checkout both use it, so the totals a customer sees are
+// the totals they are charged.
+package pricing
+
+import (
+	"go-online-store/internal/domain/product/model"
+	customErrors "go-online-store/pkg/errors"
+)
+
+const (
+	// Currency of every price in the store.
+	Currency = "IDR"
+
+	// BaseShippingFee is charged once per order, less ShippingDiscount.
+	BaseShippingFee  = 10000.00
+	ShippingDiscount = 2000.00
+
+	// TaxRate is applied to the subtotal.
+	TaxRate = 0.1
+)
+
+// Line is one product, or variant, at its current catalog price.
+type Line struct {
+	ProductID  uint    `json:"product_id"`
+	VariantID  *uint   `json:"variant_id"`
+	CategoryID *uint   `json:"category_id,omitempty"`
+	SKU        string  `json:"sku,omitempty"`
+	Name       string  `json:"name"`
+	UnitPrice  float64 `json:"unit_price"`
+	Quantity   uint    `json:"quantity"`
+	Subtotal   float64 `json:"subtotal"`
+}
+
+// Adjustment is a promotion taken off a breakdown. PromotionID is empty for
+// discounts that are not store promotions, e.g. abandoned cart codes.
+type Adjustment struct {
+	PromotionID *uint   `json:"promotion_id,omitempty"`
+	Code        string  `json:"code,omitempty"`
+	Name        string  `json:"name"`
+	Type        string  `json:"type"`
+	Amount      float64 `json:"amount"`
+}
+
+// Breakdown is the priced form of a set of lines.
+type Breakdown struct {
+	Lines       []Line       `json:"lines"`
+	Subtotal    float64      `json:"subtotal"`
+	ShippingFee float64      `json:"shipping_fee"`
+	Tax         float64      `json:"tax"`
+	Discount    float64      `json:"discount"`
+	Total       float64      `json:"total"`
+	Currency    string       `json:"currency"`
+	Promotions  []Adjustment `json:"promotions"`
+}
+
+// NewLine prices quantity units of a product, or of one of its variants.
+// Products that have variants can only be priced through one of their SKUs.
+func NewLine(product *model.Product, variantID *uint, quantity uint) (Line, error) {
+	line := Line{
+		ProductID:  product.ID,
+		VariantID:  variantID,
+		CategoryID: product.CategoryID,
+		Name:       product.Name,
+		UnitPrice:  product.Price,
+		Quantity:   quantity,
+	}
+
+	if variantID == nil {
+		if product.HasVariants() {
+			return Line{}, customErrors.ErrVariantRequired
+		}
+	} else {
+		variant, ok := product.Variant(*variantID)
+		if !ok {
+			return Line{}, customErrors.ErrInvalidVariant
+		}
+		line.SKU = variant.SKU
+		line.UnitPrice = variant.Price
+	}
+
+	line.Subtotal = float64(quantity) * line.UnitPrice
+	return line, nil
+}
+
+// Calculate adds up lines and applies shipping and tax. Promotions are
+// applied afterwards. Nothing is charged for no lines.
+func Calculate(lines []Line) Breakdown {
+	breakdown := Breakdown{
+		Lines:      lines,
+		Currency:   Currency,
+		Promotions: []Adjustment{},
+	}
+	if len(lines) == 0 {
+		breakdown.Lines = []Line{}
+		return breakdown
+	}
+
+	for _, line := range lines {
+		breakdown.Subtotal += line.Subtotal
+	}
+	breakdown.ShippingFee = BaseShippingFee - ShippingDiscount
+	breakdown.Tax = TaxRate * breakdown.Subtotal
+	breakdown.Total = breakdown.Subtotal + breakdown.ShippingFee + breakdown.Tax
+	return breakdown
+}
+
+// Apply takes a promotion off the total. Discounts never take more than the
+// subtotal and shipping fee together; the amount recorded is what was taken.
+func (b *Breakdown) Apply(adjustment Adjustment) {
+	remaining := b.Subtotal + b.ShippingFee - b.Discount
+	adjustment.Amount = max(0, min(adjustment.Amount, remaining))
+	b.Discount += adjustment.Amount
+	b.Total -= adjustment.Amount
+	b.Promotions = append(b.Promotions, adjustment)
+}
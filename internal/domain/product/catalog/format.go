@@ -0,0 +1,245 @@
+package catalog
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+// Columns are the CSV columns of an export, in order. Imports match columns
+// by header name and ignore unknown ones.
+var Columns = []string{"sku", "name", "category", "description", "price", "stok"}
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported catalog format")
+	ErrInvalidFile       = errors.New("invalid catalog file")
+)
+
+// maxLineSize bounds a single JSON Lines record.
+const maxLineSize = 1 << 20
+
+// ParseFormat returns the catalog format named by s, which may be a format
+// name, a file name or a content type.
+func ParseFormat(s string) (string, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if i := strings.IndexByte(s, ';'); i >= 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+
+	switch strings.TrimPrefix(filepath.Ext(s), ".") {
+	case "csv":
+		return FormatCSV, nil
+	case "jsonl", "ndjson":
+		return FormatJSONL, nil
+	}
+
+	switch s {
+	case "csv", "text/csv":
+		return FormatCSV, nil
+	case "jsonl", "ndjson", "application/jsonl", "application/x-ndjson", "application/x-jsonlines":
+		return FormatJSONL, nil
+	}
+	return "", ErrUnsupportedFormat
+}
+
+// ContentType returns the media type of a catalog format.
+func ContentType(format string) string {
+	if format == FormatJSONL {
+		return "application/x-ndjson"
+	}
+	return "text/csv"
+}
+
+// Reader reads the rows of an import file. Next returns io.EOF after the last
+// row and a *RowError for a row that cannot be parsed; reading may continue
+// after a *RowError. Any other error ends the import.
+type Reader interface {
+	Next() (Row, error)
+}
+
+// NewReader returns a Reader for the given format.
+func NewReader(format string, r io.Reader) (Reader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVReader(r)
+	case FormatJSONL:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+		return &jsonlReader{scanner: scanner}, nil
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+// Writer writes the rows of an export file.
+type Writer interface {
+	Write(row Row) error
+	Flush() error
+}
+
+// NewWriter returns a Writer for the given format. CSV output starts with a
+// header row.
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(Columns); err != nil {
+			return nil, err
+		}
+		return &csvWriter{writer: writer}, nil
+	case FormatJSONL:
+		buffered := bufio.NewWriter(w)
+		return &jsonlWriter{buffered: buffered, encoder: json.NewEncoder(buffered)}, nil
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+type csvReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: csv file is empty", ErrInvalidFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFile, err.Error())
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, fmt.Errorf("%w: csv header has no name column", ErrInvalidFile)
+	}
+	return &csvReader{reader: reader, columns: columns}, nil
+}
+
+func (r *csvReader) Next() (Row, error) {
+	record, err := r.reader.Read()
+	if err == io.EOF {
+		return Row{}, io.EOF
+	}
+
+	line, _ := r.reader.FieldPos(0)
+	row := Row{Line: line}
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return Row{Line: parseErr.Line}, &RowError{Line: parseErr.Line, Message: parseErr.Err.Error()}
+		}
+		return row, err
+	}
+
+	field := func(name string) string {
+		i, ok := r.columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	row.SKU = field("sku")
+	row.Name = field("name")
+	row.Category = field("category")
+	row.Description = field("description")
+
+	if raw := field("price"); raw != "" {
+		price, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return row, &RowError{Line: line, SKU: row.SKU, Name: row.Name, Message: "invalid price " + strconv.Quote(raw)}
+		}
+		row.Price = &price
+	}
+	if raw := field("stok"); raw != "" {
+		stock, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			return row, &RowError{Line: line, SKU: row.SKU, Name: row.Name, Message: "invalid stok " + strconv.Quote(raw)}
+		}
+		value := uint(stock)
+		row.Stock = &value
+	}
+
+	return row, nil
+}
+
+type jsonlReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (r *jsonlReader) Next() (Row, error) {
+	for r.scanner.Scan() {
+		r.line++
+		data := strings.TrimSpace(r.scanner.Text())
+		if data == "" {
+			continue
+		}
+
+		var row Row
+		if err := json.Unmarshal([]byte(data), &row); err != nil {
+			return Row{Line: r.line}, &RowError{Line: r.line, Message: "invalid JSON: " + err.Error()}
+		}
+		row.Line = r.line
+		return row, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return Row{}, fmt.Errorf("%w: line %d is too long", ErrInvalidFile, r.line+1)
+		}
+		return Row{}, err
+	}
+	return Row{}, io.EOF
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func (w *csvWriter) Write(row Row) error {
+	record := []string{row.SKU, row.Name, row.Category, row.Description, "", ""}
+	if row.Price != nil {
+		record[4] = strconv.FormatFloat(*row.Price, 'f', -1, 64)
+	}
+	if row.Stock != nil {
+		record[5] = strconv.FormatUint(uint64(*row.Stock), 10)
+	}
+	return w.writer.Write(record)
+}
+
+func (w *csvWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+type jsonlWriter struct {
+	buffered *bufio.Writer
+	encoder  *json.Encoder
+}
+
+func (w *jsonlWriter) Write(row Row) error {
+	return w.encoder.Encode(row)
+}
+
+func (w *jsonlWriter) Flush() error {
+	return w.buffered.Flush()
+}
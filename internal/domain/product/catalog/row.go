@@ -0,0 +1,104 @@
+package catalog
+
+import (
+	"fmt"
+	"go-online-store/internal/domain/product/model"
+	"strings"
+)
+
+// Row is one product of an import or export file. Price and Stock are
+// pointers so an import can tell a missing value from zero; a missing stock
+// leaves the stock of an existing product unchanged.
+type Row struct {
+	Line        int      `json:"-"`
+	SKU         string   `json:"sku"`
+	Name        string   `json:"name"`
+	Category    string   `json:"category"`
+	Description string   `json:"description"`
+	Price       *float64 `json:"price"`
+	Stock       *uint    `json:"stok"`
+}
+
+// RowFromProduct returns the export row of a product.
+func RowFromProduct(product *model.Product) Row {
+	row := Row{
+		Name:        product.Name,
+		Category:    product.Category,
+		Description: product.Description,
+		Price:       &product.Price,
+		Stock:       &product.Stok,
+	}
+	if product.SKU != nil {
+		row.SKU = *product.SKU
+	}
+	return row
+}
+
+// Key identifies the product a row upserts: its SKU, or its name when the
+// row has no SKU.
+func (row Row) Key() string {
+	if row.SKU != "" {
+		return "sku:" + strings.ToUpper(row.SKU)
+	}
+	return "name:" + strings.ToLower(row.Name)
+}
+
+// Normalize trims the text fields of a row.
+func (row *Row) Normalize() {
+	row.SKU = strings.TrimSpace(row.SKU)
+	row.Name = strings.TrimSpace(row.Name)
+	row.Category = strings.TrimSpace(row.Category)
+	row.Description = strings.TrimSpace(row.Description)
+}
+
+// Validate checks the fields of a row that do not need the database.
+func (row Row) Validate() error {
+	switch {
+	case row.Name == "":
+		return fmt.Errorf("name is required")
+	case len(row.SKU) > 64:
+		return fmt.Errorf("sku is longer than 64 characters")
+	case row.Category == "":
+		return fmt.Errorf("category is required")
+	case row.Price == nil:
+		return fmt.Errorf("price is required")
+	case *row.Price < 0:
+		return fmt.Errorf("price must not be negative")
+	}
+	return nil
+}
+
+// RowError reports why one row of an import was rejected.
+type RowError struct {
+	Line    int    `json:"line"`
+	SKU     string `json:"sku,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// ImportReport summarizes an import. In a dry run nothing is written and
+// Created and Updated count what the import would have done.
+type ImportReport struct {
+	Format  string     `json:"format"`
+	DryRun  bool       `json:"dry_run"`
+	Rows    int        `json:"rows"`
+	Created int        `json:"created"`
+	Updated int        `json:"updated"`
+	Failed  int        `json:"failed"`
+	Errors  []RowError `json:"errors"`
+}
+
+// Fail records a rejected row.
+func (report *ImportReport) Fail(row Row, message string) {
+	report.Failed++
+	report.Errors = append(report.Errors, RowError{
+		Line:    row.Line,
+		SKU:     row.SKU,
+		Name:    row.Name,
+		Message: message,
+	})
+}
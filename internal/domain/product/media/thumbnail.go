@@ -0,0 +1,112 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+)
+
+var ErrUnsupportedImage = errors.New("unsupported image format")
+
+// ThumbnailSize is a named bounding box that thumbnails are scaled to fit.
+type ThumbnailSize struct {
+	Name string
+	Max  int
+}
+
+// ThumbnailSizes are generated for every uploaded image.
+var ThumbnailSizes = []ThumbnailSize{
+	{Name: "small", Max: 150},
+	{Name: "medium", Max: 400},
+	{Name: "large", Max: 800},
+}
+
+// SupportedContentTypes are the image types accepted for upload.
+var SupportedContentTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// Decode parses an uploaded JPEG, PNG or GIF image.
+func Decode(data []byte) (image.Image, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	return img, nil
+}
+
+// Thumbnail scales img to fit within a max x max box, keeping its aspect
+// ratio, and encodes it as JPEG. Images already smaller are not enlarged.
+func Thumbnail(img image.Image, max int) ([]byte, error) {
+	bounds := img.Bounds()
+	width, height := fit(bounds.Dx(), bounds.Dy(), max)
+
+	resized := resize(img, width, height)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, resized, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func fit(width, height, max int) (int, int) {
+	if width <= max && height <= max {
+		return width, height
+	}
+	if width >= height {
+		return max, maxInt(1, height*max/width)
+	}
+	return maxInt(1, width*max/height), max
+}
+
+// resize scales src with box sampling: each destination pixel is the
+// average of the source pixels it covers. Transparent areas are flattened
+// onto white since the output is JPEG.
+func resize(src image.Image, width, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	bounds := src.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*srcH/height
+		y1 := bounds.Min.Y + maxInt((y+1)*srcH/height, y*srcH/height+1)
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*srcW/width
+			x1 := bounds.Min.X + maxInt((x+1)*srcW/width, x*srcW/width+1)
+
+			var r, g, b, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					// Composite premultiplied colour over white
+					r += uint64(cr + (0xffff - ca))
+					g += uint64(cg + (0xffff - ca))
+					b += uint64(cb + (0xffff - ca))
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(b / n >> 8),
+				A: 0xff,
+			})
+		}
+	}
+
+	return dst
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
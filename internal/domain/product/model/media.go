@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// ProductMedia is an image attached to a product. Thumbnails maps each
+// thumbnail size name to its URL.
+type ProductMedia struct {
+	ID            uint              `json:"id" gorm:"primaryKey"`
+	ProductID     uint              `json:"product_id" gorm:"column:product_id;not null;index"`
+	URL           string            `json:"url" gorm:"column:url;not null"`
+	AltText       string            `json:"alt_text" gorm:"column:alt_text"`
+	Position      int               `json:"position" gorm:"column:position;not null"`
+	ContentType   string            `json:"content_type" gorm:"column:content_type"`
+	Width         int               `json:"width" gorm:"column:width"`
+	Height        int               `json:"height" gorm:"column:height"`
+	Thumbnails    map[string]string `json:"thumbnails" gorm:"column:thumbnails;type:text;serializer:json"`
+	StorageKey    string            `json:"-" gorm:"column:storage_key;not null"`
+	ThumbnailKeys []string          `json:"-" gorm:"column:thumbnail_keys;type:text;serializer:json"`
+	CreatedAt     time.Time         `json:"created_at"`
+}
+
+// StorageKeys returns every stored object that belongs to the media.
+func (m *ProductMedia) StorageKeys() []string {
+	return append([]string{m.StorageKey}, m.ThumbnailKeys...)
+}
+
+func (ProductMedia) TableName() string {
+	return "ProductMedia"
+}
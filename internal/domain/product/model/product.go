@@ -1,13 +1,35 @@
 package model
 
+import "time"
+
 type Product struct {
-	ID       uint    `json:"id" gorm:"column:id;not null"`
-	Name     string  `json:"name" gorm:"column:name;not null"`
-	Category string  `json:"category" gorm:"column:category;not null"`
-	Price    float64 `json:"price" gorm:"column:price;not null"`
-	Stok     uint    `json:"stok" gorm:"column:stok;not null"`
+	ID          uint    `json:"id" gorm:"column:id;not null"`
+	SKU         *string `json:"sku" gorm:"column:sku;size:64;uniqueIndex"`
+	Name        string  `json:"name" gorm:"column:name;not null"`
+	CategoryID  *uint   `json:"category_id" gorm:"column:category_id;index"`
+	Category    string  `json:"category" gorm:"column:category;not null"`
+	Description string  `json:"description" gorm:"column:description;type:text"`
+	Price       float64 `json:"price" gorm:"column:price;not null"`
+	Stok        uint    `json:"stok" gorm:"column:stok;not null"`
+	// PurchaseLimit caps how many units of the product one customer may have
+	// in the cart; zero means only stock limits it.
+	PurchaseLimit uint `json:"purchase_limit" gorm:"column:purchase_limit;not null;default:0"`
+	// RatingAverage and RatingCount summarize the approved reviews of the
+	// product; they are maintained by the review service.
+	RatingAverage float64          `json:"rating_average" gorm:"column:rating_average;not null;default:0"`
+	RatingCount   uint             `json:"rating_count" gorm:"column:rating_count;not null;default:0"`
+	Options       []ProductOption  `json:"options,omitempty" gorm:"foreignKey:ProductID"`
+	Variants      []ProductVariant `json:"variants,omitempty" gorm:"foreignKey:ProductID"`
+	Media         []ProductMedia   `json:"media" gorm:"foreignKey:ProductID"`
+	CreatedAt     time.Time        `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt     time.Time        `json:"updated_at" gorm:"column:updated_at"`
 }
 
 func (Product) TableName() string {
 	return "Product"
 }
+
+// WithinPurchaseLimit reports whether quantity units may be bought at once.
+func (p *Product) WithinPurchaseLimit(quantity uint) bool {
+	return p.PurchaseLimit == 0 || quantity <= p.PurchaseLimit
+}
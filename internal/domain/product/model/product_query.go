@@ -0,0 +1,44 @@
+package model
+
+import "go-online-store/pkg/pagination"
+
+// Supported values for ProductQuery.Sort. A leading "-" sorts descending.
+const (
+	SortPriceAsc  = "price"
+	SortPriceDesc = "-price"
+	SortNameAsc   = "name"
+	SortNameDesc  = "-name"
+	SortNewest    = "newest"
+)
+
+// ProductQuery describes a filtered, sorted and paginated product listing.
+type ProductQuery struct {
+	// CategoryID selects a category and all of its descendants. Category is
+	// a slug or legacy free-text category. The service resolves both into
+	// CategoryIDs, which is what the repository filters on.
+	CategoryID  uint
+	Category    string
+	CategoryIDs []uint
+	MinPrice    *float64
+	MaxPrice    *float64
+	InStock     bool
+	Name        string
+	Sort        string
+	Pagination  pagination.Params
+}
+
+// ValidSort reports whether s is one of the supported sort values.
+func ValidSort(s string) bool {
+	switch s {
+	case SortPriceAsc, SortPriceDesc, SortNameAsc, SortNameDesc, SortNewest:
+		return true
+	}
+	return false
+}
+
+// ProductPage is one page of a product listing.
+type ProductPage struct {
+	Products   []*Product
+	Total      int64
+	NextCursor string
+}
@@ -0,0 +1,74 @@
+package model
+
+import "time"
+
+// ProductOption is a variant axis of a product, such as size or color,
+// with the values it can take.
+type ProductOption struct {
+	ID        uint     `json:"id" gorm:"primaryKey"`
+	ProductID uint     `json:"product_id" gorm:"column:product_id;not null;index"`
+	Name      string   `json:"name" gorm:"column:name;not null"`
+	Values    []string `json:"values" gorm:"column:option_values;type:text;serializer:json"`
+	Position  int      `json:"position" gorm:"column:position"`
+}
+
+// ProductVariant is a purchasable SKU of a product. Options maps each option
+// axis name to the value this variant has on that axis.
+type ProductVariant struct {
+	ID        uint              `json:"id" gorm:"primaryKey"`
+	ProductID uint              `json:"product_id" gorm:"column:product_id;not null;index"`
+	SKU       string            `json:"sku" gorm:"column:sku;size:64;not null;uniqueIndex"`
+	Barcode   string            `json:"barcode" gorm:"column:barcode;size:64"`
+	Options   map[string]string `json:"options" gorm:"column:options;type:text;serializer:json"`
+	Price     float64           `json:"price" gorm:"column:price;not null"`
+	Stok      uint              `json:"stok" gorm:"column:stok;not null"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// HasVariants reports whether the product is sold through its SKUs only.
+func (p *Product) HasVariants() bool {
+	return len(p.Variants) > 0
+}
+
+// Variant returns the variant of the product with the given ID.
+func (p *Product) Variant(variantID uint) (*ProductVariant, bool) {
+	for i := range p.Variants {
+		if p.Variants[i].ID == variantID {
+			return &p.Variants[i], true
+		}
+	}
+	return nil, false
+}
+
+// MatchesOptions reports whether every option of the variant is a declared
+// value of the given axes, and every axis is set.
+func (v *ProductVariant) MatchesOptions(options []ProductOption) bool {
+	if len(v.Options) != len(options) {
+		return false
+	}
+	for _, option := range options {
+		value, ok := v.Options[option.Name]
+		if !ok || !contains(option.Values, value) {
+			return false
+		}
+	}
+	return true
+}
+
+func (ProductOption) TableName() string {
+	return "ProductOption"
+}
+
+func (ProductVariant) TableName() string {
+	return "ProductVariant"
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
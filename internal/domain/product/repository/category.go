@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"go-online-store/internal/domain/product/model"
+
+	"gorm.io/gorm"
+)
+
+func (repo *ProductRepository) GetProductsByCategoryIDs(categoryIDs []uint) ([]*model.Product, error) {
+	var products []*model.Product
+	result := repo.db.Preload("Media", orderByPosition).Where("category_id IN ?", categoryIDs).Find(&products)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return products, nil
+}
+
+func (repo *ProductRepository) CountByCategoryIDs(categoryIDs []uint) (int64, error) {
+	var count int64
+	err := repo.db.Model(&model.Product{}).Where("category_id IN ?", categoryIDs).Count(&count).Error
+	return count, err
+}
+
+// LegacyCategories returns the distinct free-text categories of products
+// that are not linked to a category entity yet.
+func (repo *ProductRepository) LegacyCategories() ([]string, error) {
+	var categories []string
+	err := repo.db.Model(&model.Product{}).
+		Where("category_id IS NULL AND category <> ''").
+		Distinct().
+		Pluck("category", &categories).Error
+	return categories, err
+}
+
+// AssignLegacyCategory links every unlinked product with the given
+// free-text category to a category entity.
+func (repo *ProductRepository) AssignLegacyCategory(legacy string, categoryID uint, name string) error {
+	return repo.updateCategoryWhere(
+		repo.db.Where("category_id IS NULL AND category = ?", legacy),
+		map[string]interface{}{"category_id": categoryID, "category": name},
+	)
+}
+
+// ReassignCategory moves every product of one category to another.
+func (repo *ProductRepository) ReassignCategory(fromID uint, toID uint, name string) error {
+	return repo.updateCategoryWhere(
+		repo.db.Where("category_id = ?", fromID),
+		map[string]interface{}{"category_id": toID, "category": name},
+	)
+}
+
+// RenameCategory refreshes the denormalized category name of its products.
+func (repo *ProductRepository) RenameCategory(categoryID uint, name string) error {
+	return repo.updateCategoryWhere(
+		repo.db.Where("category_id = ?", categoryID),
+		map[string]interface{}{"category": name},
+	)
+}
+
+// updateCategoryWhere applies a bulk update and notifies observers of every
+// product it touched.
+func (repo *ProductRepository) updateCategoryWhere(scope *gorm.DB, values map[string]interface{}) error {
+	var ids []uint
+	if err := scope.Session(&gorm.Session{}).Model(&model.Product{}).Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err := repo.db.Model(&model.Product{}).Where("id IN ?", ids).Updates(values).Error; err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		repo.notifyReloaded(id)
+	}
+	return nil
+}
@@ -1,23 +1,72 @@
 package repository
 
 import (
+	"fmt"
 	mysql "go-online-store/config/database/my_sql_db"
+	inventoryModel "go-online-store/internal/domain/inventory/model"
+	inventoryRepo "go-online-store/internal/domain/inventory/repository"
 	"go-online-store/internal/domain/product/model"
+	"go-online-store/pkg/constant"
+	"go-online-store/pkg/pagination"
+	"strconv"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ProductRepository struct {
 	db *gorm.DB
+	// pool is the connection outside any transaction, used to reload
+	// products for observers once a transaction has committed.
+	pool *gorm.DB
+	// ledger records every stock write; change attributes those records.
+	ledger inventoryRepo.InventoryRepositoryImpl
+	change inventoryModel.StockChange
 }
 
 type ProductRepositoryImpl interface {
 	Create(product *model.Product) error
+	Update(product *model.Product) error
 	UpdateStock(productID uint, newStock uint) error
 	Delete(id uint) error
 	GetByID(id uint) (*model.Product, error)
 	GetProductsByCategory(category string) ([]*model.Product, error)
 	GetAll() ([]*model.Product, error)
+	GetAllInBatches(batchSize int, fn func(products []*model.Product) error) error
+	GetBySKU(sku string) (*model.Product, error)
+	GetByName(name string) ([]*model.Product, error)
+	List(query model.ProductQuery) (*model.ProductPage, error)
+	ReplaceOptions(productID uint, options []model.ProductOption) error
+	GetVariantByID(id uint) (*model.ProductVariant, error)
+	GetVariantBySKU(sku string) (*model.ProductVariant, error)
+	GetVariantsByProductID(productID uint) ([]model.ProductVariant, error)
+	CreateVariant(variant *model.ProductVariant) error
+	UpdateVariant(variant *model.ProductVariant) error
+	DeleteVariant(variant *model.ProductVariant) error
+	UpdateVariantStock(variantID uint, newStock uint) error
+	AdjustStock(productID uint, variantID *uint, delta int, reason string) error
+	ReserveStock(productID uint, variantID *uint, quantity uint) error
+	ReleaseStock(productID uint, variantID *uint, quantity uint) error
+	RecordSale(productID uint, variantID *uint, quantity uint) error
+	TransferStock(transfer inventoryModel.StockTransfer) error
+	GetMediaByID(id uint) (*model.ProductMedia, error)
+	GetMediaByProductID(productID uint) ([]model.ProductMedia, error)
+	CreateMedia(media *model.ProductMedia) error
+	UpdateMedia(media *model.ProductMedia) error
+	DeleteMedia(media *model.ProductMedia) error
+	ReorderMedia(productID uint, mediaIDs []uint) error
+	GetProductsByCategoryIDs(categoryIDs []uint) ([]*model.Product, error)
+	CountByCategoryIDs(categoryIDs []uint) (int64, error)
+	LegacyCategories() ([]string, error)
+	AssignLegacyCategory(legacy string, categoryID uint, name string) error
+	ReassignCategory(fromID uint, toID uint, name string) error
+	RenameCategory(categoryID uint, name string) error
+	GetAllVariants() ([]model.ProductVariant, error)
+	UpdateRating(productID uint, average float64, count uint) error
+	WithTx(tx *gorm.DB) ProductRepositoryImpl
+	WithStockChange(change inventoryModel.StockChange) ProductRepositoryImpl
 }
 
 func NewProductRepository() (ProductRepositoryImpl, error) {
@@ -26,33 +75,140 @@ func NewProductRepository() (ProductRepositoryImpl, error) {
 		return nil, err
 	}
 
-	db.AutoMigrate(&model.Product{})
-	return &ProductRepository{db}, nil
+	ledger, err := inventoryRepo.NewInventoryRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	db.AutoMigrate(&model.Product{}, &model.ProductOption{}, &model.ProductVariant{}, &model.ProductMedia{})
+	return &ProductRepository{db: db, pool: db, ledger: ledger}, nil
+}
+
+// WithTx returns a repository that runs its statements in tx. Observers are
+// only notified once tx commits.
+func (repo *ProductRepository) WithTx(tx *gorm.DB) ProductRepositoryImpl {
+	clone := *repo
+	clone.db = tx
+	return &clone
 }
 
+// Create inserts a product and records its initial stock in the ledger.
 func (repo *ProductRepository) Create(product *model.Product) error {
-	result := repo.db.Create(product)
-	return result.Error
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(product).Error; err != nil {
+			return err
+		}
+		return repo.record(tx, repo.change.Movement(product.ID, nil, int(product.Stok), constant.STOCK_REASON_OPENING))
+	})
+	if err != nil {
+		return err
+	}
+	repo.notifyChanged(product)
+	return nil
 }
 
+// Update saves a product and records any change of its stock in the ledger.
+// The stock of a product with variants is the sum of its SKUs and is kept.
+func (repo *ProductRepository) Update(product *model.Product) error {
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		current, err := lockProductStock(tx, product.ID)
+		if err != nil {
+			return err
+		}
+
+		var variants int64
+		if err := tx.Model(&model.ProductVariant{}).Where("product_id = ?", product.ID).Count(&variants).Error; err != nil {
+			return err
+		}
+		if variants > 0 {
+			product.Stok = current.Stok
+		}
+
+		if err := tx.Omit(clause.Associations).Save(product).Error; err != nil {
+			return err
+		}
+
+		delta := int(product.Stok) - int(current.Stok)
+		return repo.record(tx, repo.change.Movement(product.ID, nil, delta, constant.STOCK_REASON_ADJUSTMENT))
+	})
+	if err != nil {
+		return err
+	}
+	repo.notifyChanged(product)
+	return nil
+}
+
+// UpdateStock sets the stock of a product and records the difference in the
+// ledger as an adjustment.
 func (repo *ProductRepository) UpdateStock(productID uint, newStock uint) error {
-	result := repo.db.Model(&model.Product{}).
-		Where("id = ?", productID).
-		Update("stok", newStock)
-	if result.Error != nil {
-		return result.Error
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		current, err := lockProductStock(tx, productID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Product{}).Where("id = ?", productID).Update("stok", newStock).Error; err != nil {
+			return err
+		}
+
+		delta := int(newStock) - int(current.Stok)
+		return repo.record(tx, repo.change.Movement(productID, nil, delta, constant.STOCK_REASON_ADJUSTMENT))
+	})
+	if err != nil {
+		return err
+	}
+	repo.notifyReloaded(productID)
+	return nil
+}
+
+// UpdateRating stores the review summary of a product. It is not a catalog
+// edit, so updated_at is left alone.
+func (repo *ProductRepository) UpdateRating(productID uint, average float64, count uint) error {
+	err := repo.db.Model(&model.Product{}).Where("id = ?", productID).UpdateColumns(map[string]interface{}{
+		"rating_average": average,
+		"rating_count":   count,
+	}).Error
+	if err != nil {
+		return err
 	}
+	repo.notifyReloaded(productID)
 	return nil
 }
 
+// Delete removes a product with its options, variants and media. The stored
+// files of the media are removed once the delete is committed.
 func (repo *ProductRepository) Delete(id uint) error {
-	result := repo.db.Delete(&model.Product{}, id)
-	return result.Error
+	var media []model.ProductMedia
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("product_id = ?", id).Delete(&model.ProductVariant{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("product_id = ?", id).Delete(&model.ProductOption{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("product_id = ?", id).Find(&media).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("product_id = ?", id).Delete(&model.ProductMedia{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Product{}, id).Error
+	})
+	if err != nil {
+		return err
+	}
+	mysql.AfterCommit(repo.db, func() {
+		notifyMediaDeleted(media)
+		notifyDeleted(id)
+	})
+	return nil
 }
 
 func (repo *ProductRepository) GetByID(id uint) (*model.Product, error) {
 	var product model.Product
-	result := repo.db.First(&product, id)
+	result := repo.db.Preload("Options", orderByPosition).
+		Preload("Variants").
+		Preload("Media", orderByPosition).
+		First(&product, id)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -61,18 +217,191 @@ func (repo *ProductRepository) GetByID(id uint) (*model.Product, error) {
 
 func (repo *ProductRepository) GetAll() ([]*model.Product, error) {
 	var products []*model.Product
-	result := repo.db.Find(&products)
+	result := repo.db.Preload("Media", orderByPosition).Find(&products)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return products, nil
 }
 
+// GetAllInBatches walks the catalog in ID order, batchSize products at a
+// time, so callers can stream it without loading every product at once.
+func (repo *ProductRepository) GetAllInBatches(batchSize int, fn func(products []*model.Product) error) error {
+	var products []*model.Product
+	return repo.db.Order("id").FindInBatches(&products, batchSize, func(tx *gorm.DB, batch int) error {
+		return fn(products)
+	}).Error
+}
+
+func (repo *ProductRepository) GetBySKU(sku string) (*model.Product, error) {
+	var product model.Product
+	if err := repo.db.Where("sku = ?", sku).First(&product).Error; err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// GetByName returns every product with the given name; names are not unique.
+func (repo *ProductRepository) GetByName(name string) ([]*model.Product, error) {
+	var products []*model.Product
+	if err := repo.db.Where("name = ?", name).Order("id").Find(&products).Error; err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
 func (repo *ProductRepository) GetProductsByCategory(category string) ([]*model.Product, error) {
 	var products []*model.Product
-	result := repo.db.Where("category = ?", category).Find(&products)
+	result := repo.db.Preload("Media", orderByPosition).Where("category = ?", category).Find(&products)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return products, nil
 }
+
+func (repo *ProductRepository) notifyChanged(product *model.Product) {
+	mysql.AfterCommit(repo.db, func() {
+		notifyChanged(product)
+	})
+}
+
+// notifyReloaded re-reads a product after a partial update so observers see the full row.
+func (repo *ProductRepository) notifyReloaded(productID uint) {
+	if !hasObservers() {
+		return
+	}
+	mysql.AfterCommit(repo.db, func() {
+		committed := &ProductRepository{db: repo.pool, pool: repo.pool, ledger: repo.ledger}
+		product, err := committed.GetByID(productID)
+		if err != nil {
+			return
+		}
+		notifyChanged(product)
+	})
+}
+
+// List returns one page of products matching the query along with the total
+// number of matching rows.
+func (repo *ProductRepository) List(query model.ProductQuery) (*model.ProductPage, error) {
+	var total int64
+	if err := applyProductFilters(repo.db.Model(&model.Product{}), query).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	column, desc := productSortColumn(query.Sort)
+	direction := "ASC"
+	if desc {
+		direction = "DESC"
+	}
+
+	db := applyProductFilters(repo.db.Preload("Media", orderByPosition), query).
+		Order(fmt.Sprintf("%s %s, id %s", column, direction, direction))
+
+	params := query.Pagination
+	if params.UseCursor {
+		if params.Cursor != "" {
+			cursor, err := pagination.DecodeCursor(params.Cursor)
+			if err != nil || cursor.Sort != query.Sort {
+				return nil, pagination.ErrInvalidCursor
+			}
+
+			value, err := productCursorValue(query.Sort, cursor.Value)
+			if err != nil {
+				return nil, pagination.ErrInvalidCursor
+			}
+
+			op := ">"
+			if desc {
+				op = "<"
+			}
+			db = db.Where(fmt.Sprintf("(%s %s ? OR (%s = ? AND id %s ?))", column, op, column, op), value, value, cursor.ID)
+		}
+		// Fetch one extra row to know whether another page exists
+		db = db.Limit(params.Limit + 1)
+	} else {
+		db = db.Offset(params.Offset()).Limit(params.Limit)
+	}
+
+	var products []*model.Product
+	if err := db.Find(&products).Error; err != nil {
+		return nil, err
+	}
+
+	page := &model.ProductPage{Total: total}
+	if params.UseCursor && len(products) > params.Limit {
+		products = products[:params.Limit]
+		last := products[len(products)-1]
+		page.NextCursor = pagination.Cursor{
+			Sort:  query.Sort,
+			Value: productCursorKey(query.Sort, last),
+			ID:    last.ID,
+		}.Encode()
+	}
+	page.Products = products
+
+	return page, nil
+}
+
+func applyProductFilters(db *gorm.DB, query model.ProductQuery) *gorm.DB {
+	if len(query.CategoryIDs) > 0 {
+		db = db.Where("category_id IN ?", query.CategoryIDs)
+	} else if query.Category != "" {
+		db = db.Where("category = ?", query.Category)
+	}
+	if query.MinPrice != nil {
+		db = db.Where("price >= ?", *query.MinPrice)
+	}
+	if query.MaxPrice != nil {
+		db = db.Where("price <= ?", *query.MaxPrice)
+	}
+	if query.InStock {
+		db = db.Where("stok > 0")
+	}
+	if query.Name != "" {
+		db = db.Where("name LIKE ?", "%"+escapeLike(query.Name)+"%")
+	}
+	return db
+}
+
+func productSortColumn(sort string) (column string, desc bool) {
+	switch sort {
+	case model.SortPriceAsc:
+		return "price", false
+	case model.SortPriceDesc:
+		return "price", true
+	case model.SortNameDesc:
+		return "name", true
+	case model.SortNewest:
+		return "created_at", true
+	default:
+		return "name", false
+	}
+}
+
+// productCursorKey returns the sort key of a product as stored in a cursor.
+func productCursorKey(sort string, product *model.Product) string {
+	switch sort {
+	case model.SortPriceAsc, model.SortPriceDesc:
+		return strconv.FormatFloat(product.Price, 'f', -1, 64)
+	case model.SortNewest:
+		return product.CreatedAt.Format(time.RFC3339Nano)
+	default:
+		return product.Name
+	}
+}
+
+// productCursorValue converts a cursor sort key back to a query argument.
+func productCursorValue(sort string, key string) (interface{}, error) {
+	switch sort {
+	case model.SortPriceAsc, model.SortPriceDesc:
+		return strconv.ParseFloat(key, 64)
+	case model.SortNewest:
+		return time.Parse(time.RFC3339Nano, key)
+	default:
+		return key, nil
+	}
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"go-online-store/internal/domain/product/model"
+
+	"gorm.io/gorm"
+)
+
+func orderByPosition(db *gorm.DB) *gorm.DB {
+	return db.Order("position, id")
+}
+
+func (repo *ProductRepository) GetMediaByID(id uint) (*model.ProductMedia, error) {
+	var media model.ProductMedia
+	if err := repo.db.First(&media, id).Error; err != nil {
+		return nil, err
+	}
+	return &media, nil
+}
+
+func (repo *ProductRepository) GetMediaByProductID(productID uint) ([]model.ProductMedia, error) {
+	var media []model.ProductMedia
+	if err := orderByPosition(repo.db.Where("product_id = ?", productID)).Find(&media).Error; err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+func (repo *ProductRepository) CreateMedia(media *model.ProductMedia) error {
+	if err := repo.db.Create(media).Error; err != nil {
+		return err
+	}
+	repo.notifyReloaded(media.ProductID)
+	return nil
+}
+
+func (repo *ProductRepository) UpdateMedia(media *model.ProductMedia) error {
+	if err := repo.db.Save(media).Error; err != nil {
+		return err
+	}
+	repo.notifyReloaded(media.ProductID)
+	return nil
+}
+
+func (repo *ProductRepository) DeleteMedia(media *model.ProductMedia) error {
+	if err := repo.db.Delete(&model.ProductMedia{}, media.ID).Error; err != nil {
+		return err
+	}
+	repo.notifyReloaded(media.ProductID)
+	return nil
+}
+
+// ReorderMedia sets the position of each media to its index in mediaIDs.
+func (repo *ProductRepository) ReorderMedia(productID uint, mediaIDs []uint) error {
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		for position, id := range mediaIDs {
+			if err := tx.Model(&model.ProductMedia{}).
+				Where("id = ? AND product_id = ?", id, productID).
+				Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	repo.notifyReloaded(productID)
+	return nil
+}
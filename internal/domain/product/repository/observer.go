@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"go-online-store/internal/domain/product/model"
+	"sync"
+)
+
+// ProductObserver is notified after a product row has been written or deleted,
+// whichever repository instance performed the write.
+type ProductObserver interface {
+	ProductChanged(product *model.Product)
+	ProductDeleted(productID uint)
+}
+
+// MediaObserver is an optional interface of a ProductObserver that is
+// notified of the media deleted along with their product, so it can remove
+// their stored files.
+type MediaObserver interface {
+	MediaDeleted(media []model.ProductMedia)
+}
+
+var (
+	observersMu sync.RWMutex
+	observers   []ProductObserver
+)
+
+// RegisterObserver subscribes o to product writes for the life of the process.
+func RegisterObserver(o ProductObserver) {
+	observersMu.Lock()
+	defer observersMu.Unlock()
+
+	observers = append(observers, o)
+}
+
+func hasObservers() bool {
+	observersMu.RLock()
+	defer observersMu.RUnlock()
+
+	return len(observers) > 0
+}
+
+func notifyChanged(product *model.Product) {
+	observersMu.RLock()
+	defer observersMu.RUnlock()
+
+	for _, o := range observers {
+		o.ProductChanged(product)
+	}
+}
+
+func notifyDeleted(productID uint) {
+	observersMu.RLock()
+	defer observersMu.RUnlock()
+
+	for _, o := range observers {
+		o.ProductDeleted(productID)
+	}
+}
+
+func notifyMediaDeleted(media []model.ProductMedia) {
+	if len(media) == 0 {
+		return
+	}
+
+	observersMu.RLock()
+	defer observersMu.RUnlock()
+
+	for _, o := range observers {
+		if mo, ok := o.(MediaObserver); ok {
+			mo.MediaDeleted(media)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"errors"
+	inventoryModel "go-online-store/internal/domain/inventory/model"
+	"go-online-store/internal/domain/product/model"
+	"go-online-store/pkg/constant"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInsufficientStock = errors.New("insufficient stock")
+
+// WithStockChange returns a repository that attributes the ledger movements
+// of its stock writes to change.
+func (repo *ProductRepository) WithStockChange(change inventoryModel.StockChange) ProductRepositoryImpl {
+	clone := *repo
+	clone.change = change
+	return &clone
+}
+
+// AdjustStock adds delta units to the stock of a product, or of one of its
+// variants, and records the movement with the given reason. The rows are
+// locked with SELECT ... FOR UPDATE until the surrounding transaction ends,
+// so concurrent writes to the same product are serialized. It fails with
+// ErrInsufficientStock instead of letting stock go negative.
+func (repo *ProductRepository) AdjustStock(productID uint, variantID *uint, delta int, reason string) error {
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		product, err := lockProductStock(tx, productID)
+		if err != nil {
+			return err
+		}
+		if int(product.Stok)+delta < 0 {
+			return ErrInsufficientStock
+		}
+
+		if variantID != nil {
+			var variant model.ProductVariant
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Select("id", "stok").
+				Where("id = ? AND product_id = ?", *variantID, productID).
+				First(&variant).Error; err != nil {
+				return err
+			}
+			if int(variant.Stok)+delta < 0 {
+				return ErrInsufficientStock
+			}
+
+			if err := tx.Model(&model.ProductVariant{}).
+				Where("id = ?", variant.ID).
+				Update("stok", stockExpr(delta)).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&model.Product{}).
+			Where("id = ?", productID).
+			Update("stok", stockExpr(delta)).Error; err != nil {
+			return err
+		}
+		return repo.record(tx, repo.change.Movement(productID, variantID, delta, reason))
+	})
+	if err != nil {
+		return err
+	}
+
+	repo.notifyReloaded(productID)
+	return nil
+}
+
+// ReserveStock takes quantity units out of the available stock of a product,
+// or of one of its variants, for an unpaid order.
+func (repo *ProductRepository) ReserveStock(productID uint, variantID *uint, quantity uint) error {
+	return repo.AdjustStock(productID, variantID, -int(quantity), constant.STOCK_REASON_RESERVATION)
+}
+
+// ReleaseStock returns previously reserved units to the available stock.
+func (repo *ProductRepository) ReleaseStock(productID uint, variantID *uint, quantity uint) error {
+	return repo.AdjustStock(productID, variantID, int(quantity), constant.STOCK_REASON_RELEASE)
+}
+
+// RecordSale turns reserved units into a sale. The units already left the
+// available stock when they were reserved, so stock does not change; the
+// ledger records the reservation being released and the sale in its place.
+func (repo *ProductRepository) RecordSale(productID uint, variantID *uint, quantity uint) error {
+	return repo.record(repo.db,
+		repo.change.Movement(productID, variantID, int(quantity), constant.STOCK_REASON_RELEASE),
+		repo.change.Movement(productID, variantID, -int(quantity), constant.STOCK_REASON_SALE),
+	)
+}
+
+// syncProductStock keeps Product.stok equal to the sum of its SKU stock after
+// a variant write that changed variant stock by variantDelta. product must be
+// locked and hold the stock from before the write. Variant movements already
+// count toward the product, so only the remainder is recorded at product level.
+func (repo *ProductRepository) syncProductStock(tx *gorm.DB, product *model.Product, variantDelta int) error {
+	var count int64
+	if err := tx.Model(&model.ProductVariant{}).Where("product_id = ?", product.ID).Count(&count).Error; err != nil {
+		return err
+	}
+
+	total := product.Stok
+	if count > 0 {
+		if err := tx.Model(&model.ProductVariant{}).
+			Select("COALESCE(SUM(stok), 0)").
+			Where("product_id = ?", product.ID).
+			Scan(&total).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Product{}).Where("id = ?", product.ID).Update("stok", total).Error; err != nil {
+			return err
+		}
+	}
+
+	rest := int(total) - int(product.Stok) - variantDelta
+	return repo.record(tx, repo.change.Movement(product.ID, nil, rest, constant.STOCK_REASON_ADJUSTMENT))
+}
+
+// TransferStock moves stock of a product or variant from one warehouse to
+// another. Total stock does not change.
+func (repo *ProductRepository) TransferStock(transfer inventoryModel.StockTransfer) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		// Serialize with other stock writes of the product
+		if _, err := lockProductStock(tx, transfer.ProductID); err != nil {
+			return err
+		}
+
+		change := repo.change
+		change.Reference = transfer.Reference
+		change.Note = transfer.Note
+
+		change.WarehouseID = &transfer.FromWarehouseID
+		out := change.Movement(transfer.ProductID, transfer.VariantID, -int(transfer.Quantity), constant.STOCK_REASON_TRANSFER)
+		change.WarehouseID = &transfer.ToWarehouseID
+		in := change.Movement(transfer.ProductID, transfer.VariantID, int(transfer.Quantity), constant.STOCK_REASON_TRANSFER)
+
+		return repo.record(tx, out, in)
+	})
+}
+
+// record appends movements to the ledger in tx, skipping empty ones. A
+// removal at a warehouse fails with ErrInsufficientStock when the warehouse
+// holds too little; a removal without a warehouse is taken from the
+// warehouses that hold stock, the default one first.
+func (repo *ProductRepository) record(tx *gorm.DB, movements ...inventoryModel.StockMovement) error {
+	ledger := repo.ledger.WithTx(tx)
+	// Stock levels as changed by the movements of this batch so far
+	levels := make(map[inventoryModel.StockKey]int)
+	loaded := make(map[inventoryModel.StockKey]bool)
+	load := func(productID uint, variantID *uint) ([]inventoryModel.WarehouseStock, error) {
+		stock, err := ledger.StockLevels(productID, variantID)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range stock {
+			key := inventoryModel.NewStockKey(s.WarehouseID, s.ProductID, s.VariantID)
+			if !loaded[key] {
+				loaded[key] = true
+				levels[key] += s.Quantity
+			}
+		}
+		return stock, nil
+	}
+
+	entries := make([]inventoryModel.StockMovement, 0, len(movements))
+	for _, movement := range movements {
+		if movement.Quantity == 0 {
+			continue
+		}
+
+		if movement.Quantity > 0 {
+			if movement.WarehouseID != nil {
+				levels[inventoryModel.NewStockKey(*movement.WarehouseID, movement.ProductID, movement.VariantID)] += movement.Quantity
+			}
+			entries = append(entries, movement)
+			continue
+		}
+
+		stock, err := load(movement.ProductID, movement.VariantID)
+		if err != nil {
+			return err
+		}
+
+		if movement.WarehouseID != nil {
+			key := inventoryModel.NewStockKey(*movement.WarehouseID, movement.ProductID, movement.VariantID)
+			if levels[key]+movement.Quantity < 0 {
+				return ErrInsufficientStock
+			}
+			levels[key] += movement.Quantity
+			entries = append(entries, movement)
+			continue
+		}
+
+		remaining := -movement.Quantity
+		for _, s := range stock {
+			key := inventoryModel.NewStockKey(s.WarehouseID, s.ProductID, s.VariantID)
+			take := min(levels[key], remaining)
+			if take <= 0 {
+				continue
+			}
+			part := movement
+			part.WarehouseID = &s.WarehouseID
+			part.Quantity = -take
+			levels[key] -= take
+			remaining -= take
+			entries = append(entries, part)
+			if remaining == 0 {
+				break
+			}
+		}
+		// Stock the warehouses do not account for is taken from the default
+		// one and shows up in reconciliation
+		if remaining > 0 {
+			part := movement
+			part.Quantity = -remaining
+			entries = append(entries, part)
+		}
+	}
+	return ledger.Record(entries...)
+}
+
+// lockProductStock reads the stock of a product and locks its row.
+func lockProductStock(tx *gorm.DB, productID uint) (*model.Product, error) {
+	var product model.Product
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "stok").
+		First(&product, productID).Error; err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+func lockVariantStock(tx *gorm.DB, variantID uint) (*model.ProductVariant, error) {
+	var variant model.ProductVariant
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "product_id", "stok").
+		First(&variant, variantID).Error; err != nil {
+		return nil, err
+	}
+	return &variant, nil
+}
+
+func stockExpr(delta int) clause.Expr {
+	if delta < 0 {
+		return gorm.Expr("stok - ?", -delta)
+	}
+	return gorm.Expr("stok + ?", delta)
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"go-online-store/internal/domain/product/model"
+	"go-online-store/pkg/constant"
+
+	"gorm.io/gorm"
+)
+
+// ReplaceOptions replaces every option axis of a product.
+func (repo *ProductRepository) ReplaceOptions(productID uint, options []model.ProductOption) error {
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("product_id = ?", productID).Delete(&model.ProductOption{}).Error; err != nil {
+			return err
+		}
+		if len(options) == 0 {
+			return nil
+		}
+		for i := range options {
+			options[i].ID = 0
+			options[i].ProductID = productID
+		}
+		return tx.Create(&options).Error
+	})
+	if err != nil {
+		return err
+	}
+	repo.notifyReloaded(productID)
+	return nil
+}
+
+func (repo *ProductRepository) GetVariantByID(id uint) (*model.ProductVariant, error) {
+	var variant model.ProductVariant
+	if err := repo.db.First(&variant, id).Error; err != nil {
+		return nil, err
+	}
+	return &variant, nil
+}
+
+func (repo *ProductRepository) GetVariantBySKU(sku string) (*model.ProductVariant, error) {
+	var variant model.ProductVariant
+	if err := repo.db.Where("sku = ?", sku).First(&variant).Error; err != nil {
+		return nil, err
+	}
+	return &variant, nil
+}
+
+func (repo *ProductRepository) GetAllVariants() ([]model.ProductVariant, error) {
+	var variants []model.ProductVariant
+	if err := repo.db.Order("id").Find(&variants).Error; err != nil {
+		return nil, err
+	}
+	return variants, nil
+}
+
+func (repo *ProductRepository) GetVariantsByProductID(productID uint) ([]model.ProductVariant, error) {
+	var variants []model.ProductVariant
+	if err := repo.db.Where("product_id = ?", productID).Order("id").Find(&variants).Error; err != nil {
+		return nil, err
+	}
+	return variants, nil
+}
+
+func (repo *ProductRepository) CreateVariant(variant *model.ProductVariant) error {
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		product, err := lockProductStock(tx, variant.ProductID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(variant).Error; err != nil {
+			return err
+		}
+
+		opening := repo.change.Movement(variant.ProductID, &variant.ID, int(variant.Stok), constant.STOCK_REASON_OPENING)
+		if err := repo.record(tx, opening); err != nil {
+			return err
+		}
+		return repo.syncProductStock(tx, product, int(variant.Stok))
+	})
+	if err != nil {
+		return err
+	}
+
+	repo.notifyReloaded(variant.ProductID)
+	return nil
+}
+
+func (repo *ProductRepository) UpdateVariant(variant *model.ProductVariant) error {
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		product, err := lockProductStock(tx, variant.ProductID)
+		if err != nil {
+			return err
+		}
+		current, err := lockVariantStock(tx, variant.ID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Save(variant).Error; err != nil {
+			return err
+		}
+
+		delta := int(variant.Stok) - int(current.Stok)
+		adjustment := repo.change.Movement(variant.ProductID, &variant.ID, delta, constant.STOCK_REASON_ADJUSTMENT)
+		if err := repo.record(tx, adjustment); err != nil {
+			return err
+		}
+		return repo.syncProductStock(tx, product, delta)
+	})
+	if err != nil {
+		return err
+	}
+
+	repo.notifyReloaded(variant.ProductID)
+	return nil
+}
+
+// DeleteVariant removes a SKU. Its remaining stock is written off in the
+// ledger.
+func (repo *ProductRepository) DeleteVariant(variant *model.ProductVariant) error {
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		product, err := lockProductStock(tx, variant.ProductID)
+		if err != nil {
+			return err
+		}
+		current, err := lockVariantStock(tx, variant.ID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Delete(&model.ProductVariant{}, variant.ID).Error; err != nil {
+			return err
+		}
+
+		delta := -int(current.Stok)
+		writeOff := repo.change.Movement(variant.ProductID, &variant.ID, delta, constant.STOCK_REASON_ADJUSTMENT)
+		if err := repo.record(tx, writeOff); err != nil {
+			return err
+		}
+		return repo.syncProductStock(tx, product, delta)
+	})
+	if err != nil {
+		return err
+	}
+
+	repo.notifyReloaded(variant.ProductID)
+	return nil
+}
+
+// UpdateVariantStock sets the stock of a single SKU and refreshes the
+// product-level total.
+func (repo *ProductRepository) UpdateVariantStock(variantID uint, newStock uint) error {
+	variant, err := repo.GetVariantByID(variantID)
+	if err != nil {
+		return err
+	}
+
+	err = repo.db.Transaction(func(tx *gorm.DB) error {
+		product, err := lockProductStock(tx, variant.ProductID)
+		if err != nil {
+			return err
+		}
+		current, err := lockVariantStock(tx, variantID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&model.ProductVariant{}).
+			Where("id = ?", variantID).
+			Update("stok", newStock).Error; err != nil {
+			return err
+		}
+
+		delta := int(newStock) - int(current.Stok)
+		adjustment := repo.change.Movement(variant.ProductID, &variantID, delta, constant.STOCK_REASON_ADJUSTMENT)
+		if err := repo.record(tx, adjustment); err != nil {
+			return err
+		}
+		return repo.syncProductStock(tx, product, delta)
+	})
+	if err != nil {
+		return err
+	}
+
+	repo.notifyReloaded(variant.ProductID)
+	return nil
+}
@@ -0,0 +1,302 @@
+package search
+
+import (
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"go-online-store/internal/domain/product/model"
+)
+
+type field int
+
+const (
+	fieldName field = iota
+	fieldCategory
+	fieldDescription
+	numFields
+)
+
+var (
+	fieldNames   = [numFields]string{"name", "category", "description"}
+	fieldWeights = [numFields]float64{3, 2, 1}
+)
+
+// BM25 parameters
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// Match quality of a candidate term relative to the query term
+const (
+	qualityExact  = 1.0
+	qualityPrefix = 0.8
+	qualityTypo   = 0.6
+)
+
+// Result is a ranked search hit. Highlights holds the matched fields with
+// matching terms wrapped in <em> tags.
+type Result struct {
+	Product    model.Product     `json:"product"`
+	Score      float64           `json:"score"`
+	Highlights map[string]string `json:"highlights"`
+}
+
+type document struct {
+	product model.Product
+	terms   map[string]struct{}
+	lengths [numFields]int
+}
+
+// Index is an in-memory inverted index over product name, category and
+// description. It is safe for concurrent use.
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[uint]*document
+	postings map[string]map[uint]*[numFields]int
+	totalLen [numFields]int
+}
+
+func NewIndex() *Index {
+	return &Index{
+		docs:     make(map[uint]*document),
+		postings: make(map[string]map[uint]*[numFields]int),
+	}
+}
+
+// Rebuild replaces the whole index with the given products.
+func (idx *Index) Rebuild(products []*model.Product) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.docs = make(map[uint]*document, len(products))
+	idx.postings = make(map[string]map[uint]*[numFields]int)
+	idx.totalLen = [numFields]int{}
+	for _, product := range products {
+		idx.add(product)
+	}
+}
+
+// Upsert adds a product or replaces its previous entry.
+func (idx *Index) Upsert(product *model.Product) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(product.ID)
+	idx.add(product)
+}
+
+// Remove drops a product from the index.
+func (idx *Index) Remove(productID uint) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(productID)
+}
+
+// Len returns the number of indexed products.
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return len(idx.docs)
+}
+
+// ProductChanged keeps the index in sync with product writes.
+func (idx *Index) ProductChanged(product *model.Product) {
+	idx.Upsert(product)
+}
+
+// ProductDeleted keeps the index in sync with product deletes.
+func (idx *Index) ProductDeleted(productID uint) {
+	idx.Remove(productID)
+}
+
+func (idx *Index) add(product *model.Product) {
+	doc := &document{
+		product: *product,
+		terms:   make(map[string]struct{}),
+	}
+
+	for f, text := range fieldTexts(product) {
+		tokens := tokenize(text)
+		doc.lengths[f] = len(tokens)
+		idx.totalLen[f] += len(tokens)
+		for _, tok := range tokens {
+			docs, ok := idx.postings[tok.term]
+			if !ok {
+				docs = make(map[uint]*[numFields]int)
+				idx.postings[tok.term] = docs
+			}
+			freqs, ok := docs[product.ID]
+			if !ok {
+				freqs = &[numFields]int{}
+				docs[product.ID] = freqs
+			}
+			freqs[f]++
+			doc.terms[tok.term] = struct{}{}
+		}
+	}
+
+	idx.docs[product.ID] = doc
+}
+
+func (idx *Index) remove(productID uint) {
+	doc, ok := idx.docs[productID]
+	if !ok {
+		return
+	}
+
+	for term := range doc.terms {
+		delete(idx.postings[term], productID)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	for f := range doc.lengths {
+		idx.totalLen[f] -= doc.lengths[f]
+	}
+	delete(idx.docs, productID)
+}
+
+// Search returns up to limit products ranked by relevance to the query.
+// The last query term also matches as a prefix, and longer terms tolerate
+// one or two typos.
+func (idx *Index) Search(query string, limit int) []Result {
+	queryTokens := tokenize(query)
+	if len(queryTokens) == 0 {
+		return nil
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	n := float64(len(idx.docs))
+	if n == 0 {
+		return nil
+	}
+
+	var avgLen [numFields]float64
+	for f := range avgLen {
+		avgLen[f] = float64(idx.totalLen[f]) / n
+	}
+
+	scores := make(map[uint]float64)
+	matchedTokens := make(map[uint]int)
+	matchedTerms := make(map[uint]map[string]struct{})
+
+	for i, qt := range queryTokens {
+		candidates := idx.expand(qt.term, i == len(queryTokens)-1)
+
+		best := make(map[uint]float64)
+		for term, quality := range candidates {
+			docs := idx.postings[term]
+			df := float64(len(docs))
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+
+			for docID, freqs := range docs {
+				doc := idx.docs[docID]
+				var score float64
+				for f, tf := range freqs {
+					if tf == 0 {
+						continue
+					}
+					norm := 1 - b + b*float64(doc.lengths[f])/math.Max(avgLen[f], 1)
+					score += fieldWeights[f] * idf * float64(tf) * (k1 + 1) / (float64(tf) + k1*norm)
+				}
+				score *= quality
+				if score > best[docID] {
+					best[docID] = score
+				}
+
+				if matchedTerms[docID] == nil {
+					matchedTerms[docID] = make(map[string]struct{})
+				}
+				matchedTerms[docID][term] = struct{}{}
+			}
+		}
+
+		for docID, score := range best {
+			scores[docID] += score
+			matchedTokens[docID]++
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+	for docID, score := range scores {
+		// Favour products that match more of the query terms
+		coverage := float64(matchedTokens[docID]) / float64(len(queryTokens))
+		doc := idx.docs[docID]
+		results = append(results, Result{
+			Product:    doc.product,
+			Score:      score * coverage * coverage,
+			Highlights: highlight(&doc.product, matchedTerms[docID]),
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Product.ID < results[j].Product.ID
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// expand returns the indexed terms that match a query term and their quality.
+func (idx *Index) expand(term string, prefix bool) map[string]float64 {
+	candidates := make(map[string]float64)
+	maxEdits := allowedEdits(term)
+
+	for candidate := range idx.postings {
+		switch {
+		case candidate == term:
+			candidates[candidate] = qualityExact
+		case prefix && len(term) >= 2 && strings.HasPrefix(candidate, term):
+			candidates[candidate] = qualityPrefix
+		case maxEdits > 0:
+			if d := editDistance(term, candidate, maxEdits); d <= maxEdits {
+				candidates[candidate] = qualityTypo / float64(d)
+			}
+		}
+	}
+
+	return candidates
+}
+
+func fieldTexts(product *model.Product) [numFields]string {
+	return [numFields]string{product.Name, product.Category, product.Description}
+}
+
+// highlight wraps every matched term of each field in <em> tags.
+func highlight(product *model.Product, terms map[string]struct{}) map[string]string {
+	highlights := make(map[string]string)
+
+	for f, text := range fieldTexts(product) {
+		var sb strings.Builder
+		last, matched := 0, false
+		for _, tok := range tokenize(text) {
+			if _, ok := terms[tok.term]; !ok {
+				continue
+			}
+			sb.WriteString(html.EscapeString(text[last:tok.start]))
+			sb.WriteString("<em>")
+			sb.WriteString(html.EscapeString(text[tok.start:tok.end]))
+			sb.WriteString("</em>")
+			last, matched = tok.end, true
+		}
+		if matched {
+			sb.WriteString(html.EscapeString(text[last:]))
+			highlights[fieldNames[f]] = sb.String()
+		}
+	}
+
+	return highlights
+}
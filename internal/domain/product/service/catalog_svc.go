@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-online-store/internal/domain/product/catalog"
+	"go-online-store/internal/domain/product/model"
+	"go-online-store/pkg/constant"
+	customErrors "go-online-store/pkg/errors"
+	"io"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// MaxImportSize is the largest accepted import file in bytes.
+const MaxImportSize = 32 << 20
+
+// exportBatchSize is the number of products read per query during an export.
+const exportBatchSize = 500
+
+// ImportProducts upserts the products of a CSV or JSON Lines file. A row
+// updates the product with its SKU, or with its name when the row has no SKU
+// or no product has the SKU yet; otherwise it creates a product. Rows are
+// applied one by one and a rejected row does not stop the import. With
+// dryRun set every row is validated but nothing is written.
+func (productService *ProductService) ImportProducts(ctx context.Context, format string, r io.Reader, dryRun bool) (*catalog.ImportReport, error) {
+	productService.logger.Info("Importing products from " + format + " file")
+	reader, err := catalog.NewReader(format, r)
+	if err != nil {
+		productService.logger.Error("Failed to read import file: " + err.Error())
+		return nil, catalogError(err)
+	}
+
+	change := stockChange(ctx)
+	if change.Actor == "" {
+		change.Actor = constant.STOCK_ACTOR_SYSTEM
+	}
+	change.Note = "catalog import"
+	products := productService.repoProduct.WithStockChange(change)
+
+	report := &catalog.ImportReport{Format: format, DryRun: dryRun, Errors: []catalog.RowError{}}
+	seen := make(map[string]int)
+	for {
+		row, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		report.Rows++
+
+		var rowErr *catalog.RowError
+		if errors.As(err, &rowErr) {
+			report.Fail(row, rowErr.Message)
+			continue
+		}
+		if err != nil {
+			productService.logger.Error("Failed to read import file: " + err.Error())
+			return nil, catalogError(err)
+		}
+
+		row.Normalize()
+		if err := row.Validate(); err != nil {
+			report.Fail(row, err.Error())
+			continue
+		}
+		if line, ok := seen[row.Key()]; ok {
+			report.Fail(row, fmt.Sprintf("duplicate of line %d", line))
+			continue
+		}
+		seen[row.Key()] = row.Line
+
+		product, err := productService.importedProduct(row)
+		if err != nil {
+			report.Fail(row, err.Error())
+			continue
+		}
+
+		isNew := product.ID == 0
+		if !dryRun {
+			if isNew {
+				err = products.Create(product)
+			} else {
+				err = products.Update(product)
+			}
+			if err != nil {
+				productService.logger.Error("Failed to import line " + fmt.Sprint(row.Line) + ": " + err.Error())
+				report.Fail(row, "failed to save product")
+				continue
+			}
+		}
+
+		if isNew {
+			report.Created++
+		} else {
+			report.Updated++
+		}
+	}
+
+	productService.logger.Info(fmt.Sprintf("Imported %d rows: %d created, %d updated, %d failed", report.Rows, report.Created, report.Updated, report.Failed))
+	return report, nil
+}
+
+// importedProduct returns the product a row creates, or the existing product
+// it matches with the row applied. Nothing is written.
+func (productService *ProductService) importedProduct(row catalog.Row) (*model.Product, error) {
+	product, err := productService.matchProduct(row)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		product = &model.Product{}
+	}
+
+	if row.SKU != "" {
+		product.SKU = &row.SKU
+	}
+	product.Name = row.Name
+	product.Description = row.Description
+	product.Price = *row.Price
+	if row.Stock != nil {
+		product.Stok = *row.Stock
+	}
+
+	product.CategoryID = nil
+	product.Category = row.Category
+	if err := productService.resolveCategory(product); err != nil {
+		if errors.Is(err, customErrors.ErrInvalidCategory) {
+			return nil, fmt.Errorf("unknown category %q", row.Category)
+		}
+		return nil, err
+	}
+
+	return product, nil
+}
+
+// matchProduct finds the product a row upserts: by SKU, then by name among
+// products without a SKU so that an existing catalog can adopt SKUs. A name
+// shared by several products is ambiguous and rejected.
+func (productService *ProductService) matchProduct(row catalog.Row) (*model.Product, error) {
+	if row.SKU != "" {
+		product, err := productService.repoProduct.GetBySKU(row.SKU)
+		if err == nil {
+			return product, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+
+	candidates, err := productService.repoProduct.GetByName(row.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*model.Product
+	for _, candidate := range candidates {
+		if row.SKU == "" || candidate.SKU == nil {
+			matches = append(matches, candidate)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, nil
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("name matches %d products; give the row a sku", len(matches))
+	}
+}
+
+// ExportProducts streams the whole catalog to w as CSV or JSON Lines and
+// returns the number of products written. Output is flushed after every
+// batch, so large catalogs are never held in memory.
+func (productService *ProductService) ExportProducts(ctx context.Context, format string, w io.Writer) (int, error) {
+	productService.logger.Info("Exporting products as " + format)
+	writer, err := catalog.NewWriter(format, w)
+	if err != nil {
+		return 0, catalogError(err)
+	}
+
+	count := 0
+	err = productService.repoProduct.GetAllInBatches(exportBatchSize, func(products []*model.Product) error {
+		for _, product := range products {
+			if err := writer.Write(catalog.RowFromProduct(product)); err != nil {
+				return err
+			}
+			count++
+		}
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+		if flusher, ok := w.(interface{ Flush() }); ok {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		productService.logger.Error("Failed to export products: " + err.Error())
+		return count, err
+	}
+
+	return count, writer.Flush()
+}
+
+// validateSKU trims the SKU of a product and checks no other product has it.
+// An empty SKU is stored as none.
+func (productService *ProductService) validateSKU(product *model.Product) error {
+	if product.SKU == nil {
+		return nil
+	}
+
+	sku := strings.TrimSpace(*product.SKU)
+	if sku == "" {
+		product.SKU = nil
+		return nil
+	}
+	product.SKU = &sku
+
+	existing, err := productService.repoProduct.GetBySKU(sku)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if existing != nil && existing.ID != product.ID {
+		return customErrors.ErrDuplicateSKU
+	}
+	return nil
+}
+
+// catalogError maps errors of the catalog file formats to service errors.
+func catalogError(err error) error {
+	switch {
+	case errors.Is(err, catalog.ErrUnsupportedFormat):
+		return customErrors.ErrUnsupportedMedia
+	case errors.Is(err, catalog.ErrInvalidFile):
+		detail := strings.TrimPrefix(err.Error(), catalog.ErrInvalidFile.Error()+": ")
+		return fmt.Errorf("%w: %s", customErrors.ErrInvalidImportFile, detail)
+	}
+	return err
+}
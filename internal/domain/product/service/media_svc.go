@@ -0,0 +1,254 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	storageConfig "go-online-store/config/storage"
+	"go-online-store/internal/domain/product/media"
+	"go-online-store/internal/domain/product/model"
+	"go-online-store/internal/domain/product/repository"
+	customErrors "go-online-store/pkg/errors"
+	"go-online-store/pkg/logger"
+	"go-online-store/pkg/storage"
+	"net/http"
+	"os"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxMediaSize is the largest accepted upload in bytes.
+const MaxMediaSize = 10 << 20
+
+type MediaService struct {
+	repoProduct repository.ProductRepositoryImpl
+	storage     storage.Storage
+	logger      *logger.Logger
+}
+
+type MediaServiceImpl interface {
+	GetProductMedia(ctx context.Context, productId uint) ([]model.ProductMedia, error)
+	UploadMedia(ctx context.Context, productId uint, data []byte, altText string) (*model.ProductMedia, error)
+	UpdateMediaAltText(ctx context.Context, productId uint, mediaId uint, altText string) (*model.ProductMedia, error)
+	ReorderMedia(ctx context.Context, productId uint, mediaIds []uint) ([]model.ProductMedia, error)
+	DeleteMedia(ctx context.Context, productId uint, mediaId uint) error
+}
+
+func NewInstanceMediaService() MediaServiceImpl {
+	log := logger.NewLogger(os.Stdout, "Service [Media] :")
+	productRepo, err := repository.NewProductRepository()
+	if err != nil {
+		log.Error("Failed to initialize product repository: " + err.Error())
+		return nil
+	}
+
+	cfg := storageConfig.LoadStorageConfig()
+	localStorage, err := storage.NewLocalStorage(cfg.MediaRoot, cfg.MediaBaseURL)
+	if err != nil {
+		log.Error("Failed to initialize media storage: " + err.Error())
+		return nil
+	}
+
+	return NewMediaService(productRepo, localStorage, log)
+}
+
+// NewMediaService builds a media service on top of any storage backend.
+func NewMediaService(productRepo repository.ProductRepositoryImpl, store storage.Storage, log *logger.Logger) *MediaService {
+	mediaService := &MediaService{
+		repoProduct: productRepo,
+		storage:     store,
+		logger:      log,
+	}
+	repository.RegisterObserver(mediaService)
+	return mediaService
+}
+
+func (mediaService *MediaService) GetProductMedia(ctx context.Context, productId uint) ([]model.ProductMedia, error) {
+	if err := mediaService.ensureProduct(productId); err != nil {
+		return nil, err
+	}
+
+	return mediaService.repoProduct.GetMediaByProductID(productId)
+}
+
+// UploadMedia stores an image with its thumbnails and appends it to the
+// product's media list.
+func (mediaService *MediaService) UploadMedia(ctx context.Context, productId uint, data []byte, altText string) (*model.ProductMedia, error) {
+	mediaService.logger.Info("Uploading media for product with ID: " + fmt.Sprint(productId))
+	if err := mediaService.ensureProduct(productId); err != nil {
+		return nil, err
+	}
+
+	if len(data) > MaxMediaSize {
+		return nil, customErrors.ErrMediaTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	ext, ok := media.SupportedContentTypes[contentType]
+	if !ok {
+		return nil, customErrors.ErrUnsupportedMedia
+	}
+
+	img, err := media.Decode(data)
+	if err != nil {
+		return nil, customErrors.ErrUnsupportedMedia
+	}
+
+	existing, err := mediaService.repoProduct.GetMediaByProductID(productId)
+	if err != nil {
+		return nil, err
+	}
+
+	baseKey := fmt.Sprintf("products/%d/%s", productId, uuid.New().String())
+	item := &model.ProductMedia{
+		ProductID:   productId,
+		AltText:     altText,
+		Position:    len(existing),
+		ContentType: contentType,
+		Width:       img.Bounds().Dx(),
+		Height:      img.Bounds().Dy(),
+		StorageKey:  baseKey + ext,
+		Thumbnails:  make(map[string]string, len(media.ThumbnailSizes)),
+	}
+
+	item.URL, err = mediaService.storage.Save(ctx, item.StorageKey, bytes.NewReader(data))
+	if err != nil {
+		mediaService.logger.Error("Failed to store media: " + err.Error())
+		return nil, err
+	}
+
+	for _, size := range media.ThumbnailSizes {
+		thumb, err := media.Thumbnail(img, size.Max)
+		if err != nil {
+			mediaService.cleanup(ctx, item)
+			return nil, err
+		}
+
+		key := baseKey + "_" + size.Name + ".jpg"
+		url, err := mediaService.storage.Save(ctx, key, bytes.NewReader(thumb))
+		if err != nil {
+			mediaService.logger.Error("Failed to store thumbnail: " + err.Error())
+			mediaService.cleanup(ctx, item)
+			return nil, err
+		}
+		item.ThumbnailKeys = append(item.ThumbnailKeys, key)
+		item.Thumbnails[size.Name] = url
+	}
+
+	if err := mediaService.repoProduct.CreateMedia(item); err != nil {
+		mediaService.logger.Error("Failed to save media: " + err.Error())
+		mediaService.cleanup(ctx, item)
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (mediaService *MediaService) UpdateMediaAltText(ctx context.Context, productId uint, mediaId uint, altText string) (*model.ProductMedia, error) {
+	item, err := mediaService.getMedia(productId, mediaId)
+	if err != nil {
+		return nil, err
+	}
+
+	item.AltText = altText
+	if err := mediaService.repoProduct.UpdateMedia(item); err != nil {
+		mediaService.logger.Error("Failed to update media: " + err.Error())
+		return nil, err
+	}
+	return item, nil
+}
+
+// ReorderMedia sets the display order of a product's media. mediaIds must
+// list every media of the product exactly once.
+func (mediaService *MediaService) ReorderMedia(ctx context.Context, productId uint, mediaIds []uint) ([]model.ProductMedia, error) {
+	existing, err := mediaService.GetProductMedia(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(mediaIds) != len(existing) {
+		return nil, customErrors.ErrBadRequest
+	}
+	known := make(map[uint]bool, len(existing))
+	for _, item := range existing {
+		known[item.ID] = true
+	}
+	for _, id := range mediaIds {
+		if !known[id] {
+			return nil, customErrors.ErrBadRequest
+		}
+		delete(known, id)
+	}
+
+	if err := mediaService.repoProduct.ReorderMedia(productId, mediaIds); err != nil {
+		mediaService.logger.Error("Failed to reorder media: " + err.Error())
+		return nil, err
+	}
+
+	return mediaService.repoProduct.GetMediaByProductID(productId)
+}
+
+func (mediaService *MediaService) DeleteMedia(ctx context.Context, productId uint, mediaId uint) error {
+	item, err := mediaService.getMedia(productId, mediaId)
+	if err != nil {
+		return err
+	}
+
+	if err := mediaService.repoProduct.DeleteMedia(item); err != nil {
+		mediaService.logger.Error("Failed to delete media: " + err.Error())
+		return err
+	}
+	mediaService.cleanup(ctx, item)
+
+	return nil
+}
+
+// ProductChanged is part of repository.ProductObserver.
+func (mediaService *MediaService) ProductChanged(product *model.Product) {}
+
+// ProductDeleted is part of repository.ProductObserver. The media rows are
+// deleted along with the product; see MediaDeleted.
+func (mediaService *MediaService) ProductDeleted(productId uint) {}
+
+// MediaDeleted removes the stored files of the media deleted along with
+// their product.
+func (mediaService *MediaService) MediaDeleted(items []model.ProductMedia) {
+	for i := range items {
+		mediaService.cleanup(context.Background(), &items[i])
+	}
+}
+
+func (mediaService *MediaService) ensureProduct(productId uint) error {
+	if _, err := mediaService.repoProduct.GetByID(productId); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return customErrors.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (mediaService *MediaService) getMedia(productId uint, mediaId uint) (*model.ProductMedia, error) {
+	item, err := mediaService.repoProduct.GetMediaByID(mediaId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, customErrors.ErrNotFound
+		}
+		return nil, err
+	}
+	if item.ProductID != productId {
+		return nil, customErrors.ErrNotFound
+	}
+	return item, nil
+}
+
+// cleanup removes stored objects of a media, logging failures.
+func (mediaService *MediaService) cleanup(ctx context.Context, item *model.ProductMedia) {
+	for _, key := range item.StorageKeys() {
+		if err := mediaService.storage.Delete(ctx, key); err != nil {
+			mediaService.logger.Error("Failed to delete stored media " + key + ": " + err.Error())
+		}
+	}
+}
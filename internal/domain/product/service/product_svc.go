@@ -2,22 +2,57 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	categoryModel "go-online-store/internal/domain/category/model"
+	repoCategory "go-online-store/internal/domain/category/repository"
+	inventoryModel "go-online-store/internal/domain/inventory/model"
+	"go-online-store/internal/domain/product/catalog"
 	"go-online-store/internal/domain/product/model"
 	"go-online-store/internal/domain/product/repository"
+	"go-online-store/internal/domain/product/search"
+	"go-online-store/internal/middleware/jwt"
+	customErrors "go-online-store/pkg/errors"
 	"go-online-store/pkg/logger"
+	"go-online-store/pkg/pagination"
+	"io"
 	"os"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
 )
 
 type ProductService struct {
-	repoProduct repository.ProductRepositoryImpl
-	logger      *logger.Logger
+	repoProduct  repository.ProductRepositoryImpl
+	repoCategory repoCategory.CategoryRepositoryImpl
+	searchIndex  *search.Index
+	logger       *logger.Logger
 }
 
+const defaultSearchLimit = 20
+
+var (
+	searchIndexOnce sync.Once
+	searchIndex     *search.Index
+)
+
 type ProductServiceImpl interface {
+	GetProductList(ctx context.Context) ([]*model.Product, error)
 	GetProductListByCategory(ctx context.Context, category string) ([]*model.Product, error)
+	GetProductPage(ctx context.Context, query model.ProductQuery) (*model.ProductPage, error)
 	GetProductById(ctx context.Context, productId uint) (*model.Product, error)
 	CreateProduct(ctx context.Context, product model.Product) (*model.Product, error)
+	UpdateProduct(ctx context.Context, product model.Product) (*model.Product, error)
+	DeleteProduct(ctx context.Context, productId uint) error
+	SearchProducts(ctx context.Context, query string, limit int) ([]search.Result, error)
+	SetProductOptions(ctx context.Context, productId uint, options []model.ProductOption) (*model.Product, error)
+	GetProductVariants(ctx context.Context, productId uint) ([]model.ProductVariant, error)
+	CreateVariant(ctx context.Context, productId uint, variant model.ProductVariant) (*model.ProductVariant, error)
+	UpdateVariant(ctx context.Context, productId uint, variant model.ProductVariant) (*model.ProductVariant, error)
+	DeleteVariant(ctx context.Context, productId uint, variantId uint) error
+	ImportProducts(ctx context.Context, format string, r io.Reader, dryRun bool) (*catalog.ImportReport, error)
+	ExportProducts(ctx context.Context, format string, w io.Writer) (int, error)
 }
 
 func NewInstanceProductService() ProductServiceImpl {
@@ -28,15 +63,63 @@ func NewInstanceProductService() ProductServiceImpl {
 		return nil
 	}
 
+	categoryRepo, err := repoCategory.NewCategoryRepository()
+	if err != nil {
+		log.Error("Failed to initialize category repository: " + err.Error())
+		return nil
+	}
+
 	return &ProductService{
-		repoProduct: productRepo,
-		logger:      log,
+		repoProduct:  productRepo,
+		repoCategory: categoryRepo,
+		searchIndex:  sharedSearchIndex(productRepo, log),
+		logger:       log,
 	}
 }
 
+// sharedSearchIndex builds the process-wide search index from the catalog once
+// and subscribes it to product writes so it stays current.
+func sharedSearchIndex(productRepo repository.ProductRepositoryImpl, log *logger.Logger) *search.Index {
+	searchIndexOnce.Do(func() {
+		searchIndex = search.NewIndex()
+
+		products, err := productRepo.GetAll()
+		if err != nil {
+			log.Error("Failed to build search index: " + err.Error())
+		} else {
+			searchIndex.Rebuild(products)
+			log.Info("Search index built with " + fmt.Sprint(searchIndex.Len()) + " products")
+		}
+
+		repository.RegisterObserver(searchIndex)
+	})
+	return searchIndex
+}
+
+func (productService *ProductService) GetProductList(ctx context.Context) ([]*model.Product, error) {
+	productService.logger.Info("Fetching all products")
+	productList, err := productService.repoProduct.GetAll()
+	if err != nil {
+		productService.logger.Error("Failed to fetch products: " + err.Error())
+		return nil, err
+	}
+
+	return productList, nil
+}
+
 func (productService *ProductService) GetProductListByCategory(ctx context.Context, category string) ([]*model.Product, error) {
 	productService.logger.Info("Fetching products for category: " + category)
-	productList, err := productService.repoProduct.GetProductsByCategory(category)
+	categoryIDs, err := productService.categoryTree(category, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var productList []*model.Product
+	if len(categoryIDs) > 0 {
+		productList, err = productService.repoProduct.GetProductsByCategoryIDs(categoryIDs)
+	} else {
+		productList, err = productService.repoProduct.GetProductsByCategory(category)
+	}
 	if err != nil {
 		productService.logger.Error("Failed to fetch products for category " + category + ": " + err.Error())
 		return nil, err
@@ -45,11 +128,46 @@ func (productService *ProductService) GetProductListByCategory(ctx context.Conte
 	return productList, nil
 }
 
+// GetProductPage returns one filtered, sorted page of the catalog.
+func (productService *ProductService) GetProductPage(ctx context.Context, query model.ProductQuery) (*model.ProductPage, error) {
+	if query.Sort == "" {
+		query.Sort = model.SortNameAsc
+	}
+	if !model.ValidSort(query.Sort) {
+		return nil, customErrors.ErrBadRequest
+	}
+	if query.MinPrice != nil && query.MaxPrice != nil && *query.MinPrice > *query.MaxPrice {
+		return nil, customErrors.ErrBadRequest
+	}
+	query.Pagination = query.Pagination.Normalize()
+
+	categoryIDs, err := productService.categoryTree(query.Category, query.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+	query.CategoryIDs = categoryIDs
+
+	productService.logger.Info("Fetching product page")
+	page, err := productService.repoProduct.List(query)
+	if err != nil {
+		productService.logger.Error("Failed to fetch product page: " + err.Error())
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			return nil, customErrors.ErrBadRequest
+		}
+		return nil, err
+	}
+
+	return page, nil
+}
+
 func (productService *ProductService) GetProductById(ctx context.Context, productId uint) (*model.Product, error) {
 	productService.logger.Info("Fetching product with ID: " + fmt.Sprint(productId))
 	product, err := productService.repoProduct.GetByID(productId)
 	if err != nil {
 		productService.logger.Error("Failed to fetch product with ID " + fmt.Sprint(productId) + ": " + err.Error())
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, customErrors.ErrNotFound
+		}
 		return nil, err
 	}
 	return product, nil
@@ -57,7 +175,14 @@ func (productService *ProductService) GetProductById(ctx context.Context, produc
 
 func (productService *ProductService) CreateProduct(ctx context.Context, product model.Product) (*model.Product, error) {
 	productService.logger.Info("Creating product")
-	err := productService.repoProduct.Create(&product)
+	if err := productService.resolveCategory(&product); err != nil {
+		return nil, err
+	}
+	if err := productService.validateSKU(&product); err != nil {
+		return nil, err
+	}
+
+	err := productService.repoProduct.WithStockChange(stockChange(ctx)).Create(&product)
 	if err != nil {
 		productService.logger.Error("Failed to create product")
 		return &product, err
@@ -65,3 +190,129 @@ func (productService *ProductService) CreateProduct(ctx context.Context, product
 
 	return &product, nil
 }
+
+// UpdateProduct replaces the stored fields of an existing product.
+func (productService *ProductService) UpdateProduct(ctx context.Context, product model.Product) (*model.Product, error) {
+	productService.logger.Info("Updating product with ID: " + fmt.Sprint(product.ID))
+	existing, err := productService.GetProductById(ctx, product.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Stock of a variant product is the sum of its SKUs and cannot be set directly
+	if existing.HasVariants() {
+		product.Stok = existing.Stok
+	}
+	product.CreatedAt = existing.CreatedAt
+	product.RatingAverage = existing.RatingAverage
+	product.RatingCount = existing.RatingCount
+
+	if err := productService.resolveCategory(&product); err != nil {
+		return nil, err
+	}
+	if err := productService.validateSKU(&product); err != nil {
+		return nil, err
+	}
+
+	if err := productService.repoProduct.WithStockChange(stockChange(ctx)).Update(&product); err != nil {
+		productService.logger.Error("Failed to update product with ID " + fmt.Sprint(product.ID) + ": " + err.Error())
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+// DeleteProduct removes a product from the catalog.
+func (productService *ProductService) DeleteProduct(ctx context.Context, productId uint) error {
+	productService.logger.Info("Deleting product with ID: " + fmt.Sprint(productId))
+	if _, err := productService.GetProductById(ctx, productId); err != nil {
+		return err
+	}
+
+	if err := productService.repoProduct.Delete(productId); err != nil {
+		productService.logger.Error("Failed to delete product with ID " + fmt.Sprint(productId) + ": " + err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// stockChange attributes the stock movements of a catalog edit to the signed-in
+// user.
+func stockChange(ctx context.Context) inventoryModel.StockChange {
+	var change inventoryModel.StockChange
+	if customer, ok := jwt.FromCustomer(ctx); ok {
+		change.Actor = customer.Email
+	}
+	return change
+}
+
+// resolveCategory links a product to its category entity, either by ID or by
+// the slug of the given category name, and copies the category name.
+func (productService *ProductService) resolveCategory(product *model.Product) error {
+	var category *categoryModel.Category
+	var err error
+
+	switch {
+	case product.CategoryID != nil:
+		category, err = productService.repoCategory.GetByID(*product.CategoryID)
+	case product.Category != "":
+		category, err = productService.repoCategory.GetBySlug(categoryModel.Slugify(product.Category))
+	default:
+		return customErrors.ErrInvalidCategory
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return customErrors.ErrInvalidCategory
+		}
+		return err
+	}
+
+	product.CategoryID = &category.ID
+	product.Category = category.Name
+	return nil
+}
+
+// categoryTree returns the IDs of a category and all of its descendants.
+// The category is given by ID or by slug/name; when neither matches a
+// category entity the result is empty and callers fall back to the legacy
+// free-text match.
+func (productService *ProductService) categoryTree(category string, categoryID uint) ([]uint, error) {
+	if category == "" && categoryID == 0 {
+		return nil, nil
+	}
+
+	categories, err := productService.repoCategory.GetAll()
+	if err != nil {
+		productService.logger.Error("Failed to fetch categories: " + err.Error())
+		return nil, err
+	}
+
+	if categoryID == 0 {
+		slug := categoryModel.Slugify(category)
+		for _, c := range categories {
+			if c.Slug == slug {
+				categoryID = c.ID
+				break
+			}
+		}
+		if categoryID == 0 {
+			return nil, nil
+		}
+	}
+
+	return categoryModel.DescendantIDs(categories, categoryID), nil
+}
+
+// SearchProducts runs a ranked, typo-tolerant search over name, category and description.
+func (productService *ProductService) SearchProducts(ctx context.Context, query string, limit int) ([]search.Result, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, customErrors.ErrBadRequest
+	}
+	if limit <= 0 || limit > pagination.MaxLimit {
+		limit = defaultSearchLimit
+	}
+
+	productService.logger.Info("Searching products: " + query)
+	return productService.searchIndex.Search(query, limit), nil
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-online-store/internal/domain/product/model"
+	customErrors "go-online-store/pkg/errors"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// SetProductOptions replaces the option axes of a product. Existing variants
+// must still be valid under the new axes.
+func (productService *ProductService) SetProductOptions(ctx context.Context, productId uint, options []model.ProductOption) (*model.Product, error) {
+	productService.logger.Info("Setting options for product with ID: " + fmt.Sprint(productId))
+	product, err := productService.GetProductById(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for i, option := range options {
+		if option.Name == "" || len(option.Values) == 0 || seen[option.Name] {
+			return nil, customErrors.ErrInvalidVariant
+		}
+		seen[option.Name] = true
+		options[i].Position = i
+	}
+
+	for _, variant := range product.Variants {
+		if !variant.MatchesOptions(options) {
+			productService.logger.Error("Variant " + variant.SKU + " does not match the new options")
+			return nil, customErrors.ErrInvalidVariant
+		}
+	}
+
+	if err := productService.repoProduct.ReplaceOptions(productId, options); err != nil {
+		productService.logger.Error("Failed to set product options: " + err.Error())
+		return nil, err
+	}
+
+	return productService.GetProductById(ctx, productId)
+}
+
+// GetProductVariants lists the SKUs of a product.
+func (productService *ProductService) GetProductVariants(ctx context.Context, productId uint) ([]model.ProductVariant, error) {
+	if _, err := productService.GetProductById(ctx, productId); err != nil {
+		return nil, err
+	}
+
+	variants, err := productService.repoProduct.GetVariantsByProductID(productId)
+	if err != nil {
+		productService.logger.Error("Failed to fetch variants: " + err.Error())
+		return nil, err
+	}
+	return variants, nil
+}
+
+// CreateVariant adds a SKU to a product.
+func (productService *ProductService) CreateVariant(ctx context.Context, productId uint, variant model.ProductVariant) (*model.ProductVariant, error) {
+	productService.logger.Info("Creating variant " + variant.SKU + " for product with ID: " + fmt.Sprint(productId))
+	product, err := productService.GetProductById(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+
+	variant.ID = 0
+	variant.ProductID = productId
+	if err := productService.validateVariant(product, &variant); err != nil {
+		return nil, err
+	}
+
+	if err := productService.repoProduct.WithStockChange(stockChange(ctx)).CreateVariant(&variant); err != nil {
+		productService.logger.Error("Failed to create variant: " + err.Error())
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// UpdateVariant replaces the stored fields of a SKU.
+func (productService *ProductService) UpdateVariant(ctx context.Context, productId uint, variant model.ProductVariant) (*model.ProductVariant, error) {
+	productService.logger.Info("Updating variant with ID: " + fmt.Sprint(variant.ID))
+	product, err := productService.GetProductById(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, ok := product.Variant(variant.ID)
+	if !ok {
+		return nil, customErrors.ErrNotFound
+	}
+
+	variant.ProductID = productId
+	variant.CreatedAt = existing.CreatedAt
+	if err := productService.validateVariant(product, &variant); err != nil {
+		return nil, err
+	}
+
+	if err := productService.repoProduct.WithStockChange(stockChange(ctx)).UpdateVariant(&variant); err != nil {
+		productService.logger.Error("Failed to update variant: " + err.Error())
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// DeleteVariant removes a SKU from a product.
+func (productService *ProductService) DeleteVariant(ctx context.Context, productId uint, variantId uint) error {
+	productService.logger.Info("Deleting variant with ID: " + fmt.Sprint(variantId))
+	product, err := productService.GetProductById(ctx, productId)
+	if err != nil {
+		return err
+	}
+
+	variant, ok := product.Variant(variantId)
+	if !ok {
+		return customErrors.ErrNotFound
+	}
+
+	if err := productService.repoProduct.WithStockChange(stockChange(ctx)).DeleteVariant(variant); err != nil {
+		productService.logger.Error("Failed to delete variant: " + err.Error())
+		return err
+	}
+	return nil
+}
+
+// validateVariant checks the SKU is unique and the option combination is
+// valid and not already taken by a sibling variant.
+func (productService *ProductService) validateVariant(product *model.Product, variant *model.ProductVariant) error {
+	variant.SKU = strings.TrimSpace(variant.SKU)
+	if variant.SKU == "" {
+		return customErrors.ErrInvalidVariant
+	}
+
+	existing, err := productService.repoProduct.GetVariantBySKU(variant.SKU)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if existing != nil && existing.ID != variant.ID {
+		return customErrors.ErrDuplicateSKU
+	}
+
+	if !variant.MatchesOptions(product.Options) {
+		return customErrors.ErrInvalidVariant
+	}
+
+	key := optionsKey(variant.Options)
+	for _, sibling := range product.Variants {
+		if sibling.ID != variant.ID && optionsKey(sibling.Options) == key {
+			return customErrors.ErrInvalidVariant
+		}
+	}
+
+	return nil
+}
+
+func optionsKey(options map[string]string) string {
+	pairs := make([]string, 0, len(options))
+	for name, value := range options {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ";")
+}
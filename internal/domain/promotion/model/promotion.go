@@ -0,0 +1,239 @@
+package model
+
+import (
+	"go-online-store/internal/domain/pricing"
+	"go-online-store/pkg/constant"
+	customErrors "go-online-store/pkg/errors"
+	"strings"
+	"time"
+)
+
+// Promotion is a discount rule. Promotions with a code are coupons the
+// customer enters at checkout; promotions without one apply by themselves to
+// every cart that qualifies. A category limits the rule, and its minimum
+// spend, to the products of that category and the categories below it.
+type Promotion struct {
+	ID          uint    `json:"id" gorm:"primaryKey"`
+	Name        string  `json:"name" gorm:"size:191;not null"`
+	Code        *string `json:"code,omitempty" gorm:"size:32;uniqueIndex"`
+	Type        string  `json:"type" gorm:"size:20;not null"`
+	Value       float64 `json:"value" gorm:"not null;default:0"`
+	BuyQuantity uint    `json:"buy_quantity" gorm:"not null;default:0"`
+	GetQuantity uint    `json:"get_quantity" gorm:"not null;default:0"`
+	CategoryID  *uint   `json:"category_id" gorm:"index"`
+	MinSpend    float64 `json:"min_spend" gorm:"not null;default:0"`
+	// StartsAt and EndsAt bound when the promotion can be used; either may
+	// be left open.
+	StartsAt *time.Time `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at"`
+	// UsageLimit caps the uses of the promotion across all customers and
+	// PerCustomerLimit the uses by one customer; zero means unlimited. Orders
+	// that were cancelled give their use back.
+	UsageLimit       uint `json:"usage_limit" gorm:"not null;default:0"`
+	PerCustomerLimit uint `json:"per_customer_limit" gorm:"not null;default:0"`
+	// Stackable promotions combine with each other; one that is not
+	// stackable is only ever applied alone.
+	Stackable bool `json:"stackable" gorm:"not null"`
+	Active    bool `json:"active" gorm:"not null"`
+	// Scope is the category and every category below it. It is filled in
+	// by the promotion service before the promotion is applied.
+	Scope     []uint    `json:"-" gorm:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (Promotion) TableName() string {
+	return "Promotion"
+}
+
+// NormalizeCode makes codes case-insensitive, so "summer10" and " SUMMER10 "
+// are the same coupon.
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Validate checks the rule is complete for its type: a rate between 0 and 1
+// for percentages, a positive amount for fixed discounts and both quantities
+// for buy-X-get-Y.
+func (p *Promotion) Validate() error {
+	if strings.TrimSpace(p.Name) == "" || p.MinSpend < 0 {
+		return customErrors.ErrInvalidPromotion
+	}
+	if p.StartsAt != nil && p.EndsAt != nil && !p.EndsAt.After(*p.StartsAt) {
+		return customErrors.ErrInvalidPromotion
+	}
+
+	switch p.Type {
+	case constant.PROMOTION_TYPE_PERCENTAGE:
+		if p.Value <= 0 || p.Value > 1 {
+			return customErrors.ErrInvalidPromotion
+		}
+	case constant.PROMOTION_TYPE_FIXED:
+		if p.Value <= 0 {
+			return customErrors.ErrInvalidPromotion
+		}
+	case constant.PROMOTION_TYPE_FREE_SHIPPING:
+	case constant.PROMOTION_TYPE_BUY_X_GET_Y:
+		if p.BuyQuantity == 0 || p.GetQuantity == 0 {
+			return customErrors.ErrInvalidPromotion
+		}
+	default:
+		return customErrors.ErrInvalidPromotion
+	}
+	return nil
+}
+
+// Live reports whether the promotion is switched on and within its validity
+// window at now.
+func (p *Promotion) Live(now time.Time) bool {
+	if !p.Active {
+		return false
+	}
+	if p.StartsAt != nil && now.Before(*p.StartsAt) {
+		return false
+	}
+	return p.EndsAt == nil || now.Before(*p.EndsAt)
+}
+
+// Available reports whether the usage limits allow one more use, given how
+// often the promotion has been used in total and by the customer.
+func (p *Promotion) Available(uses, customerUses int64) bool {
+	if p.UsageLimit > 0 && uses >= int64(p.UsageLimit) {
+		return false
+	}
+	return p.PerCustomerLimit == 0 || customerUses < int64(p.PerCustomerLimit)
+}
+
+// Discount returns how much the promotion takes off b, or zero when b does
+// not qualify.
+func (p *Promotion) Discount(b pricing.Breakdown) float64 {
+	var lines []pricing.Line
+	var subtotal float64
+	for _, line := range b.Lines {
+		if p.covers(line) {
+			lines = append(lines, line)
+			subtotal += line.Subtotal
+		}
+	}
+	if len(lines) == 0 || subtotal < p.MinSpend {
+		return 0
+	}
+
+	switch p.Type {
+	case constant.PROMOTION_TYPE_PERCENTAGE:
+		return p.Value * subtotal
+	case constant.PROMOTION_TYPE_FIXED:
+		return min(p.Value, subtotal)
+	case constant.PROMOTION_TYPE_FREE_SHIPPING:
+		return b.ShippingFee
+	case constant.PROMOTION_TYPE_BUY_X_GET_Y:
+		group := p.BuyQuantity + p.GetQuantity
+		// Validate rejects empty groups, but rows stored before it, or edited
+		// in the database, may still have one
+		if group == 0 {
+			return 0
+		}
+		var discount float64
+		for _, line := range lines {
+			free := line.Quantity / group * p.GetQuantity
+			discount += float64(free) * line.UnitPrice
+		}
+		return discount
+	}
+	return 0
+}
+
+// Adjustment returns the promotion as taken off a breakdown for amount.
+func (p *Promotion) Adjustment(amount float64) pricing.Adjustment {
+	adjustment := pricing.Adjustment{
+		Name:   p.Name,
+		Type:   p.Type,
+		Amount: amount,
+	}
+	if p.ID != 0 {
+		id := p.ID
+		adjustment.PromotionID = &id
+	}
+	if p.Code != nil {
+		adjustment.Code = *p.Code
+	}
+	return adjustment
+}
+
+// Combine picks the promotions to take off b and returns them in the order
+// to apply them. A coupon is always applied: alone when it is not stackable,
+// otherwise together with every stackable automatic promotion. Without a
+// coupon the customer gets whichever is worth more, the stackable automatic
+// promotions together or the best one that is not stackable. Shipping is
+// waived once however many promotions would waive it, and promotions worth
+// nothing are left out.
+func Combine(b pricing.Breakdown, automatic []Promotion, coupon *Promotion) []pricing.Adjustment {
+	var stacked []pricing.Adjustment
+	var stackedTotal float64
+	freeShipping := false
+	stack := func(p *Promotion, amount float64) {
+		if p.Type == constant.PROMOTION_TYPE_FREE_SHIPPING {
+			if freeShipping {
+				return
+			}
+			freeShipping = true
+		}
+		stacked = append(stacked, p.Adjustment(amount))
+		stackedTotal += amount
+	}
+
+	if coupon != nil {
+		amount := coupon.Discount(b)
+		if amount <= 0 {
+			return nil
+		}
+		if !coupon.Stackable {
+			return []pricing.Adjustment{coupon.Adjustment(amount)}
+		}
+		stack(coupon, amount)
+	}
+
+	var best *pricing.Adjustment
+	for i := range automatic {
+		p := &automatic[i]
+		amount := p.Discount(b)
+		if amount <= 0 {
+			continue
+		}
+		if p.Stackable {
+			stack(p, amount)
+			continue
+		}
+		if coupon == nil && (best == nil || amount > best.Amount) {
+			adjustment := p.Adjustment(amount)
+			best = &adjustment
+		}
+	}
+
+	if best != nil && best.Amount > stackedTotal {
+		return []pricing.Adjustment{*best}
+	}
+	return stacked
+}
+
+// covers reports whether a line is within the categories the promotion is
+// limited to.
+func (p *Promotion) covers(line pricing.Line) bool {
+	if p.CategoryID == nil {
+		return true
+	}
+	if line.CategoryID == nil {
+		return false
+	}
+
+	scope := p.Scope
+	if len(scope) == 0 {
+		scope = []uint{*p.CategoryID}
+	}
+	for _, id := range scope {
+		if id == *line.CategoryID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	mysql "go-online-store/config/database/my_sql_db"
+	orderModel "go-online-store/internal/domain/order/model"
+	"go-online-store/internal/domain/promotion/model"
+	"go-online-store/pkg/constant"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// releasedStatuses are the statuses of orders that no longer use the
+// promotions they were placed with.
+var releasedStatuses = []string{
+	constant.ORDER_STATUS_CANCELLED,
+	constant.ORDER_STATUS_REFUNDED,
+	constant.ORDER_STATUS_FAILED,
+}
+
+type PromotionRepository struct {
+	db *gorm.DB
+}
+
+type PromotionRepositoryImpl interface {
+	Create(promotion *model.Promotion) error
+	Update(promotion *model.Promotion) error
+	GetByID(id uint) (*model.Promotion, error)
+	GetByCode(code string) (*model.Promotion, error)
+	GetAll() ([]model.Promotion, error)
+	GetAutomatic(now time.Time) ([]model.Promotion, error)
+	GetForUpdate(id uint) (*model.Promotion, error)
+	CountUses(promotionID uint, customerID uint) (int64, error)
+	WithTx(tx *gorm.DB) PromotionRepositoryImpl
+}
+
+func NewPromotionRepository() (PromotionRepositoryImpl, error) {
+	db, err := mysql.ConnectDatabase()
+	if err != nil {
+		return nil, err
+	}
+
+	db.AutoMigrate(&model.Promotion{})
+	return &PromotionRepository{db: db}, nil
+}
+
+// WithTx returns a repository that runs its statements in tx.
+func (repo *PromotionRepository) WithTx(tx *gorm.DB) PromotionRepositoryImpl {
+	return &PromotionRepository{db: tx}
+}
+
+func (repo *PromotionRepository) Create(promotion *model.Promotion) error {
+	return repo.db.Create(promotion).Error
+}
+
+func (repo *PromotionRepository) Update(promotion *model.Promotion) error {
+	return repo.db.Save(promotion).Error
+}
+
+func (repo *PromotionRepository) GetByID(id uint) (*model.Promotion, error) {
+	var promotion model.Promotion
+	if err := repo.db.First(&promotion, id).Error; err != nil {
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+func (repo *PromotionRepository) GetByCode(code string) (*model.Promotion, error) {
+	var promotion model.Promotion
+	if err := repo.db.Where("code = ?", code).First(&promotion).Error; err != nil {
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+func (repo *PromotionRepository) GetAll() ([]model.Promotion, error) {
+	var promotions []model.Promotion
+	if err := repo.db.Order("id DESC").Find(&promotions).Error; err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
+
+// GetAutomatic returns the active promotions without a code that are within
+// their validity window at now.
+func (repo *PromotionRepository) GetAutomatic(now time.Time) ([]model.Promotion, error) {
+	var promotions []model.Promotion
+	err := repo.db.
+		Where("active = ? AND code IS NULL", true).
+		Where("starts_at IS NULL OR starts_at <= ?", now).
+		Where("ends_at IS NULL OR ends_at > ?", now).
+		Order("id").
+		Find(&promotions).Error
+	if err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
+
+// GetForUpdate reads a promotion and locks its row until the surrounding
+// transaction ends, so concurrent checkouts cannot exceed its usage limits.
+func (repo *PromotionRepository) GetForUpdate(id uint) (*model.Promotion, error) {
+	var promotion model.Promotion
+	if err := repo.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&promotion, id).Error; err != nil {
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+// CountUses counts the orders a promotion was taken off, leaving out orders
+// that were cancelled. A customerID other than zero counts only the orders
+// of that customer.
+func (repo *PromotionRepository) CountUses(promotionID uint, customerID uint) (int64, error) {
+	orders := repo.db.Model(&orderModel.Order{}).Select("id").Where("order_status NOT IN ?", releasedStatuses)
+	if customerID != 0 {
+		orders = orders.Where("customer_id = ?", customerID)
+	}
+
+	var uses int64
+	err := repo.db.Model(&orderModel.OrderPromotion{}).
+		Where("promotion_id = ? AND order_id IN (?)", promotionID, orders).
+		Count(&uses).Error
+	return uses, err
+}
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"errors"
+	categoryModel "go-online-store/internal/domain/category/model"
+	repoCategory "go-online-store/internal/domain/category/repository"
+	"go-online-store/internal/domain/pricing"
+	"go-online-store/internal/domain/promotion/model"
+	"go-online-store/internal/domain/promotion/repository"
+	customErrors "go-online-store/pkg/errors"
+	"go-online-store/pkg/logger"
+	"os"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PromotionService struct {
+	repoPromotion repository.PromotionRepositoryImpl
+	repoCategory  repoCategory.CategoryRepositoryImpl
+	logger        *logger.Logger
+}
+
+type PromotionServiceImpl interface {
+	GetPromotions(ctx context.Context) ([]model.Promotion, error)
+	GetPromotion(ctx context.Context, id uint) (*model.Promotion, error)
+	CreatePromotion(ctx context.Context, promotion model.Promotion) (*model.Promotion, error)
+	UpdatePromotion(ctx context.Context, promotion model.Promotion) (*model.Promotion, error)
+	GetCoupon(ctx context.Context, customerID uint, code string) (*model.Promotion, error)
+	ApplyPromotions(ctx context.Context, customerID uint, breakdown *pricing.Breakdown, coupon *model.Promotion) error
+}
+
+func NewInstancePromotionService() PromotionServiceImpl {
+	log := logger.NewLogger(os.Stdout, "Service [Promotion] :")
+	promotionRepo, err := repository.NewPromotionRepository()
+	if err != nil {
+		log.Error("Failed to initialize promotion repository: " + err.Error())
+		return nil
+	}
+
+	categoryRepo, err := repoCategory.NewCategoryRepository()
+	if err != nil {
+		log.Error("Failed to initialize category repository: " + err.Error())
+		return nil
+	}
+
+	return NewPromotionService(promotionRepo, categoryRepo, log)
+}
+
+// NewPromotionService builds a promotion service on any promotion and
+// category repositories.
+func NewPromotionService(promotionRepo repository.PromotionRepositoryImpl, categoryRepo repoCategory.CategoryRepositoryImpl, log *logger.Logger) *PromotionService {
+	return &PromotionService{
+		repoPromotion: promotionRepo,
+		repoCategory:  categoryRepo,
+		logger:        log,
+	}
+}
+
+func (promotionService *PromotionService) GetPromotions(ctx context.Context) ([]model.Promotion, error) {
+	promotions, err := promotionService.repoPromotion.GetAll()
+	if err != nil {
+		promotionService.logger.Error("Failed to fetch promotions: " + err.Error())
+		return nil, err
+	}
+	return promotions, nil
+}
+
+func (promotionService *PromotionService) GetPromotion(ctx context.Context, id uint) (*model.Promotion, error) {
+	promotion, err := promotionService.repoPromotion.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, customErrors.ErrNotFound
+		}
+		promotionService.logger.Error("Failed to fetch promotion: " + err.Error())
+		return nil, err
+	}
+	return promotion, nil
+}
+
+func (promotionService *PromotionService) CreatePromotion(ctx context.Context, promotion model.Promotion) (*model.Promotion, error) {
+	promotionService.logger.Info("Creating promotion " + promotion.Name)
+	if err := promotionService.prepare(&promotion); err != nil {
+		return nil, err
+	}
+
+	if err := promotionService.repoPromotion.Create(&promotion); err != nil {
+		promotionService.logger.Error("Failed to create promotion: " + err.Error())
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+// UpdatePromotion replaces the rule of a promotion. Orders already placed
+// keep the discount they were given.
+func (promotionService *PromotionService) UpdatePromotion(ctx context.Context, promotion model.Promotion) (*model.Promotion, error) {
+	promotionService.logger.Info("Updating promotion " + promotion.Name)
+	existing, err := promotionService.GetPromotion(ctx, promotion.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := promotionService.prepare(&promotion); err != nil {
+		return nil, err
+	}
+
+	promotion.CreatedAt = existing.CreatedAt
+	if err := promotionService.repoPromotion.Update(&promotion); err != nil {
+		promotionService.logger.Error("Failed to update promotion: " + err.Error())
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+// GetCoupon returns the promotion with code when the customer may use it
+// now. Unknown codes fail with ErrNotFound; codes that are switched off,
+// outside their validity window or used up with ErrInvalidDiscountCode.
+func (promotionService *PromotionService) GetCoupon(ctx context.Context, customerID uint, code string) (*model.Promotion, error) {
+	promotion, err := promotionService.repoPromotion.GetByCode(model.NormalizeCode(code))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, customErrors.ErrNotFound
+		}
+		promotionService.logger.Error("Failed to fetch promotion: " + err.Error())
+		return nil, err
+	}
+	if !promotion.Live(time.Now()) {
+		return nil, customErrors.ErrInvalidDiscountCode
+	}
+
+	available, err := Available(promotionService.repoPromotion, promotion, customerID)
+	if err != nil {
+		promotionService.logger.Error("Failed to count promotion uses: " + err.Error())
+		return nil, err
+	}
+	if !available {
+		return nil, customErrors.ErrInvalidDiscountCode
+	}
+	return promotion, nil
+}
+
+// ApplyPromotions takes the automatic promotions the customer may use, and
+// coupon when one was entered, off breakdown following the stacking rules
+// of Combine. A coupon the cart does not qualify for fails with
+// ErrPromotionNotApplicable. Guests have a customerID of zero; limits per
+// customer are checked when they sign in to check out.
+func (promotionService *PromotionService) ApplyPromotions(ctx context.Context, customerID uint, breakdown *pricing.Breakdown, coupon *model.Promotion) error {
+	if len(breakdown.Lines) == 0 {
+		return nil
+	}
+
+	automatic, err := promotionService.repoPromotion.GetAutomatic(time.Now())
+	if err != nil {
+		promotionService.logger.Error("Failed to fetch promotions: " + err.Error())
+		return err
+	}
+	usable := automatic[:0]
+	for i := range automatic {
+		available, err := Available(promotionService.repoPromotion, &automatic[i], customerID)
+		if err != nil {
+			promotionService.logger.Error("Failed to count promotion uses: " + err.Error())
+			return err
+		}
+		if available {
+			usable = append(usable, automatic[i])
+		}
+	}
+
+	if err := promotionService.scope(usable, coupon); err != nil {
+		promotionService.logger.Error("Failed to fetch categories: " + err.Error())
+		return err
+	}
+
+	adjustments := model.Combine(*breakdown, usable, coupon)
+	if coupon != nil && len(adjustments) == 0 {
+		return customErrors.ErrPromotionNotApplicable
+	}
+	for _, adjustment := range adjustments {
+		breakdown.Apply(adjustment)
+	}
+	return nil
+}
+
+// Available reports whether the usage limits of a promotion allow the
+// customer one more use. Checkout calls it again with a repository bound to
+// its transaction while holding the promotion's row lock.
+func Available(promotions repository.PromotionRepositoryImpl, promotion *model.Promotion, customerID uint) (bool, error) {
+	var uses, customerUses int64
+	var err error
+	if promotion.UsageLimit > 0 {
+		if uses, err = promotions.CountUses(promotion.ID, 0); err != nil {
+			return false, err
+		}
+	}
+	if promotion.PerCustomerLimit > 0 && customerID != 0 {
+		if customerUses, err = promotions.CountUses(promotion.ID, customerID); err != nil {
+			return false, err
+		}
+	}
+	return promotion.Available(uses, customerUses), nil
+}
+
+// prepare normalizes the code and checks the rule, the category and code
+// uniqueness.
+func (promotionService *PromotionService) prepare(promotion *model.Promotion) error {
+	promotion.Name = strings.TrimSpace(promotion.Name)
+	if err := promotion.Validate(); err != nil {
+		return err
+	}
+
+	if promotion.CategoryID != nil {
+		if _, err := promotionService.repoCategory.GetByID(*promotion.CategoryID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return customErrors.ErrInvalidCategory
+			}
+			return err
+		}
+	}
+
+	if promotion.Code == nil {
+		return nil
+	}
+	code := model.NormalizeCode(*promotion.Code)
+	if code == "" {
+		promotion.Code = nil
+		return nil
+	}
+	promotion.Code = &code
+
+	other, err := promotionService.repoPromotion.GetByCode(code)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if other != nil && other.ID != promotion.ID {
+		return customErrors.ErrDuplicatePromotionCode
+	}
+	return nil
+}
+
+// scope fills in the categories each category-scoped promotion covers.
+func (promotionService *PromotionService) scope(promotions []model.Promotion, coupon *model.Promotion) error {
+	scoped := make([]*model.Promotion, 0, len(promotions)+1)
+	for i := range promotions {
+		if promotions[i].CategoryID != nil {
+			scoped = append(scoped, &promotions[i])
+		}
+	}
+	if coupon != nil && coupon.CategoryID != nil {
+		scoped = append(scoped, coupon)
+	}
+	if len(scoped) == 0 {
+		return nil
+	}
+
+	categories, err := promotionService.repoCategory.GetAll()
+	if err != nil {
+		return err
+	}
+	for _, promotion := range scoped {
+		promotion.Scope = categoryModel.DescendantIDs(categories, *promotion.CategoryID)
+	}
+	return nil
+}
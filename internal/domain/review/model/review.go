@@ -0,0 +1,90 @@
+package model
+
+import (
+	"go-online-store/pkg/pagination"
+	"time"
+)
+
+// Review is a customer's rating of a product they bought. Only approved
+// reviews are shown to shoppers and count towards the product rating.
+type Review struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	ProductID      uint      `json:"product_id" gorm:"column:product_id;not null;uniqueIndex:idx_review_product_customer"`
+	CustomerID     uint      `json:"customer_id" gorm:"column:customer_id;not null;uniqueIndex:idx_review_product_customer"`
+	OrderID        uint      `json:"order_id" gorm:"column:order_id;not null"`
+	Rating         uint      `json:"rating" gorm:"column:rating;not null"`
+	Title          string    `json:"title" gorm:"column:title;size:191"`
+	Body           string    `json:"body" gorm:"column:body;type:text"`
+	Status         string    `json:"status" gorm:"column:status;size:16;not null;index"`
+	ModerationNote string    `json:"moderation_note,omitempty" gorm:"column:moderation_note"`
+	HelpfulCount   int       `json:"helpful_count" gorm:"column:helpful_count;not null;default:0"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func (Review) TableName() string {
+	return "Review"
+}
+
+// ReviewVote records whether a customer found a review helpful. A customer
+// has at most one vote per review.
+type ReviewVote struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	ReviewID   uint      `json:"review_id" gorm:"column:review_id;not null;uniqueIndex:idx_review_vote"`
+	CustomerID uint      `json:"customer_id" gorm:"column:customer_id;not null;uniqueIndex:idx_review_vote"`
+	Helpful    bool      `json:"helpful" gorm:"column:helpful;not null"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (ReviewVote) TableName() string {
+	return "ReviewVote"
+}
+
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+// Supported values for ReviewQuery.Sort. A leading "-" sorts descending.
+const (
+	SortHelpful    = "helpful"
+	SortNewest     = "newest"
+	SortRatingAsc  = "rating"
+	SortRatingDesc = "-rating"
+)
+
+// ReviewQuery describes a sorted and paginated review listing. A zero
+// ProductID lists reviews of every product; an empty Status any status.
+type ReviewQuery struct {
+	ProductID  uint
+	Status     string
+	Sort       string
+	Pagination pagination.Params
+}
+
+// ValidSort reports whether s is one of the supported sort values.
+func ValidSort(s string) bool {
+	switch s {
+	case SortHelpful, SortNewest, SortRatingAsc, SortRatingDesc:
+		return true
+	}
+	return false
+}
+
+// ReviewPage is one page of a review listing.
+type ReviewPage struct {
+	Reviews []Review
+	Total   int64
+}
+
+// RatingSummary is the average and number of approved ratings of a product.
+type RatingSummary struct {
+	Average float64
+	Count   int64
+}
+
+// Rounded returns the average rounded to one decimal, as shown to shoppers.
+func (s RatingSummary) Rounded() float64 {
+	return float64(int64(s.Average*10+0.5)) / 10
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	mysql "go-online-store/config/database/my_sql_db"
+	"go-online-store/internal/domain/review/model"
+	"go-online-store/pkg/constant"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReviewRepository struct {
+	db *gorm.DB
+}
+
+type ReviewRepositoryImpl interface {
+	Create(review *model.Review) error
+	Update(review *model.Review) error
+	Delete(id uint) error
+	GetByID(id uint) (*model.Review, error)
+	GetByProductAndCustomer(productID uint, customerID uint) (*model.Review, error)
+	List(query model.ReviewQuery) (*model.ReviewPage, error)
+	RatingSummary(productID uint) (model.RatingSummary, error)
+	Vote(vote *model.ReviewVote) error
+}
+
+func NewReviewRepository() (ReviewRepositoryImpl, error) {
+	db, err := mysql.ConnectDatabase()
+	if err != nil {
+		return nil, err
+	}
+
+	db.AutoMigrate(&model.Review{}, &model.ReviewVote{})
+	return &ReviewRepository{db: db}, nil
+}
+
+func (repo *ReviewRepository) Create(review *model.Review) error {
+	return repo.db.Create(review).Error
+}
+
+func (repo *ReviewRepository) Update(review *model.Review) error {
+	return repo.db.Save(review).Error
+}
+
+// Delete removes a review along with its votes.
+func (repo *ReviewRepository) Delete(id uint) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("review_id = ?", id).Delete(&model.ReviewVote{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Review{}, id).Error
+	})
+}
+
+func (repo *ReviewRepository) GetByID(id uint) (*model.Review, error) {
+	var review model.Review
+	if err := repo.db.First(&review, id).Error; err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (repo *ReviewRepository) GetByProductAndCustomer(productID uint, customerID uint) (*model.Review, error) {
+	var review model.Review
+	if err := repo.db.Where("product_id = ? AND customer_id = ?", productID, customerID).First(&review).Error; err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// List returns one page of reviews matching the query along with the total
+// number of matching rows.
+func (repo *ReviewRepository) List(query model.ReviewQuery) (*model.ReviewPage, error) {
+	db := repo.db.Model(&model.Review{})
+	if query.ProductID != 0 {
+		db = db.Where("product_id = ?", query.ProductID)
+	}
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var reviews []model.Review
+	params := query.Pagination
+	if err := db.Order(reviewOrder(query.Sort)).Offset(params.Offset()).Limit(params.Limit).Find(&reviews).Error; err != nil {
+		return nil, err
+	}
+
+	return &model.ReviewPage{Reviews: reviews, Total: total}, nil
+}
+
+func reviewOrder(sort string) string {
+	switch sort {
+	case model.SortNewest:
+		return "created_at DESC, id DESC"
+	case model.SortRatingAsc:
+		return "rating ASC, created_at DESC, id DESC"
+	case model.SortRatingDesc:
+		return "rating DESC, created_at DESC, id DESC"
+	default:
+		return "helpful_count DESC, created_at DESC, id DESC"
+	}
+}
+
+// RatingSummary averages the approved ratings of a product.
+func (repo *ReviewRepository) RatingSummary(productID uint) (model.RatingSummary, error) {
+	var summary model.RatingSummary
+	err := repo.db.Model(&model.Review{}).
+		Select("COALESCE(AVG(rating), 0) AS average, COUNT(*) AS count").
+		Where("product_id = ? AND status = ?", productID, constant.REVIEW_STATUS_APPROVED).
+		Scan(&summary).Error
+	return summary, err
+}
+
+// Vote records a customer's helpfulness vote, replacing any earlier vote of
+// theirs, and recounts the helpful votes of the review.
+func (repo *ReviewRepository) Vote(vote *model.ReviewVote) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "review_id"}, {Name: "customer_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"helpful", "updated_at"}),
+		}).Create(vote).Error
+		if err != nil {
+			return err
+		}
+
+		var helpful int64
+		if err := tx.Model(&model.ReviewVote{}).Where("review_id = ? AND helpful = ?", vote.ReviewID, true).Count(&helpful).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Review{}).Where("id = ?", vote.ReviewID).UpdateColumn("helpful_count", helpful).Error
+	})
+}
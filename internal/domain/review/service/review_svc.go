@@ -0,0 +1,348 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	repoOrder "go-online-store/internal/domain/order/repository"
+	repoProduct "go-online-store/internal/domain/product/repository"
+	"go-online-store/internal/domain/review/model"
+	"go-online-store/internal/domain/review/repository"
+	"go-online-store/internal/middleware/jwt"
+	"go-online-store/pkg/constant"
+	customErrors "go-online-store/pkg/errors"
+	"go-online-store/pkg/logger"
+	"os"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type ReviewService struct {
+	repoReview  repository.ReviewRepositoryImpl
+	repoProduct repoProduct.ProductRepositoryImpl
+	repoOrder   repoOrder.OrderRepositoryImpl
+	logger      *logger.Logger
+}
+
+type ReviewServiceImpl interface {
+	GetProductReviews(ctx context.Context, productID uint, query model.ReviewQuery) (*model.ReviewPage, error)
+	CreateReview(ctx context.Context, productID uint, review model.Review) (*model.Review, error)
+	UpdateReview(ctx context.Context, review model.Review) (*model.Review, error)
+	DeleteReview(ctx context.Context, reviewID uint) error
+	VoteReview(ctx context.Context, reviewID uint, helpful bool) (*model.Review, error)
+	GetReviews(ctx context.Context, query model.ReviewQuery) (*model.ReviewPage, error)
+	ModerateReview(ctx context.Context, reviewID uint, status string, note string) (*model.Review, error)
+}
+
+func NewInstanceReviewService() ReviewServiceImpl {
+	log := logger.NewLogger(os.Stdout, "Service [Review] :")
+	reviewRepo, err := repository.NewReviewRepository()
+	if err != nil {
+		log.Error("Failed to initialize review repository: " + err.Error())
+		return nil
+	}
+
+	productRepo, err := repoProduct.NewProductRepository()
+	if err != nil {
+		log.Error("Failed to initialize product repository: " + err.Error())
+		return nil
+	}
+
+	orderRepo, err := repoOrder.NewInstanceOrderRepository()
+	if err != nil {
+		log.Error("Failed to initialize order repository: " + err.Error())
+		return nil
+	}
+
+	return NewReviewService(reviewRepo, productRepo, orderRepo, log)
+}
+
+// NewReviewService builds a review service on the given repositories.
+func NewReviewService(reviewRepo repository.ReviewRepositoryImpl, productRepo repoProduct.ProductRepositoryImpl, orderRepo repoOrder.OrderRepositoryImpl, log *logger.Logger) *ReviewService {
+	return &ReviewService{
+		repoReview:  reviewRepo,
+		repoProduct: productRepo,
+		repoOrder:   orderRepo,
+		logger:      log,
+	}
+}
+
+// GetProductReviews returns one page of the approved reviews of a product.
+func (reviewService *ReviewService) GetProductReviews(ctx context.Context, productID uint, query model.ReviewQuery) (*model.ReviewPage, error) {
+	reviewService.logger.Info("Fetching reviews of product with ID: " + fmt.Sprint(productID))
+	if err := reviewService.checkProduct(productID); err != nil {
+		return nil, err
+	}
+
+	query.ProductID = productID
+	query.Status = constant.REVIEW_STATUS_APPROVED
+	return reviewService.list(query)
+}
+
+// CreateReview records the review of a product by the signed-in customer.
+// Only customers with a paid order containing the product may review it,
+// once. The review waits for moderation before it is shown.
+func (reviewService *ReviewService) CreateReview(ctx context.Context, productID uint, review model.Review) (*model.Review, error) {
+	customer, ok := jwt.FromCustomer(ctx)
+	if !ok {
+		return nil, customErrors.ErrUnauthorized
+	}
+	reviewService.logger.Info("Creating review of product with ID: " + fmt.Sprint(productID))
+
+	if err := reviewService.checkProduct(productID); err != nil {
+		return nil, err
+	}
+	if err := validateReview(&review); err != nil {
+		return nil, err
+	}
+
+	_, err := reviewService.repoReview.GetByProductAndCustomer(productID, customer.ID)
+	if err == nil {
+		return nil, customErrors.ErrDuplicateReview
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		reviewService.logger.Error("Failed to fetch review: " + err.Error())
+		return nil, err
+	}
+
+	order, err := reviewService.repoOrder.GetPaidOrderWithProduct(customer.ID, productID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, customErrors.ErrReviewNotAllowed
+		}
+		reviewService.logger.Error("Failed to verify purchase: " + err.Error())
+		return nil, err
+	}
+
+	review.ID = 0
+	review.ProductID = productID
+	review.CustomerID = customer.ID
+	review.OrderID = order.ID
+	review.Status = constant.REVIEW_STATUS_PENDING
+	review.ModerationNote = ""
+	review.HelpfulCount = 0
+	if err := reviewService.repoReview.Create(&review); err != nil {
+		reviewService.logger.Error("Failed to create review: " + err.Error())
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+// UpdateReview changes the rating and text of the signed-in customer's own
+// review. The edited review goes back to moderation.
+func (reviewService *ReviewService) UpdateReview(ctx context.Context, review model.Review) (*model.Review, error) {
+	reviewService.logger.Info("Updating review with ID: " + fmt.Sprint(review.ID))
+	existing, err := reviewService.ownReview(ctx, review.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateReview(&review); err != nil {
+		return nil, err
+	}
+
+	wasApproved := existing.Status == constant.REVIEW_STATUS_APPROVED
+	existing.Rating = review.Rating
+	existing.Title = review.Title
+	existing.Body = review.Body
+	existing.Status = constant.REVIEW_STATUS_PENDING
+	existing.ModerationNote = ""
+	if err := reviewService.repoReview.Update(existing); err != nil {
+		reviewService.logger.Error("Failed to update review: " + err.Error())
+		return nil, err
+	}
+
+	if wasApproved {
+		if err := reviewService.refreshRating(existing.ProductID); err != nil {
+			return nil, err
+		}
+	}
+	return existing, nil
+}
+
+// DeleteReview removes a review. Customers may delete their own reviews and
+// admins any review.
+func (reviewService *ReviewService) DeleteReview(ctx context.Context, reviewID uint) error {
+	reviewService.logger.Info("Deleting review with ID: " + fmt.Sprint(reviewID))
+	customer, ok := jwt.FromCustomer(ctx)
+	if !ok {
+		return customErrors.ErrUnauthorized
+	}
+
+	review, err := reviewService.getReview(reviewID)
+	if err != nil {
+		return err
+	}
+	if review.CustomerID != customer.ID && !customer.IsAdmin() {
+		return customErrors.ErrForbidden
+	}
+
+	if err := reviewService.repoReview.Delete(reviewID); err != nil {
+		reviewService.logger.Error("Failed to delete review: " + err.Error())
+		return err
+	}
+
+	if review.Status == constant.REVIEW_STATUS_APPROVED {
+		return reviewService.refreshRating(review.ProductID)
+	}
+	return nil
+}
+
+// VoteReview records whether the signed-in customer found an approved
+// review helpful. Voting again replaces the earlier vote; customers cannot
+// vote on their own reviews.
+func (reviewService *ReviewService) VoteReview(ctx context.Context, reviewID uint, helpful bool) (*model.Review, error) {
+	reviewService.logger.Info("Voting on review with ID: " + fmt.Sprint(reviewID))
+	customer, ok := jwt.FromCustomer(ctx)
+	if !ok {
+		return nil, customErrors.ErrUnauthorized
+	}
+
+	review, err := reviewService.getReview(reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if review.Status != constant.REVIEW_STATUS_APPROVED {
+		return nil, customErrors.ErrNotFound
+	}
+	if review.CustomerID == customer.ID {
+		return nil, customErrors.ErrForbidden
+	}
+
+	vote := &model.ReviewVote{ReviewID: reviewID, CustomerID: customer.ID, Helpful: helpful}
+	if err := reviewService.repoReview.Vote(vote); err != nil {
+		reviewService.logger.Error("Failed to record vote: " + err.Error())
+		return nil, err
+	}
+
+	return reviewService.getReview(reviewID)
+}
+
+// GetReviews returns one page of reviews of any product for moderation,
+// optionally limited to one status.
+func (reviewService *ReviewService) GetReviews(ctx context.Context, query model.ReviewQuery) (*model.ReviewPage, error) {
+	reviewService.logger.Info("Fetching reviews for moderation")
+	if query.Status != "" && !validStatus(query.Status) {
+		return nil, customErrors.ErrBadRequest
+	}
+	return reviewService.list(query)
+}
+
+// ModerateReview approves or rejects a review and updates the rating of its
+// product.
+func (reviewService *ReviewService) ModerateReview(ctx context.Context, reviewID uint, status string, note string) (*model.Review, error) {
+	reviewService.logger.Info("Moderating review with ID: " + fmt.Sprint(reviewID) + " to " + status)
+	if status != constant.REVIEW_STATUS_APPROVED && status != constant.REVIEW_STATUS_REJECTED {
+		return nil, customErrors.ErrInvalidReview
+	}
+
+	review, err := reviewService.getReview(reviewID)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := review.Status != status
+	review.Status = status
+	review.ModerationNote = strings.TrimSpace(note)
+	if err := reviewService.repoReview.Update(review); err != nil {
+		reviewService.logger.Error("Failed to moderate review: " + err.Error())
+		return nil, err
+	}
+
+	if changed {
+		if err := reviewService.refreshRating(review.ProductID); err != nil {
+			return nil, err
+		}
+	}
+	return review, nil
+}
+
+func (reviewService *ReviewService) list(query model.ReviewQuery) (*model.ReviewPage, error) {
+	if query.Sort == "" {
+		query.Sort = model.SortHelpful
+	}
+	if !model.ValidSort(query.Sort) {
+		return nil, customErrors.ErrBadRequest
+	}
+	query.Pagination = query.Pagination.Normalize()
+
+	page, err := reviewService.repoReview.List(query)
+	if err != nil {
+		reviewService.logger.Error("Failed to fetch reviews: " + err.Error())
+		return nil, err
+	}
+	return page, nil
+}
+
+// refreshRating recomputes the rating shown on a product from its approved
+// reviews.
+func (reviewService *ReviewService) refreshRating(productID uint) error {
+	summary, err := reviewService.repoReview.RatingSummary(productID)
+	if err != nil {
+		reviewService.logger.Error("Failed to compute rating: " + err.Error())
+		return err
+	}
+
+	if err := reviewService.repoProduct.UpdateRating(productID, summary.Rounded(), uint(summary.Count)); err != nil {
+		reviewService.logger.Error("Failed to update product rating: " + err.Error())
+		return err
+	}
+	return nil
+}
+
+func (reviewService *ReviewService) ownReview(ctx context.Context, reviewID uint) (*model.Review, error) {
+	customer, ok := jwt.FromCustomer(ctx)
+	if !ok {
+		return nil, customErrors.ErrUnauthorized
+	}
+
+	review, err := reviewService.getReview(reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if review.CustomerID != customer.ID {
+		return nil, customErrors.ErrForbidden
+	}
+	return review, nil
+}
+
+func (reviewService *ReviewService) getReview(id uint) (*model.Review, error) {
+	review, err := reviewService.repoReview.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, customErrors.ErrNotFound
+		}
+		reviewService.logger.Error("Failed to fetch review: " + err.Error())
+		return nil, err
+	}
+	return review, nil
+}
+
+func (reviewService *ReviewService) checkProduct(productID uint) error {
+	if _, err := reviewService.repoProduct.GetByID(productID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return customErrors.ErrNotFound
+		}
+		reviewService.logger.Error("Failed to fetch product: " + err.Error())
+		return err
+	}
+	return nil
+}
+
+func validateReview(review *model.Review) error {
+	review.Title = strings.TrimSpace(review.Title)
+	review.Body = strings.TrimSpace(review.Body)
+	if review.Rating < model.MinRating || review.Rating > model.MaxRating || review.Body == "" {
+		return customErrors.ErrInvalidReview
+	}
+	return nil
+}
+
+func validStatus(status string) bool {
+	switch status {
+	case constant.REVIEW_STATUS_PENDING, constant.REVIEW_STATUS_APPROVED, constant.REVIEW_STATUS_REJECTED:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"go-online-store/internal/domain/product/model"
+	"time"
+)
+
+// MaxWishlists is the number of lists a customer may keep.
+const MaxWishlists = 20
+
+type Wishlist struct {
+	ID         uint           `json:"id" gorm:"primaryKey"`
+	CustomerID uint           `json:"customer_id" gorm:"not null;index"`
+	Name       string         `json:"name" gorm:"size:100;not null"`
+	ShareToken *string        `json:"share_token,omitempty" gorm:"size:64;uniqueIndex"`
+	Items      []WishlistItem `json:"items" gorm:"foreignKey:WishlistID"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+}
+
+type WishlistItem struct {
+	ID         uint                  `json:"id" gorm:"primaryKey"`
+	WishlistID uint                  `json:"wishlist_id" gorm:"not null;index"`
+	ProductID  uint                  `json:"product_id" gorm:"not null"`
+	VariantID  *uint                 `json:"variant_id"`
+	Product    model.Product         `json:"product" gorm:"foreignKey:ProductID"`
+	Variant    *model.ProductVariant `json:"variant,omitempty" gorm:"foreignKey:VariantID"`
+	CreatedAt  time.Time             `json:"created_at"`
+}
+
+func (Wishlist) TableName() string {
+	return "Wishlist"
+}
+
+func (WishlistItem) TableName() string {
+	return "WishlistItem"
+}
+
+// SharedWishlist is the read-only view of a wishlist opened through its
+// share link. It does not reveal who owns the list.
+type SharedWishlist struct {
+	Name      string         `json:"name"`
+	Items     []WishlistItem `json:"items"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// Shared returns the public view of the wishlist.
+func (w *Wishlist) Shared() SharedWishlist {
+	return SharedWishlist{
+		Name:      w.Name,
+		Items:     w.Items,
+		UpdatedAt: w.UpdatedAt,
+	}
+}
+
+// Item returns the item with the given ID.
+func (w *Wishlist) Item(id uint) (*WishlistItem, bool) {
+	for i := range w.Items {
+		if w.Items[i].ID == id {
+			return &w.Items[i], true
+		}
+	}
+	return nil, false
+}
+
+// FindItem returns the item of a product, or of one of its variants.
+func (w *Wishlist) FindItem(productID uint, variantID *uint) (*WishlistItem, bool) {
+	for i := range w.Items {
+		item := &w.Items[i]
+		if item.ProductID != productID {
+			continue
+		}
+		if (item.VariantID == nil && variantID == nil) ||
+			(item.VariantID != nil && variantID != nil && *item.VariantID == *variantID) {
+			return item, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	mysql "go-online-store/config/database/my_sql_db"
+	"go-online-store/internal/domain/wishlist/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WishlistRepository struct {
+	db *gorm.DB
+}
+
+type WishlistRepositoryImpl interface {
+	GetWishlistsByCustomerID(customerID uint) ([]model.Wishlist, error)
+	GetWishlistByID(id uint) (*model.Wishlist, error)
+	GetWishlistByShareToken(token string) (*model.Wishlist, error)
+	CountWishlists(customerID uint) (int64, error)
+	CreateWishlist(wishlist *model.Wishlist) error
+	UpdateWishlist(wishlist *model.Wishlist) error
+	DeleteWishlist(id uint) error
+	CreateWishlistItem(item *model.WishlistItem) error
+	DeleteWishlistItem(wishlistID uint, itemID uint) error
+}
+
+func NewWishlistRepository() (WishlistRepositoryImpl, error) {
+	db, err := mysql.ConnectDatabase()
+	if err != nil {
+		return nil, err
+	}
+
+	db.AutoMigrate(&model.Wishlist{}, &model.WishlistItem{})
+	return &WishlistRepository{db: db}, nil
+}
+
+func (wishlistRepo *WishlistRepository) withItems() *gorm.DB {
+	return wishlistRepo.db.
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("created_at DESC, id DESC") }).
+		Preload("Items.Product").
+		Preload("Items.Variant")
+}
+
+// GetWishlistsByCustomerID retrieves every list of a customer with its items.
+func (wishlistRepo *WishlistRepository) GetWishlistsByCustomerID(customerID uint) ([]model.Wishlist, error) {
+	var wishlists []model.Wishlist
+	if err := wishlistRepo.withItems().Where("customer_id = ?", customerID).Order("id").Find(&wishlists).Error; err != nil {
+		return nil, err
+	}
+	return wishlists, nil
+}
+
+func (wishlistRepo *WishlistRepository) GetWishlistByID(id uint) (*model.Wishlist, error) {
+	var wishlist model.Wishlist
+	if err := wishlistRepo.withItems().First(&wishlist, id).Error; err != nil {
+		return nil, err
+	}
+	return &wishlist, nil
+}
+
+func (wishlistRepo *WishlistRepository) GetWishlistByShareToken(token string) (*model.Wishlist, error) {
+	var wishlist model.Wishlist
+	if err := wishlistRepo.withItems().Where("share_token = ?", token).First(&wishlist).Error; err != nil {
+		return nil, err
+	}
+	return &wishlist, nil
+}
+
+func (wishlistRepo *WishlistRepository) CountWishlists(customerID uint) (int64, error) {
+	var count int64
+	err := wishlistRepo.db.Model(&model.Wishlist{}).Where("customer_id = ?", customerID).Count(&count).Error
+	return count, err
+}
+
+func (wishlistRepo *WishlistRepository) CreateWishlist(wishlist *model.Wishlist) error {
+	return wishlistRepo.db.Omit(clause.Associations).Create(wishlist).Error
+}
+
+// UpdateWishlist saves the name and share token of a list; items are
+// changed through their own methods.
+func (wishlistRepo *WishlistRepository) UpdateWishlist(wishlist *model.Wishlist) error {
+	return wishlistRepo.db.Omit(clause.Associations).Save(wishlist).Error
+}
+
+// DeleteWishlist removes a list along with its items.
+func (wishlistRepo *WishlistRepository) DeleteWishlist(id uint) error {
+	return wishlistRepo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("wishlist_id = ?", id).Delete(&model.WishlistItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Wishlist{}, id).Error
+	})
+}
+
+func (wishlistRepo *WishlistRepository) CreateWishlistItem(item *model.WishlistItem) error {
+	return wishlistRepo.db.Omit(clause.Associations).Create(item).Error
+}
+
+func (wishlistRepo *WishlistRepository) DeleteWishlistItem(wishlistID uint, itemID uint) error {
+	return wishlistRepo.db.Where("wishlist_id = ? AND id = ?", wishlistID, itemID).Delete(&model.WishlistItem{}).Error
+}
@@ -0,0 +1,341 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	cartService "go-online-store/internal/domain/cart/service"
+	repoProduct "go-online-store/internal/domain/product/repository"
+	"go-online-store/internal/domain/wishlist/model"
+	"go-online-store/internal/domain/wishlist/repository"
+	"go-online-store/internal/middleware/jwt"
+	customErrors "go-online-store/pkg/errors"
+	"go-online-store/pkg/logger"
+	"os"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type WishlistService struct {
+	repoWishlist repository.WishlistRepositoryImpl
+	repoProduct  repoProduct.ProductRepositoryImpl
+	cartService  cartService.CartServiceImpl
+	logger       *logger.Logger
+}
+
+type WishlistServiceImpl interface {
+	GetWishlists(ctx context.Context) ([]model.Wishlist, error)
+	GetWishlist(ctx context.Context, wishlistID uint) (*model.Wishlist, error)
+	CreateWishlist(ctx context.Context, name string) (*model.Wishlist, error)
+	RenameWishlist(ctx context.Context, wishlistID uint, name string) (*model.Wishlist, error)
+	DeleteWishlist(ctx context.Context, wishlistID uint) error
+	AddToWishlist(ctx context.Context, wishlistID uint, productID uint, variantID *uint) (*model.Wishlist, error)
+	RemoveFromWishlist(ctx context.Context, wishlistID uint, itemID uint) (*model.Wishlist, error)
+	MoveToCart(ctx context.Context, wishlistID uint, itemID uint, quantity uint) (*model.Wishlist, error)
+	ShareWishlist(ctx context.Context, wishlistID uint) (*model.Wishlist, error)
+	UnshareWishlist(ctx context.Context, wishlistID uint) (*model.Wishlist, error)
+	GetSharedWishlist(ctx context.Context, token string) (*model.SharedWishlist, error)
+}
+
+func NewInstanceWishlistService() WishlistServiceImpl {
+	log := logger.NewLogger(os.Stdout, "Service [Wishlist] :")
+	wishlistRepo, err := repository.NewWishlistRepository()
+	if err != nil {
+		log.Error("Failed to initialize wishlist repository: " + err.Error())
+		return nil
+	}
+
+	productRepo, err := repoProduct.NewProductRepository()
+	if err != nil {
+		log.Error("Failed to initialize product repository: " + err.Error())
+		return nil
+	}
+
+	carts := cartService.NewInstanceCartService()
+	if carts == nil {
+		log.Error("Failed to initialize cart service")
+		return nil
+	}
+
+	return &WishlistService{
+		repoWishlist: wishlistRepo,
+		repoProduct:  productRepo,
+		cartService:  carts,
+		logger:       log,
+	}
+}
+
+// GetWishlists retrieves every list of the signed-in customer.
+func (wishlistService *WishlistService) GetWishlists(ctx context.Context) ([]model.Wishlist, error) {
+	wishlistService.logger.Info("Retrieving wishlists for customer")
+	customerCtx, ok := jwt.FromCustomer(ctx)
+	if !ok {
+		wishlistService.logger.Error("CustomerID not found on ctx")
+		return nil, customErrors.ErrCustomerIDNotFound
+	}
+
+	wishlists, err := wishlistService.repoWishlist.GetWishlistsByCustomerID(customerCtx.ID)
+	if err != nil {
+		wishlistService.logger.Error("Failed to retrieve wishlists: " + err.Error())
+		return nil, err
+	}
+	return wishlists, nil
+}
+
+// GetWishlist retrieves one list of the signed-in customer.
+func (wishlistService *WishlistService) GetWishlist(ctx context.Context, wishlistID uint) (*model.Wishlist, error) {
+	wishlistService.logger.Info("Retrieving wishlist with ID: " + fmt.Sprint(wishlistID))
+	customerCtx, ok := jwt.FromCustomer(ctx)
+	if !ok {
+		wishlistService.logger.Error("CustomerID not found on ctx")
+		return nil, customErrors.ErrCustomerIDNotFound
+	}
+
+	wishlist, err := wishlistService.repoWishlist.GetWishlistByID(wishlistID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, customErrors.ErrNotFound
+		}
+		wishlistService.logger.Error("Failed to retrieve wishlist: " + err.Error())
+		return nil, err
+	}
+
+	// Lists of other customers are only reachable through their share link
+	if wishlist.CustomerID != customerCtx.ID {
+		return nil, customErrors.ErrNotFound
+	}
+	return wishlist, nil
+}
+
+// CreateWishlist starts a new named list for the signed-in customer.
+func (wishlistService *WishlistService) CreateWishlist(ctx context.Context, name string) (*model.Wishlist, error) {
+	wishlistService.logger.Info("Creating wishlist " + name)
+	customerCtx, ok := jwt.FromCustomer(ctx)
+	if !ok {
+		wishlistService.logger.Error("CustomerID not found on ctx")
+		return nil, customErrors.ErrCustomerIDNotFound
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, customErrors.ErrInvalidWishlist
+	}
+
+	count, err := wishlistService.repoWishlist.CountWishlists(customerCtx.ID)
+	if err != nil {
+		wishlistService.logger.Error("Failed to count wishlists: " + err.Error())
+		return nil, err
+	}
+	if count >= model.MaxWishlists {
+		return nil, customErrors.ErrWishlistLimit
+	}
+
+	wishlist := &model.Wishlist{
+		CustomerID: customerCtx.ID,
+		Name:       name,
+		Items:      []model.WishlistItem{},
+	}
+	if err := wishlistService.repoWishlist.CreateWishlist(wishlist); err != nil {
+		wishlistService.logger.Error("Failed to create wishlist: " + err.Error())
+		return nil, err
+	}
+	return wishlist, nil
+}
+
+// RenameWishlist changes the name of a list.
+func (wishlistService *WishlistService) RenameWishlist(ctx context.Context, wishlistID uint, name string) (*model.Wishlist, error) {
+	wishlist, err := wishlistService.GetWishlist(ctx, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, customErrors.ErrInvalidWishlist
+	}
+
+	wishlist.Name = name
+	if err := wishlistService.repoWishlist.UpdateWishlist(wishlist); err != nil {
+		wishlistService.logger.Error("Failed to rename wishlist: " + err.Error())
+		return nil, err
+	}
+	return wishlist, nil
+}
+
+// DeleteWishlist removes a list and its items.
+func (wishlistService *WishlistService) DeleteWishlist(ctx context.Context, wishlistID uint) error {
+	if _, err := wishlistService.GetWishlist(ctx, wishlistID); err != nil {
+		return err
+	}
+
+	if err := wishlistService.repoWishlist.DeleteWishlist(wishlistID); err != nil {
+		wishlistService.logger.Error("Failed to delete wishlist: " + err.Error())
+		return err
+	}
+	return nil
+}
+
+// AddToWishlist saves a product, or one of its variants, to a list. A
+// product can be saved before a variant is chosen. Saving an item that is
+// already on the list does nothing.
+func (wishlistService *WishlistService) AddToWishlist(ctx context.Context, wishlistID uint, productID uint, variantID *uint) (*model.Wishlist, error) {
+	wishlistService.logger.Info("Adding product to wishlist. ProductID:" + fmt.Sprint(productID))
+	wishlist, err := wishlistService.GetWishlist(ctx, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := wishlistService.repoProduct.GetByID(productID)
+	if err != nil {
+		wishlistService.logger.Error("Failed to retrieve product")
+		return nil, customErrors.ErrNotFound
+	}
+	if variantID != nil {
+		if _, ok := product.Variant(*variantID); !ok {
+			wishlistService.logger.Error("Variant does not belong to product")
+			return nil, customErrors.ErrInvalidVariant
+		}
+	}
+
+	if _, ok := wishlist.FindItem(productID, variantID); ok {
+		return wishlist, nil
+	}
+
+	item := &model.WishlistItem{
+		WishlistID: wishlist.ID,
+		ProductID:  productID,
+		VariantID:  variantID,
+	}
+	if err := wishlistService.repoWishlist.CreateWishlistItem(item); err != nil {
+		wishlistService.logger.Error("Failed to add product to wishlist: " + err.Error())
+		return nil, err
+	}
+
+	return wishlistService.GetWishlist(ctx, wishlistID)
+}
+
+// RemoveFromWishlist removes an item from a list.
+func (wishlistService *WishlistService) RemoveFromWishlist(ctx context.Context, wishlistID uint, itemID uint) (*model.Wishlist, error) {
+	wishlistService.logger.Info("Removing item from wishlist. ItemID:" + fmt.Sprint(itemID))
+	wishlist, err := wishlistService.GetWishlist(ctx, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := wishlist.Item(itemID); !ok {
+		return nil, customErrors.ErrNotFound
+	}
+
+	if err := wishlistService.repoWishlist.DeleteWishlistItem(wishlistID, itemID); err != nil {
+		wishlistService.logger.Error("Failed to remove item from wishlist: " + err.Error())
+		return nil, err
+	}
+
+	return wishlistService.GetWishlist(ctx, wishlistID)
+}
+
+// MoveToCart adds a wishlist item to the cart and takes it off the list.
+// The item stays on the list when the cart refuses it, for example because
+// the product is out of stock or a variant still has to be chosen.
+func (wishlistService *WishlistService) MoveToCart(ctx context.Context, wishlistID uint, itemID uint, quantity uint) (*model.Wishlist, error) {
+	wishlistService.logger.Info("Moving wishlist item to cart. ItemID:" + fmt.Sprint(itemID))
+	wishlist, err := wishlistService.GetWishlist(ctx, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	item, ok := wishlist.Item(itemID)
+	if !ok {
+		return nil, customErrors.ErrNotFound
+	}
+	if quantity == 0 {
+		quantity = 1
+	}
+
+	if err := wishlistService.cartService.AddToCart(ctx, item.ProductID, item.VariantID, quantity); err != nil {
+		wishlistService.logger.Error("Failed to add wishlist item to cart: " + err.Error())
+		return nil, err
+	}
+
+	if err := wishlistService.repoWishlist.DeleteWishlistItem(wishlistID, itemID); err != nil {
+		wishlistService.logger.Error("Failed to remove item from wishlist: " + err.Error())
+		return nil, err
+	}
+
+	return wishlistService.GetWishlist(ctx, wishlistID)
+}
+
+// ShareWishlist gives a list a share link so anyone with the link can view
+// it. A list that is already shared keeps its link.
+func (wishlistService *WishlistService) ShareWishlist(ctx context.Context, wishlistID uint) (*model.Wishlist, error) {
+	wishlist, err := wishlistService.GetWishlist(ctx, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+	if wishlist.ShareToken != nil {
+		return wishlist, nil
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		wishlistService.logger.Error("Failed to generate share token: " + err.Error())
+		return nil, err
+	}
+
+	wishlist.ShareToken = &token
+	if err := wishlistService.repoWishlist.UpdateWishlist(wishlist); err != nil {
+		wishlistService.logger.Error("Failed to share wishlist: " + err.Error())
+		return nil, err
+	}
+	return wishlist, nil
+}
+
+// UnshareWishlist revokes the share link of a list.
+func (wishlistService *WishlistService) UnshareWishlist(ctx context.Context, wishlistID uint) (*model.Wishlist, error) {
+	wishlist, err := wishlistService.GetWishlist(ctx, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+	if wishlist.ShareToken == nil {
+		return wishlist, nil
+	}
+
+	wishlist.ShareToken = nil
+	if err := wishlistService.repoWishlist.UpdateWishlist(wishlist); err != nil {
+		wishlistService.logger.Error("Failed to unshare wishlist: " + err.Error())
+		return nil, err
+	}
+	return wishlist, nil
+}
+
+// GetSharedWishlist returns the read-only view of a shared list. No sign-in
+// is needed.
+func (wishlistService *WishlistService) GetSharedWishlist(ctx context.Context, token string) (*model.SharedWishlist, error) {
+	wishlistService.logger.Info("Retrieving shared wishlist")
+	if token == "" {
+		return nil, customErrors.ErrNotFound
+	}
+
+	wishlist, err := wishlistService.repoWishlist.GetWishlistByShareToken(token)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, customErrors.ErrNotFound
+		}
+		wishlistService.logger.Error("Failed to retrieve shared wishlist: " + err.Error())
+		return nil, err
+	}
+
+	shared := wishlist.Shared()
+	return &shared, nil
+}
+
+// newShareToken returns an unguessable token for a share link.
+func newShareToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -7,10 +7,24 @@ const (
 )
 
 type RequestAddToCard struct {
-	ProductID uint `json:"product_id"`
-	Quantity  uint `json:"quantity"`
+	ProductID uint  `json:"product_id"`
+	VariantID *uint `json:"variant_id"`
+	Quantity  uint  `json:"quantity"`
 }
 
 type ReqRemoveCart struct {
-	ProductID uint `json:"product_id"`
+	ProductID uint  `json:"product_id"`
+	VariantID *uint `json:"variant_id"`
+}
+
+// RequestUpdateCartItem sets the quantity of a cart line; zero removes it.
+type RequestUpdateCartItem struct {
+	VariantID *uint `json:"variant_id"`
+	Quantity  *uint `json:"quantity" validate:"required"`
+}
+
+// RequestMoveCartItem picks the line to move between the cart and the saved
+// for later section.
+type RequestMoveCartItem struct {
+	VariantID *uint `json:"variant_id"`
 }
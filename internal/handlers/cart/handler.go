@@ -5,6 +5,7 @@ import (
 	"go-online-store/internal/domain/cart/service"
 	customErrors "go-online-store/pkg/errors"
 	"net/http"
+	"strconv"
 
 	"github.com/labstack/echo/v4"
 )
@@ -28,18 +29,19 @@ func (h *CartHandler) AddToCartHandler(c echo.Context) error {
 		return customErrors.HTTPErrorHandler(customErrors.ErrBadRequest)
 	}
 
-	if err := h.cartService.AddToCart(ctx, req.ProductID, req.Quantity); err != nil {
+	if err := h.cartService.AddToCart(ctx, req.ProductID, req.VariantID, req.Quantity); err != nil {
 		return customErrors.HTTPErrorHandler(err)
 	}
 
 	return c.JSON(http.StatusOK, map[string]string{"message": "product added to cart"})
 }
 
-// GetCartHandler handles the request to get the cart for a customer
+// GetCartHandler handles the request to get the cart for a customer along
+// with its price breakdown and the items saved for later
 func (h *CartHandler) GetCartHandler(c echo.Context) error {
 	ctx := context.WithValue(c.Request().Context(), ctxKeyUserID, c.Get("id"))
 
-	cart, err := h.cartService.GetCartByCustomerID(ctx)
+	cart, err := h.cartService.GetCartSummary(ctx)
 	if err != nil {
 		return customErrors.HTTPErrorHandler(err)
 	}
@@ -57,9 +59,91 @@ func (h *CartHandler) RemoveFromCartHandler(c echo.Context) error {
 
 	ctx := context.WithValue(c.Request().Context(), ctxKeyUserID, c.Get("id"))
 
-	if err := h.cartService.RemoveFromCart(ctx, req.ProductID); err != nil {
+	if err := h.cartService.RemoveFromCart(ctx, req.ProductID, req.VariantID); err != nil {
 		return customErrors.HTTPErrorHandler(err)
 	}
 
 	return c.JSON(http.StatusOK, map[string]string{"message": "product removed from cart"})
 }
+
+// UpdateCartItemHandler handles the request to set the quantity of a product in the cart
+func (h *CartHandler) UpdateCartItemHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	productID, err := strconv.ParseUint(c.Param("productId"), 10, 64)
+	if err != nil {
+		return customErrors.HTTPErrorHandler(customErrors.ErrBadRequest)
+	}
+
+	var req RequestUpdateCartItem
+	if err := c.Bind(&req); err != nil {
+		return customErrors.HTTPErrorHandler(customErrors.ErrBadRequest)
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Validation error: "+err.Error())
+	}
+
+	if err := h.cartService.UpdateCartItem(ctx, uint(productID), req.VariantID, *req.Quantity); err != nil {
+		return customErrors.HTTPErrorHandler(err)
+	}
+
+	if *req.Quantity == 0 {
+		return c.JSON(http.StatusOK, map[string]string{"message": "product removed from cart"})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "cart updated"})
+}
+
+// ConfirmCartHandler handles the request to accept the price and stock changes reported for the cart
+func (h *CartHandler) ConfirmCartHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	cart, err := h.cartService.ConfirmCart(ctx)
+	if err != nil {
+		return customErrors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, cart)
+}
+
+// SaveForLaterHandler handles the request to move a product out of the cart into the saved for later section
+func (h *CartHandler) SaveForLaterHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	productID, err := strconv.ParseUint(c.Param("productId"), 10, 64)
+	if err != nil {
+		return customErrors.HTTPErrorHandler(customErrors.ErrBadRequest)
+	}
+
+	var req RequestMoveCartItem
+	if err := c.Bind(&req); err != nil {
+		return customErrors.HTTPErrorHandler(customErrors.ErrBadRequest)
+	}
+
+	if err := h.cartService.SaveForLater(ctx, uint(productID), req.VariantID); err != nil {
+		return customErrors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "product saved for later"})
+}
+
+// MoveToCartHandler handles the request to move a product saved for later back into the cart
+func (h *CartHandler) MoveToCartHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	productID, err := strconv.ParseUint(c.Param("productId"), 10, 64)
+	if err != nil {
+		return customErrors.HTTPErrorHandler(customErrors.ErrBadRequest)
+	}
+
+	var req RequestMoveCartItem
+	if err := c.Bind(&req); err != nil {
+		return customErrors.HTTPErrorHandler(customErrors.ErrBadRequest)
+	}
+
+	if err := h.cartService.MoveToCart(ctx, uint(productID), req.VariantID); err != nil {
+		return customErrors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "product moved to cart"})
+}
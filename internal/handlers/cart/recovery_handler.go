@@ -0,0 +1,75 @@
+package cart
+
+import (
+	"net/http"
+	"time"
+
+	"go-online-store/internal/domain/cart/service"
+	customErrors "go-online-store/pkg/errors"
+
+	"github.com/labstack/echo/v4"
+)
+
+// defaultStatsWindow is how far back recovery stats look without a since date.
+const defaultStatsWindow = 30 * 24 * time.Hour
+
+type RecoveryHandler struct {
+	recoveryService service.RecoveryServiceImpl
+}
+
+func NewRecoveryHandler(recoveryService service.RecoveryServiceImpl) *RecoveryHandler {
+	return &RecoveryHandler{
+		recoveryService: recoveryService,
+	}
+}
+
+// @Summary Open abandoned cart reminder
+// @Tags Cart
+// @Description Records that the link in a reminder was followed and returns its discount code
+// @Produce json
+// @Param token path string true "Token from the reminder link"
+// @Success 200 {object} model.CartRecovery
+// @Failure 404 {object} ErrorResponse
+// @Router /v1/cart/recoveries/{token} [get]
+
+// OpenRecoveryHandler handles a customer following the link in an abandoned cart reminder
+func (h *RecoveryHandler) OpenRecoveryHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	recovery, err := h.recoveryService.OpenRecovery(ctx, c.Param("token"))
+	if err != nil {
+		return customErrors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": recovery})
+}
+
+// @Summary Abandoned cart recovery stats
+// @Tags Cart
+// @Description Reminders sent, opened, redeemed and converted since a date (default the last 30 days)
+// @Produce json
+// @Param since query string false "Date as YYYY-MM-DD"
+// @Success 200 {object} model.RecoveryStats
+// @Failure 400 {object} ErrorResponse
+// @Router /v1/cart/recoveries/stats [get]
+
+// GetRecoveryStatsHandler handles the request to measure the abandoned cart campaign
+func (h *RecoveryHandler) GetRecoveryStatsHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	since := time.Now().Add(-defaultStatsWindow)
+	if raw := c.QueryParam("since"); raw != "" {
+		parsed, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			return customErrors.HTTPErrorHandler(customErrors.ErrBadRequest)
+		}
+		since = parsed
+	}
+
+	stats, err := h.recoveryService.GetRecoveryStats(ctx, since)
+	if err != nil {
+		return customErrors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": stats})
+}
@@ -0,0 +1,12 @@
+package category
+
+type RequestCategory struct {
+	Name      string `json:"name" validate:"required,max=191"`
+	Slug      string `json:"slug" validate:"max=191"`
+	ParentID  *uint  `json:"parent_id"`
+	SortOrder int    `json:"sort_order"`
+}
+
+type RequestMergeCategory struct {
+	SourceID uint `json:"source_id" validate:"required"`
+}
@@ -0,0 +1,172 @@
+package category
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-online-store/internal/domain/category/model"
+	"go-online-store/internal/domain/category/service"
+	"go-online-store/pkg/errors"
+
+	"github.com/labstack/echo/v4"
+)
+
+type CategoryHandler struct {
+	categoryService service.CategoryServiceImpl
+}
+
+func NewCategoryHandler(categoryService service.CategoryServiceImpl) *CategoryHandler {
+	return &CategoryHandler{
+		categoryService: categoryService,
+	}
+}
+
+// @Summary Get category tree
+// @Tags Category
+// @Produce json
+// @Success 200 {object} []Category
+// @Router /v1/categories [get]
+
+// GetCategoryTreeHandler handles the request to fetch all categories as a tree
+func (h *CategoryHandler) GetCategoryTreeHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	tree, err := h.categoryService.GetCategoryTree(ctx)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": tree})
+}
+
+// @Summary Get category
+// @Tags Category
+// @Produce json
+// @Param id path int true "Category ID"
+// @Success 200 {object} Category
+// @Failure 404 {object} ErrorResponse
+// @Router /v1/categories/{id} [get]
+
+// GetCategoryHandler handles the request to fetch a category with its subtree
+func (h *CategoryHandler) GetCategoryHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseCategoryID(c)
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	category, err := h.categoryService.GetCategory(ctx, id)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": category})
+}
+
+// CreateCategoryHandler handles the request to create a category
+func (h *CategoryHandler) CreateCategoryHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req RequestCategory
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Validation error: "+err.Error())
+	}
+
+	category, err := h.categoryService.CreateCategory(ctx, req.toModel(0))
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{"data": category})
+}
+
+// UpdateCategoryHandler handles the request to rename, move or reorder a category
+func (h *CategoryHandler) UpdateCategoryHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseCategoryID(c)
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	var req RequestCategory
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Validation error: "+err.Error())
+	}
+
+	category, err := h.categoryService.UpdateCategory(ctx, req.toModel(id))
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": category})
+}
+
+// DeleteCategoryHandler handles the request to delete an empty category
+func (h *CategoryHandler) DeleteCategoryHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseCategoryID(c)
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	if err := h.categoryService.DeleteCategory(ctx, id); err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "category deleted"})
+}
+
+// MergeCategoryHandler handles the request to fold another category into this one
+func (h *CategoryHandler) MergeCategoryHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseCategoryID(c)
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	var req RequestMergeCategory
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Validation error: "+err.Error())
+	}
+
+	category, err := h.categoryService.MergeCategory(ctx, req.SourceID, id)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": category})
+}
+
+func (r RequestCategory) toModel(id uint) model.Category {
+	return model.Category{
+		ID:        id,
+		Name:      r.Name,
+		Slug:      r.Slug,
+		ParentID:  r.ParentID,
+		SortOrder: r.SortOrder,
+	}
+}
+
+func parseCategoryID(c echo.Context) (uint, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return uint(id), nil
+}
@@ -3,13 +3,16 @@ package customer
 import (
 	"errors"
 	"net/http"
+	"os"
 	"strings"
 	"time"
 
 	jwtConfig "go-online-store/config/jwt"
+	cartService "go-online-store/internal/domain/cart/service"
 	"go-online-store/internal/domain/customer/model"
 	"go-online-store/internal/domain/customer/service"
 	"go-online-store/internal/middleware/jwt"
+	"go-online-store/pkg/logger"
 	_ "go-online-store/server/cmd/docs"
 
 	"github.com/labstack/echo/v4"
@@ -17,14 +20,25 @@ import (
 
 type CustomerHandler struct {
 	customerService service.CustomerServiceImpl
+	cartService     cartService.CartServiceImpl
+	logger          *logger.Logger
 }
 
 func NewCustomerHandler(customerService service.CustomerServiceImpl) *CustomerHandler {
 	return &CustomerHandler{
 		customerService: customerService,
+		logger:          logger.NewLogger(os.Stdout, "Handler [Customer] :"),
 	}
 }
 
+// WithCartService returns a handler that merges the guest cart of the
+// visitor into their cart when they log in or register.
+func (h *CustomerHandler) WithCartService(carts cartService.CartServiceImpl) *CustomerHandler {
+	clone := *h
+	clone.cartService = carts
+	return &clone
+}
+
 // CustomerLogin handles customer login.
 // @Summary Login as a customer
 // @Description Login with credentials to get JWT token
@@ -59,6 +73,8 @@ func (h *CustomerHandler) CustomerLogin(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to generate JWT")
 	}
 
+	h.mergeGuestCart(c, customerAuth)
+
 	response := map[string]interface{}{
 		"token": token,
 		"data":  customerAuth,
@@ -115,9 +131,31 @@ func (h *CustomerHandler) CustomerRegister(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create user")
 	}
 
+	h.mergeGuestCart(c, createdUser)
+
 	response := map[string]interface{}{
 		"data": createdUser,
 	}
 
 	return c.JSON(http.StatusCreated, response)
 }
+
+// mergeGuestCart moves the cart the visitor built as a guest into the
+// customer's cart. Signing in still succeeds when the merge fails; the guest
+// cart is then kept for another attempt.
+func (h *CustomerHandler) mergeGuestCart(c echo.Context, customer *model.Customer) {
+	if h.cartService == nil {
+		return
+	}
+	guestID, ok := jwt.GuestFromRequest(c)
+	if !ok {
+		return
+	}
+
+	ctx := jwt.WithCustomer(c.Request().Context(), jwt.Customer{ID: customer.ID, Email: customer.Email})
+	if err := h.cartService.MergeGuestCart(ctx, guestID); err != nil {
+		h.logger.Error("Failed to merge guest cart: " + err.Error())
+		return
+	}
+	jwt.ClearGuestToken(c)
+}
@@ -0,0 +1,40 @@
+package inventory
+
+import (
+	"go-online-store/internal/domain/inventory/model"
+	"go-online-store/pkg/pagination"
+)
+
+type RequestStockAdjustment struct {
+	VariantID   *uint  `json:"variant_id"`
+	WarehouseID *uint  `json:"warehouse_id"`
+	Quantity    int    `json:"quantity" validate:"required"`
+	Reason      string `json:"reason" validate:"required,oneof=RESTOCK ADJUSTMENT RETURN"`
+	Reference   string `json:"reference" validate:"max=191"`
+	Note        string `json:"note" validate:"max=255"`
+}
+
+type RequestWarehouse struct {
+	Code       string `json:"code" validate:"required,max=32"`
+	Name       string `json:"name" validate:"required,max=191"`
+	City       string `json:"city" validate:"max=191"`
+	PostalCode string `json:"postal_code" validate:"max=16"`
+	Priority   int    `json:"priority"`
+	IsDefault  bool   `json:"is_default"`
+	Active     *bool  `json:"active"`
+}
+
+type RequestStockTransfer struct {
+	ProductID       uint   `json:"product_id" validate:"required"`
+	VariantID       *uint  `json:"variant_id"`
+	FromWarehouseID uint   `json:"from_warehouse_id" validate:"required"`
+	ToWarehouseID   uint   `json:"to_warehouse_id" validate:"required"`
+	Quantity        uint   `json:"quantity" validate:"required,gt=0"`
+	Reference       string `json:"reference" validate:"max=191"`
+	Note            string `json:"note" validate:"max=255"`
+}
+
+type StockHistoryResponse struct {
+	Data *model.StockHistory `json:"data"`
+	Meta pagination.Meta     `json:"meta"`
+}
@@ -0,0 +1,170 @@
+package inventory
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-online-store/internal/domain/inventory/model"
+	"go-online-store/internal/domain/inventory/service"
+	"go-online-store/pkg/errors"
+	"go-online-store/pkg/pagination"
+
+	"github.com/labstack/echo/v4"
+)
+
+type InventoryHandler struct {
+	inventoryService service.InventoryServiceImpl
+}
+
+func NewInventoryHandler(inventoryService service.InventoryServiceImpl) *InventoryHandler {
+	return &InventoryHandler{
+		inventoryService: inventoryService,
+	}
+}
+
+// @Summary Get stock history
+// @Tags Inventory
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param variant_id query int false "Only movements of this variant"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Success 200 {object} StockHistoryResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /v1/products/{id}/stock/history [get]
+
+// GetStockHistoryHandler handles the request to list the stock movements of a product
+func (h *InventoryHandler) GetStockHistoryHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	productID, err := parseID(c.Param("id"))
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	var variantID *uint
+	if raw := c.QueryParam("variant_id"); raw != "" {
+		id, err := parseID(raw)
+		if err != nil {
+			return errors.HTTPErrorHandler(errors.ErrBadRequest)
+		}
+		variantID = &id
+	}
+
+	var params pagination.Params
+	if params.Page, err = pagination.ParseInt(c.QueryParam("page"), 1); err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+	if params.Limit, err = pagination.ParseInt(c.QueryParam("limit"), pagination.DefaultLimit); err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+	params = params.Normalize()
+
+	history, err := h.inventoryService.GetStockHistory(ctx, productID, variantID, params)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	meta := pagination.Meta{
+		Total:      history.Total,
+		Limit:      params.Limit,
+		Page:       params.Page,
+		TotalPages: pagination.TotalPages(history.Total, params.Limit),
+	}
+	if params.Page < meta.TotalPages {
+		meta.Next = pagination.NextLink(c.Request().URL, map[string]string{"page": strconv.Itoa(params.Page + 1)})
+	}
+
+	return c.JSON(http.StatusOK, StockHistoryResponse{
+		Data: history,
+		Meta: meta,
+	})
+}
+
+// @Summary Adjust stock
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param request body RequestStockAdjustment true "Signed quantity and reason"
+// @Success 200 {object} StockHistoryResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /v1/products/{id}/stock [post]
+
+// AdjustStockHandler handles the request to record a restock, correction or return
+func (h *InventoryHandler) AdjustStockHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	productID, err := parseID(c.Param("id"))
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	var req RequestStockAdjustment
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Validation error: "+err.Error())
+	}
+
+	history, err := h.inventoryService.AdjustStock(ctx, productID, model.StockAdjustment{
+		VariantID:   req.VariantID,
+		WarehouseID: req.WarehouseID,
+		Quantity:    req.Quantity,
+		Reason:      req.Reason,
+		Reference:   req.Reference,
+		Note:        req.Note,
+	})
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": history})
+}
+
+// @Summary Reconcile stock
+// @Tags Inventory
+// @Produce json
+// @Success 200 {object} ReconcileReport
+// @Router /v1/inventory/reconcile [get]
+
+// GetReconcileHandler handles the request to compare stored stock with the ledger
+func (h *InventoryHandler) GetReconcileHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	report, err := h.inventoryService.Reconcile(ctx, false)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": report})
+}
+
+// @Summary Reconcile and fix stock
+// @Tags Inventory
+// @Produce json
+// @Success 200 {object} ReconcileReport
+// @Router /v1/inventory/reconcile [post]
+
+// ReconcileHandler handles the request to record adjustments that bring the
+// ledger back in line with stored stock
+func (h *InventoryHandler) ReconcileHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	report, err := h.inventoryService.Reconcile(ctx, true)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": report})
+}
+
+func parseID(raw string) (uint, error) {
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return uint(id), nil
+}
@@ -0,0 +1,213 @@
+package inventory
+
+import (
+	"net/http"
+
+	"go-online-store/internal/domain/inventory/model"
+	"go-online-store/internal/domain/inventory/service"
+	"go-online-store/pkg/errors"
+
+	"github.com/labstack/echo/v4"
+)
+
+type WarehouseHandler struct {
+	warehouseService service.WarehouseServiceImpl
+}
+
+func NewWarehouseHandler(warehouseService service.WarehouseServiceImpl) *WarehouseHandler {
+	return &WarehouseHandler{
+		warehouseService: warehouseService,
+	}
+}
+
+// @Summary List warehouses
+// @Tags Inventory
+// @Produce json
+// @Success 200 {array} model.Warehouse
+// @Router /v1/warehouses [get]
+
+// GetWarehousesHandler handles the request to list stock locations
+func (h *WarehouseHandler) GetWarehousesHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	warehouses, err := h.warehouseService.GetWarehouses(ctx)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": warehouses})
+}
+
+// @Summary Create warehouse
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Param request body RequestWarehouse true "Warehouse"
+// @Success 200 {object} model.Warehouse
+// @Failure 400 {object} ErrorResponse
+// @Router /v1/warehouses [post]
+
+// CreateWarehouseHandler handles the request to add a stock location
+func (h *WarehouseHandler) CreateWarehouseHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req RequestWarehouse
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Validation error: "+err.Error())
+	}
+
+	warehouse, err := h.warehouseService.CreateWarehouse(ctx, req.toModel(0))
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": warehouse})
+}
+
+// @Summary Update warehouse
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Param id path int true "Warehouse ID"
+// @Param request body RequestWarehouse true "Warehouse"
+// @Success 200 {object} model.Warehouse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /v1/warehouses/{id} [put]
+
+// UpdateWarehouseHandler handles the request to change a stock location
+func (h *WarehouseHandler) UpdateWarehouseHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	var req RequestWarehouse
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Validation error: "+err.Error())
+	}
+
+	warehouse, err := h.warehouseService.UpdateWarehouse(ctx, req.toModel(id))
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": warehouse})
+}
+
+// @Summary Get warehouse stock
+// @Tags Inventory
+// @Produce json
+// @Param id path int true "Warehouse ID"
+// @Success 200 {array} model.WarehouseStock
+// @Failure 404 {object} ErrorResponse
+// @Router /v1/warehouses/{id}/stock [get]
+
+// GetWarehouseStockHandler handles the request to list the stock held at a warehouse
+func (h *WarehouseHandler) GetWarehouseStockHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	stock, err := h.warehouseService.GetWarehouseStock(ctx, id)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": stock})
+}
+
+// @Summary Get product stock locations
+// @Tags Inventory
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {array} model.WarehouseStock
+// @Failure 404 {object} ErrorResponse
+// @Router /v1/products/{id}/stock/locations [get]
+
+// GetProductStockLocationsHandler handles the request to list where a product is stocked
+func (h *WarehouseHandler) GetProductStockLocationsHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	productID, err := parseID(c.Param("id"))
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	stock, err := h.warehouseService.GetProductStockLocations(ctx, productID)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": stock})
+}
+
+// @Summary Transfer stock
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Param request body RequestStockTransfer true "Product, warehouses and quantity"
+// @Success 200 {array} model.WarehouseStock
+// @Failure 400 {object} ErrorResponse
+// @Router /v1/warehouses/transfers [post]
+
+// TransferStockHandler handles the request to move stock between warehouses
+func (h *WarehouseHandler) TransferStockHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req RequestStockTransfer
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Validation error: "+err.Error())
+	}
+
+	stock, err := h.warehouseService.TransferStock(ctx, model.StockTransfer{
+		ProductID:       req.ProductID,
+		VariantID:       req.VariantID,
+		FromWarehouseID: req.FromWarehouseID,
+		ToWarehouseID:   req.ToWarehouseID,
+		Quantity:        req.Quantity,
+		Reference:       req.Reference,
+		Note:            req.Note,
+	})
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": stock})
+}
+
+// toModel converts the request to a warehouse; warehouses are active unless
+// stated otherwise.
+func (req RequestWarehouse) toModel(id uint) model.Warehouse {
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+	return model.Warehouse{
+		ID:         id,
+		Code:       req.Code,
+		Name:       req.Name,
+		City:       req.City,
+		PostalCode: req.PostalCode,
+		Priority:   req.Priority,
+		IsDefault:  req.IsDefault,
+		Active:     active,
+	}
+}
@@ -0,0 +1,6 @@
+package notification
+
+type RequestSubscription struct {
+	Kind        string   `json:"kind" validate:"required,oneof=BACK_IN_STOCK PRICE_DROP"`
+	TargetPrice *float64 `json:"target_price" validate:"omitempty,gt=0"`
+}
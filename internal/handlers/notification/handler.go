@@ -0,0 +1,110 @@
+package notification
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-online-store/internal/domain/notification/service"
+	"go-online-store/pkg/errors"
+
+	"github.com/labstack/echo/v4"
+)
+
+type NotificationHandler struct {
+	notificationService service.NotificationServiceImpl
+}
+
+func NewNotificationHandler(notificationService service.NotificationServiceImpl) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+// @Summary Subscribe to product notifications
+// @Tags Notification
+// @Description Get notified once when an out-of-stock product is back in stock, or when its price drops to target_price
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param request body RequestSubscription true "BACK_IN_STOCK, or PRICE_DROP with a target price"
+// @Success 201 {object} model.Subscription
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /v1/products/{id}/subscriptions [post]
+
+// SubscribeHandler handles the request to subscribe to a product
+func (h *NotificationHandler) SubscribeHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	productID, err := parseID(c.Param("id"))
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	var req RequestSubscription
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Validation error: "+err.Error())
+	}
+
+	subscription, err := h.notificationService.Subscribe(ctx, productID, req.Kind, req.TargetPrice)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{"data": subscription})
+}
+
+// @Summary List subscriptions
+// @Tags Notification
+// @Produce json
+// @Success 200 {array} model.Subscription
+// @Failure 401 {object} ErrorResponse
+// @Router /v1/subscriptions [get]
+
+// GetSubscriptionsHandler handles the request to list the customer's subscriptions
+func (h *NotificationHandler) GetSubscriptionsHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	subscriptions, err := h.notificationService.GetSubscriptions(ctx)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": subscriptions})
+}
+
+// @Summary Unsubscribe
+// @Tags Notification
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} ErrorResponse
+// @Router /v1/subscriptions/{id} [delete]
+
+// UnsubscribeHandler handles the removal of a subscription
+func (h *NotificationHandler) UnsubscribeHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	if err := h.notificationService.Unsubscribe(ctx, id); err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "subscription removed"})
+}
+
+func parseID(raw string) (uint, error) {
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return uint(id), nil
+}
@@ -0,0 +1,25 @@
+package order
+
+import (
+	"go-online-store/internal/domain/order/model"
+	"go-online-store/pkg/pagination"
+)
+
+type RequestCheckout struct {
+	DiscountCode string `json:"discount_code" validate:"max=32"`
+}
+
+type RequestOrderStatus struct {
+	Status string `json:"status" validate:"required,oneof=PROCESSING SHIPPED DELIVERED"`
+	Note   string `json:"note" validate:"max=255"`
+}
+
+type RequestCancelOrder struct {
+	Reason string `json:"reason" validate:"required,oneof=CHANGED_MIND ORDERED_BY_MISTAKE FOUND_CHEAPER DELIVERY_TOO_SLOW OUT_OF_STOCK PAYMENT_ISSUE FRAUD_SUSPECTED OTHER"`
+	Note   string `json:"note" validate:"max=255"`
+}
+
+type OrderListResponse struct {
+	Data []model.Order   `json:"data"`
+	Meta pagination.Meta `json:"meta"`
+}
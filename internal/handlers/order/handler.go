@@ -1,10 +1,16 @@
 package order
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
+	cartModel "go-online-store/internal/domain/cart/model"
+	"go-online-store/internal/domain/order/model"
 	"go-online-store/internal/domain/order/service"
+	customErrors "go-online-store/pkg/errors"
+	"go-online-store/pkg/pagination"
 
 	"github.com/labstack/echo/v4"
 )
@@ -22,9 +28,27 @@ func NewOrderHandler(orderService service.OrderServiceImpl) *OrderHandler {
 func (h *OrderHandler) CheckoutHandler(c echo.Context) error {
 	ctx := c.Request().Context()
 
-	order, err := h.orderService.Checkout(ctx)
+	// The body is optional; it only carries a discount code
+	var req RequestCheckout
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Validation error: "+err.Error())
+	}
+
+	order, err := h.orderService.Checkout(ctx, req.DiscountCode)
+	var changed *cartModel.ChangedError
+	if errors.As(err, &changed) {
+		// Show what changed so the customer can confirm or adjust the cart
+		return c.JSON(http.StatusConflict, map[string]interface{}{
+			"message":  changed.Error(),
+			"warnings": changed.Warnings,
+		})
+	}
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		return customErrors.HTTPErrorHandler(err)
 	}
 
 	return c.JSON(http.StatusOK, order)
@@ -39,8 +63,160 @@ func (h *OrderHandler) TransactionPaidHandler(c echo.Context) error {
 
 	err := h.orderService.UpdatePaymentStatus(ctx, uint(id))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		return customErrors.HTTPErrorHandler(err)
 	}
 
 	return c.JSON(http.StatusOK, "Checkout process completed successfully")
 }
+
+// GetOrdersHandler handles the request to list the customer's orders. The
+// optional from and to dates (YYYY-MM-DD) are both inclusive.
+func (h *OrderHandler) GetOrdersHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	query, err := parseOrderQuery(c)
+	if err != nil {
+		return customErrors.HTTPErrorHandler(customErrors.ErrBadRequest)
+	}
+
+	page, err := h.orderService.GetOrders(ctx, query)
+	if err != nil {
+		return customErrors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, orderListResponse(c, query, page))
+}
+
+// GetOrderHandler handles the request to get one of the customer's orders
+// with its items, transactions and status history
+func (h *OrderHandler) GetOrderHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		return customErrors.HTTPErrorHandler(customErrors.ErrBadRequest)
+	}
+
+	order, err := h.orderService.GetOrder(ctx, id)
+	if err != nil {
+		return customErrors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": order})
+}
+
+// UpdateOrderStatusHandler handles the request to move an order along its
+// lifecycle, e.g. to shipped
+func (h *OrderHandler) UpdateOrderStatusHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		return customErrors.HTTPErrorHandler(customErrors.ErrBadRequest)
+	}
+
+	var req RequestOrderStatus
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Validation error: "+err.Error())
+	}
+
+	order, err := h.orderService.UpdateOrderStatus(ctx, id, req.Status, req.Note)
+	if err != nil {
+		return customErrors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": order})
+}
+
+// CancelOrderHandler handles the request to cancel an order
+func (h *OrderHandler) CancelOrderHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		return customErrors.HTTPErrorHandler(customErrors.ErrBadRequest)
+	}
+
+	var req RequestCancelOrder
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Validation error: "+err.Error())
+	}
+
+	order, err := h.orderService.CancelOrder(ctx, id, req.Reason, req.Note)
+	if err != nil {
+		return customErrors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": order})
+}
+
+func parseOrderQuery(c echo.Context) (model.OrderQuery, error) {
+	query := model.OrderQuery{Status: c.QueryParam("status")}
+
+	if raw := c.QueryParam("from"); raw != "" {
+		from, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			return query, err
+		}
+		query.From = &from
+	}
+	if raw := c.QueryParam("to"); raw != "" {
+		to, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			return query, err
+		}
+		// Include the whole last day
+		to = to.AddDate(0, 0, 1)
+		query.To = &to
+	}
+
+	page, err := pagination.ParseInt(c.QueryParam("page"), 1)
+	if err != nil {
+		return query, err
+	}
+	limit, err := pagination.ParseInt(c.QueryParam("limit"), pagination.DefaultLimit)
+	if err != nil {
+		return query, err
+	}
+	query.Pagination = pagination.Params{Page: page, Limit: limit}
+
+	return query, nil
+}
+
+func orderListResponse(c echo.Context, query model.OrderQuery, page *model.OrderPage) OrderListResponse {
+	params := query.Pagination.Normalize()
+	meta := pagination.Meta{
+		Total:      page.Total,
+		Limit:      params.Limit,
+		Page:       params.Page,
+		TotalPages: pagination.TotalPages(page.Total, params.Limit),
+	}
+	if params.Page < meta.TotalPages {
+		meta.Next = pagination.NextLink(c.Request().URL, map[string]string{"page": strconv.Itoa(params.Page + 1)})
+	}
+
+	orders := page.Orders
+	if orders == nil {
+		orders = []model.Order{}
+	}
+	return OrderListResponse{
+		Data: orders,
+		Meta: meta,
+	}
+}
+
+func parseID(raw string) (uint, error) {
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return uint(id), nil
+}
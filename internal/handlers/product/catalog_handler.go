@@ -0,0 +1,118 @@
+package product
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-online-store/internal/domain/product/catalog"
+	"go-online-store/internal/domain/product/service"
+	"go-online-store/pkg/errors"
+
+	"github.com/labstack/echo/v4"
+)
+
+// @Summary Import products
+// @Tags Product
+// @Description Upsert products from a CSV or JSON Lines file, keyed by SKU or name. The file is sent as the multipart field "file" or as the request body.
+// @Accept multipart/form-data
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Produce json
+// @Param file formData file false "CSV or JSON Lines file"
+// @Param format query string false "csv or jsonl; defaults to the file extension or content type"
+// @Param dry_run query bool false "Validate every row without saving"
+// @Success 200 {object} catalog.ImportReport
+// @Failure 400 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Failure 415 {object} ErrorResponse
+// @Router /v1/products/import [post]
+
+// ImportProductsHandler handles the bulk upsert of products from a file
+func (h *ProductHandler) ImportProductsHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	dryRun := false
+	if raw := c.QueryParam("dry_run"); raw != "" {
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return errors.HTTPErrorHandler(errors.ErrBadRequest)
+		}
+		dryRun = value
+	}
+
+	var body io.Reader
+	formatHint := c.QueryParam("format")
+	if fileHeader, err := c.FormFile("file"); err == nil {
+		if fileHeader.Size > service.MaxImportSize {
+			return errors.HTTPErrorHandler(errors.ErrMediaTooLarge)
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			return errors.HTTPErrorHandler(errors.ErrBadRequest)
+		}
+		defer file.Close()
+
+		body = file
+		if formatHint == "" {
+			formatHint = fileHeader.Filename
+		}
+	} else {
+		if c.Request().ContentLength > service.MaxImportSize {
+			return errors.HTTPErrorHandler(errors.ErrMediaTooLarge)
+		}
+		body = http.MaxBytesReader(c.Response(), c.Request().Body, service.MaxImportSize)
+		if formatHint == "" {
+			formatHint = c.Request().Header.Get(echo.HeaderContentType)
+		}
+	}
+
+	format, err := catalog.ParseFormat(formatHint)
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrUnsupportedMedia)
+	}
+
+	report, err := h.productService.ImportProducts(ctx, format, body, dryRun)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": report})
+}
+
+// @Summary Export products
+// @Tags Product
+// @Description Stream the whole catalog as CSV or JSON Lines
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param format query string false "csv (default) or jsonl"
+// @Success 200 {file} file
+// @Failure 415 {object} ErrorResponse
+// @Router /v1/products/export [get]
+
+// ExportProductsHandler handles the download of the whole catalog
+func (h *ProductHandler) ExportProductsHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	format := catalog.FormatCSV
+	if raw := c.QueryParam("format"); raw != "" {
+		parsed, err := catalog.ParseFormat(raw)
+		if err != nil {
+			return errors.HTTPErrorHandler(errors.ErrUnsupportedMedia)
+		}
+		format = parsed
+	}
+
+	filename := "products-" + time.Now().Format("20060102") + "." + format
+	response := c.Response()
+	response.Header().Set(echo.HeaderContentType, catalog.ContentType(format))
+	response.Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+	response.WriteHeader(http.StatusOK)
+
+	// The status is already sent, so a failure can only cut the file short
+	if _, err := h.productService.ExportProducts(ctx, format, response); err != nil {
+		c.Logger().Error("Failed to export products: " + err.Error())
+	}
+	return nil
+}
@@ -1,8 +1,64 @@
 package product
 
+import (
+	"go-online-store/internal/domain/product/model"
+	"go-online-store/internal/domain/product/search"
+	"go-online-store/pkg/pagination"
+)
+
 type RequestProduct struct {
-	Name     string  `json:"name"`
-	Category string  `json:"category"`
-	Price    float64 `json:"price"`
-	Stok     uint    `json:"stok"`
+	SKU           *string `json:"sku" validate:"omitempty,max=64"`
+	Name          string  `json:"name" validate:"required"`
+	CategoryID    *uint   `json:"category_id"`
+	Category      string  `json:"category" validate:"required_without=CategoryID"`
+	Description   string  `json:"description"`
+	Price         float64 `json:"price" validate:"gte=0"`
+	Stok          uint    `json:"stok"`
+	PurchaseLimit uint    `json:"purchase_limit"`
+}
+
+// RequestPatchProduct carries a partial update; nil fields are left untouched.
+type RequestPatchProduct struct {
+	SKU           *string  `json:"sku" validate:"omitempty,max=64"`
+	Name          *string  `json:"name" validate:"omitempty,min=1"`
+	CategoryID    *uint    `json:"category_id"`
+	Category      *string  `json:"category" validate:"omitempty,min=1"`
+	Description   *string  `json:"description"`
+	Price         *float64 `json:"price" validate:"omitempty,gte=0"`
+	Stok          *uint    `json:"stok"`
+	PurchaseLimit *uint    `json:"purchase_limit"`
+}
+
+type ProductListResponse struct {
+	Data []*model.Product `json:"data"`
+	Meta pagination.Meta  `json:"meta"`
+}
+
+type ProductSearchResponse struct {
+	Data []search.Result `json:"data"`
+}
+
+type RequestProductOption struct {
+	Name   string   `json:"name" validate:"required"`
+	Values []string `json:"values" validate:"required,min=1,dive,required"`
+}
+
+type RequestProductOptions struct {
+	Options []RequestProductOption `json:"options" validate:"dive"`
+}
+
+type RequestVariant struct {
+	SKU     string            `json:"sku" validate:"required,max=64"`
+	Barcode string            `json:"barcode" validate:"max=64"`
+	Options map[string]string `json:"options"`
+	Price   float64           `json:"price" validate:"gte=0"`
+	Stok    uint              `json:"stok"`
+}
+
+type RequestMediaAltText struct {
+	AltText string `json:"alt_text" validate:"max=255"`
+}
+
+type RequestMediaOrder struct {
+	MediaIDs []uint `json:"media_ids" validate:"required"`
 }
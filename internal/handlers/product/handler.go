@@ -2,10 +2,12 @@ package product
 
 import (
 	"net/http"
+	"strconv"
 
 	"go-online-store/internal/domain/product/model"
 	"go-online-store/internal/domain/product/service"
 	"go-online-store/pkg/errors"
+	"go-online-store/pkg/pagination"
 
 	"github.com/labstack/echo/v4"
 )
@@ -20,31 +22,113 @@ func NewProductHandler(productService service.ProductServiceImpl) *ProductHandle
 	}
 }
 
-// @Summary Get products by category
+// @Summary List products
 // @Tags Product
-// @Description Retrieve a list of products filtered by category
+// @Description Retrieve a filtered, sorted and paginated list of products
 // @Produce json
-// @Param category query string false "Category filter"
-// @Success 200 {object} []Product
+// @Param category query string false "Category slug or name, includes subcategories"
+// @Param category_id query int false "Category ID, includes subcategories"
+// @Param min_price query number false "Minimum price"
+// @Param max_price query number false "Maximum price"
+// @Param in_stock query bool false "Only products with stock"
+// @Param name query string false "Name contains"
+// @Param sort query string false "price, -price, name, -name or newest"
+// @Param page query int false "Page number (offset pagination)"
+// @Param limit query int false "Page size"
+// @Param cursor query string false "Cursor (cursor pagination)"
+// @Success 200 {object} ProductListResponse
 // @Failure 400 {object} ErrorResponse
 // @Router /v1/products [get]
 
-// GetProductsByCategoryHandler handles the request to fetch products by category
-func (h *ProductHandler) GetProductsByCategoryHandler(c echo.Context) error {
+// GetProductsHandler handles the request to list products.
+// Passing a cursor parameter (empty for the first page) switches to cursor pagination.
+func (h *ProductHandler) GetProductsHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	query, err := parseProductQuery(c)
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	page, err := h.productService.GetProductPage(ctx, query)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	params := query.Pagination.Normalize()
+	meta := pagination.Meta{
+		Total: page.Total,
+		Limit: params.Limit,
+	}
+	if params.UseCursor {
+		if page.NextCursor != "" {
+			meta.NextCursor = page.NextCursor
+			meta.Next = pagination.NextLink(c.Request().URL, map[string]string{"cursor": page.NextCursor})
+		}
+	} else {
+		meta.Page = params.Page
+		meta.TotalPages = pagination.TotalPages(page.Total, params.Limit)
+		if params.Page < meta.TotalPages {
+			meta.Next = pagination.NextLink(c.Request().URL, map[string]string{"page": strconv.Itoa(params.Page + 1)})
+		}
+	}
+
+	return c.JSON(http.StatusOK, ProductListResponse{
+		Data: page.Products,
+		Meta: meta,
+	})
+}
+
+// @Summary Search products
+// @Tags Product
+// @Description Full-text, typo-tolerant search over name, category and description
+// @Produce json
+// @Param q query string true "Search terms"
+// @Param limit query int false "Maximum number of results"
+// @Success 200 {object} ProductSearchResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /v1/products/search [get]
 
+// SearchProductsHandler handles the request to search products
+func (h *ProductHandler) SearchProductsHandler(c echo.Context) error {
 	ctx := c.Request().Context()
 
-	category := c.QueryParam("category")
-	if category == "" {
+	limit, err := pagination.ParseInt(c.QueryParam("limit"), 0)
+	if err != nil {
 		return errors.HTTPErrorHandler(errors.ErrBadRequest)
 	}
 
-	products, err := h.productService.GetProductListByCategory(ctx, category)
+	results, err := h.productService.SearchProducts(ctx, c.QueryParam("q"), limit)
 	if err != nil {
 		return errors.HTTPErrorHandler(err)
 	}
 
-	return c.JSON(http.StatusOK, products)
+	return c.JSON(http.StatusOK, ProductSearchResponse{Data: results})
+}
+
+// @Summary Get product by ID
+// @Tags Product
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {object} Product
+// @Failure 404 {object} ErrorResponse
+// @Router /v1/products/{id} [get]
+
+// GetProductByIdHandler handles the request to fetch a single product
+func (h *ProductHandler) GetProductByIdHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseProductID(c)
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	product, err := h.productService.GetProductById(ctx, id)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": product})
 }
 
 func (h *ProductHandler) CreateProduct(c echo.Context) error {
@@ -56,14 +140,25 @@ func (h *ProductHandler) CreateProduct(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
 	}
 
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Validation error: "+err.Error())
+	}
+
 	newProduct := model.Product{
-		Name:     req.Name,
-		Category: req.Category,
-		Price:    req.Price,
-		Stok:     uint(req.Price),
+		SKU:           req.SKU,
+		Name:          req.Name,
+		CategoryID:    req.CategoryID,
+		Category:      req.Category,
+		Description:   req.Description,
+		Price:         req.Price,
+		Stok:          req.Stok,
+		PurchaseLimit: req.PurchaseLimit,
 	}
 
 	product, err := h.productService.CreateProduct(ctx, newProduct)
+	if err == errors.ErrInvalidCategory || err == errors.ErrDuplicateSKU {
+		return errors.HTTPErrorHandler(err)
+	}
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create product")
 	}
@@ -74,3 +169,174 @@ func (h *ProductHandler) CreateProduct(c echo.Context) error {
 
 	return c.JSON(http.StatusCreated, response)
 }
+
+// UpdateProduct handles the full replacement of a product (PUT)
+func (h *ProductHandler) UpdateProduct(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseProductID(c)
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	var req RequestProduct
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Validation error: "+err.Error())
+	}
+
+	product, err := h.productService.UpdateProduct(ctx, model.Product{
+		ID:            id,
+		SKU:           req.SKU,
+		Name:          req.Name,
+		CategoryID:    req.CategoryID,
+		Category:      req.Category,
+		Description:   req.Description,
+		Price:         req.Price,
+		Stok:          req.Stok,
+		PurchaseLimit: req.PurchaseLimit,
+	})
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": product})
+}
+
+// PatchProduct handles a partial update of a product (PATCH)
+func (h *ProductHandler) PatchProduct(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseProductID(c)
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	var req RequestPatchProduct
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Validation error: "+err.Error())
+	}
+
+	existing, err := h.productService.GetProductById(ctx, id)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	if req.SKU != nil {
+		existing.SKU = req.SKU
+	}
+	if req.Name != nil {
+		existing.Name = *req.Name
+	}
+	if req.Category != nil {
+		existing.Category = *req.Category
+		existing.CategoryID = nil
+	}
+	if req.CategoryID != nil {
+		existing.CategoryID = req.CategoryID
+	}
+	if req.Description != nil {
+		existing.Description = *req.Description
+	}
+	if req.Price != nil {
+		existing.Price = *req.Price
+	}
+	if req.Stok != nil {
+		existing.Stok = *req.Stok
+	}
+	if req.PurchaseLimit != nil {
+		existing.PurchaseLimit = *req.PurchaseLimit
+	}
+
+	product, err := h.productService.UpdateProduct(ctx, *existing)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": product})
+}
+
+// DeleteProduct handles the removal of a product
+func (h *ProductHandler) DeleteProduct(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseProductID(c)
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	if err := h.productService.DeleteProduct(ctx, id); err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "product deleted"})
+}
+
+func parseProductQuery(c echo.Context) (model.ProductQuery, error) {
+	query := model.ProductQuery{
+		Category: c.QueryParam("category"),
+		Name:     c.QueryParam("name"),
+		Sort:     c.QueryParam("sort"),
+	}
+
+	if v := c.QueryParam("category_id"); v != "" {
+		categoryID, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return query, err
+		}
+		query.CategoryID = uint(categoryID)
+	}
+	if v := c.QueryParam("min_price"); v != "" {
+		minPrice, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return query, err
+		}
+		query.MinPrice = &minPrice
+	}
+	if v := c.QueryParam("max_price"); v != "" {
+		maxPrice, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return query, err
+		}
+		query.MaxPrice = &maxPrice
+	}
+	if v := c.QueryParam("in_stock"); v != "" {
+		inStock, err := strconv.ParseBool(v)
+		if err != nil {
+			return query, err
+		}
+		query.InStock = inStock
+	}
+
+	page, err := pagination.ParseInt(c.QueryParam("page"), 1)
+	if err != nil {
+		return query, err
+	}
+	limit, err := pagination.ParseInt(c.QueryParam("limit"), pagination.DefaultLimit)
+	if err != nil {
+		return query, err
+	}
+	query.Pagination = pagination.Params{
+		Page:      page,
+		Limit:     limit,
+		Cursor:    c.QueryParam("cursor"),
+		UseCursor: c.QueryParams().Has("cursor"),
+	}
+
+	return query, nil
+}
+
+func parseProductID(c echo.Context) (uint, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return uint(id), nil
+}
@@ -0,0 +1,175 @@
+package product
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"go-online-store/internal/domain/product/service"
+	"go-online-store/pkg/errors"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ProductMediaHandler struct {
+	mediaService service.MediaServiceImpl
+}
+
+func NewProductMediaHandler(mediaService service.MediaServiceImpl) *ProductMediaHandler {
+	return &ProductMediaHandler{
+		mediaService: mediaService,
+	}
+}
+
+// @Summary List product media
+// @Tags Product
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {object} []ProductMedia
+// @Failure 404 {object} ErrorResponse
+// @Router /v1/products/{id}/media [get]
+
+// GetMediaHandler handles the request to list the images of a product
+func (h *ProductMediaHandler) GetMediaHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseProductID(c)
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	items, err := h.mediaService.GetProductMedia(ctx, id)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": items})
+}
+
+// @Summary Upload product image
+// @Tags Product
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param file formData file true "JPEG, PNG or GIF image"
+// @Param alt_text formData string false "Alternative text"
+// @Success 201 {object} ProductMedia
+// @Failure 400 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Failure 415 {object} ErrorResponse
+// @Router /v1/products/{id}/media [post]
+
+// UploadMediaHandler handles the upload of a product image
+func (h *ProductMediaHandler) UploadMediaHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseProductID(c)
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+	if fileHeader.Size > service.MaxMediaSize {
+		return errors.HTTPErrorHandler(errors.ErrMediaTooLarge)
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+	defer file.Close()
+
+	// Read one byte past the limit so oversized bodies are detected
+	data, err := io.ReadAll(io.LimitReader(file, service.MaxMediaSize+1))
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	item, err := h.mediaService.UploadMedia(ctx, id, data, c.FormValue("alt_text"))
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{"data": item})
+}
+
+// UpdateMediaHandler handles the request to change the alt text of an image
+func (h *ProductMediaHandler) UpdateMediaHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseProductID(c)
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	mediaId, err := strconv.ParseUint(c.Param("mediaId"), 10, 64)
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	var req RequestMediaAltText
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Validation error: "+err.Error())
+	}
+
+	item, err := h.mediaService.UpdateMediaAltText(ctx, id, uint(mediaId), req.AltText)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": item})
+}
+
+// ReorderMediaHandler handles the request to change the display order of images
+func (h *ProductMediaHandler) ReorderMediaHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseProductID(c)
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	var req RequestMediaOrder
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Validation error: "+err.Error())
+	}
+
+	items, err := h.mediaService.ReorderMedia(ctx, id, req.MediaIDs)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": items})
+}
+
+// DeleteMediaHandler handles the removal of a product image
+func (h *ProductMediaHandler) DeleteMediaHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseProductID(c)
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	mediaId, err := strconv.ParseUint(c.Param("mediaId"), 10, 64)
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	if err := h.mediaService.DeleteMedia(ctx, id, uint(mediaId)); err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "media deleted"})
+}
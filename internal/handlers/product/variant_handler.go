@@ -0,0 +1,170 @@
+package product
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-online-store/internal/domain/product/model"
+	"go-online-store/pkg/errors"
+
+	"github.com/labstack/echo/v4"
+)
+
+// @Summary Set product options
+// @Tags Product
+// @Description Replace the option axes (size, color, ...) of a product
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param input body RequestProductOptions true "Option axes"
+// @Success 200 {object} Product
+// @Failure 400 {object} ErrorResponse
+// @Router /v1/products/{id}/options [put]
+
+// SetProductOptionsHandler handles the request to replace a product's option axes
+func (h *ProductHandler) SetProductOptionsHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseProductID(c)
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	var req RequestProductOptions
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Validation error: "+err.Error())
+	}
+
+	options := make([]model.ProductOption, 0, len(req.Options))
+	for _, option := range req.Options {
+		options = append(options, model.ProductOption{
+			Name:   option.Name,
+			Values: option.Values,
+		})
+	}
+
+	product, err := h.productService.SetProductOptions(ctx, id, options)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": product})
+}
+
+// @Summary List product variants
+// @Tags Product
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {object} []ProductVariant
+// @Failure 404 {object} ErrorResponse
+// @Router /v1/products/{id}/variants [get]
+
+// GetVariantsHandler handles the request to list the SKUs of a product
+func (h *ProductHandler) GetVariantsHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseProductID(c)
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	variants, err := h.productService.GetProductVariants(ctx, id)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": variants})
+}
+
+// CreateVariantHandler handles the request to add a SKU to a product
+func (h *ProductHandler) CreateVariantHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseProductID(c)
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	var req RequestVariant
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Validation error: "+err.Error())
+	}
+
+	variant, err := h.productService.CreateVariant(ctx, id, req.toModel(0))
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{"data": variant})
+}
+
+// UpdateVariantHandler handles the request to replace a SKU
+func (h *ProductHandler) UpdateVariantHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseProductID(c)
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	variantId, err := strconv.ParseUint(c.Param("variantId"), 10, 64)
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	var req RequestVariant
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Validation error: "+err.Error())
+	}
+
+	variant, err := h.productService.UpdateVariant(ctx, id, req.toModel(uint(variantId)))
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": variant})
+}
+
+// DeleteVariantHandler handles the request to remove a SKU
+func (h *ProductHandler) DeleteVariantHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseProductID(c)
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	variantId, err := strconv.ParseUint(c.Param("variantId"), 10, 64)
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	if err := h.productService.DeleteVariant(ctx, id, uint(variantId)); err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "variant deleted"})
+}
+
+func (r RequestVariant) toModel(id uint) model.ProductVariant {
+	return model.ProductVariant{
+		ID:      id,
+		SKU:     r.SKU,
+		Barcode: r.Barcode,
+		Options: r.Options,
+		Price:   r.Price,
+		Stok:    r.Stok,
+	}
+}
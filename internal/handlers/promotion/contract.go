@@ -0,0 +1,49 @@
+package promotion
+
+import (
+	"time"
+
+	"go-online-store/internal/domain/promotion/model"
+)
+
+type RequestPromotion struct {
+	Name             string     `json:"name" validate:"required,max=191"`
+	Code             string     `json:"code" validate:"max=32"`
+	Type             string     `json:"type" validate:"required,oneof=PERCENTAGE FIXED FREE_SHIPPING BUY_X_GET_Y"`
+	Value            float64    `json:"value" validate:"min=0"`
+	BuyQuantity      uint       `json:"buy_quantity"`
+	GetQuantity      uint       `json:"get_quantity"`
+	CategoryID       *uint      `json:"category_id"`
+	MinSpend         float64    `json:"min_spend" validate:"min=0"`
+	StartsAt         *time.Time `json:"starts_at"`
+	EndsAt           *time.Time `json:"ends_at"`
+	UsageLimit       uint       `json:"usage_limit"`
+	PerCustomerLimit uint       `json:"per_customer_limit"`
+	Stackable        bool       `json:"stackable"`
+	// Active defaults to true
+	Active *bool `json:"active"`
+}
+
+func (r RequestPromotion) toModel(id uint) model.Promotion {
+	promotion := model.Promotion{
+		ID:               id,
+		Name:             r.Name,
+		Type:             r.Type,
+		Value:            r.Value,
+		BuyQuantity:      r.BuyQuantity,
+		GetQuantity:      r.GetQuantity,
+		CategoryID:       r.CategoryID,
+		MinSpend:         r.MinSpend,
+		StartsAt:         r.StartsAt,
+		EndsAt:           r.EndsAt,
+		UsageLimit:       r.UsageLimit,
+		PerCustomerLimit: r.PerCustomerLimit,
+		Stackable:        r.Stackable,
+		Active:           r.Active == nil || *r.Active,
+	}
+	if r.Code != "" {
+		code := r.Code
+		promotion.Code = &code
+	}
+	return promotion
+}
@@ -0,0 +1,107 @@
+package promotion
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-online-store/internal/domain/promotion/service"
+	"go-online-store/pkg/errors"
+
+	"github.com/labstack/echo/v4"
+)
+
+type PromotionHandler struct {
+	promotionService service.PromotionServiceImpl
+}
+
+func NewPromotionHandler(promotionService service.PromotionServiceImpl) *PromotionHandler {
+	return &PromotionHandler{
+		promotionService: promotionService,
+	}
+}
+
+// GetPromotionsHandler handles the request to list every promotion
+func (h *PromotionHandler) GetPromotionsHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	promotions, err := h.promotionService.GetPromotions(ctx)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": promotions})
+}
+
+// GetPromotionHandler handles the request to fetch a promotion
+func (h *PromotionHandler) GetPromotionHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	promotion, err := h.promotionService.GetPromotion(ctx, id)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": promotion})
+}
+
+// CreatePromotionHandler handles the request to create a coupon or an
+// automatic promotion
+func (h *PromotionHandler) CreatePromotionHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req RequestPromotion
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Validation error: "+err.Error())
+	}
+
+	promotion, err := h.promotionService.CreatePromotion(ctx, req.toModel(0))
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{"data": promotion})
+}
+
+// UpdatePromotionHandler handles the request to change, or switch off, a
+// promotion
+func (h *PromotionHandler) UpdatePromotionHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	var req RequestPromotion
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Validation error: "+err.Error())
+	}
+
+	promotion, err := h.promotionService.UpdatePromotion(ctx, req.toModel(id))
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": promotion})
+}
+
+func parseID(raw string) (uint, error) {
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return uint(id), nil
+}
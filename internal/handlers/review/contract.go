@@ -0,0 +1,26 @@
+package review
+
+import (
+	"go-online-store/internal/domain/review/model"
+	"go-online-store/pkg/pagination"
+)
+
+type RequestReview struct {
+	Rating uint   `json:"rating" validate:"required,min=1,max=5"`
+	Title  string `json:"title" validate:"max=191"`
+	Body   string `json:"body" validate:"required,max=5000"`
+}
+
+type RequestReviewVote struct {
+	Helpful *bool `json:"helpful" validate:"required"`
+}
+
+type RequestModeration struct {
+	Status string `json:"status" validate:"required,oneof=APPROVED REJECTED"`
+	Note   string `json:"note" validate:"max=255"`
+}
+
+type ReviewListResponse struct {
+	Data []model.Review  `json:"data"`
+	Meta pagination.Meta `json:"meta"`
+}
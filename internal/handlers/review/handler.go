@@ -0,0 +1,320 @@
+package review
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-online-store/internal/domain/review/model"
+	"go-online-store/internal/domain/review/service"
+	"go-online-store/pkg/errors"
+	"go-online-store/pkg/pagination"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ReviewHandler struct {
+	reviewService service.ReviewServiceImpl
+}
+
+func NewReviewHandler(reviewService service.ReviewServiceImpl) *ReviewHandler {
+	return &ReviewHandler{
+		reviewService: reviewService,
+	}
+}
+
+// @Summary List product reviews
+// @Tags Review
+// @Description Approved reviews of a product, most helpful first by default
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param sort query string false "helpful, newest, rating or -rating"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Success 200 {object} ReviewListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /v1/products/{id}/reviews [get]
+
+// GetProductReviewsHandler handles the request to list the reviews of a product
+func (h *ReviewHandler) GetProductReviewsHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	productID, err := parseID(c.Param("id"))
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	query, err := parseReviewQuery(c)
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	page, err := h.reviewService.GetProductReviews(ctx, productID, query)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, reviewListResponse(c, query, page))
+}
+
+// @Summary Review a product
+// @Tags Review
+// @Description Rate a product bought in a paid order; the review is shown once approved
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param request body RequestReview true "Rating and text"
+// @Success 201 {object} model.Review
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /v1/products/{id}/reviews [post]
+
+// CreateReviewHandler handles the request to review a product
+func (h *ReviewHandler) CreateReviewHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	productID, err := parseID(c.Param("id"))
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	var req RequestReview
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Validation error: "+err.Error())
+	}
+
+	review, err := h.reviewService.CreateReview(ctx, productID, model.Review{
+		Rating: req.Rating,
+		Title:  req.Title,
+		Body:   req.Body,
+	})
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{"data": review})
+}
+
+// @Summary Update own review
+// @Tags Review
+// @Accept json
+// @Produce json
+// @Param id path int true "Review ID"
+// @Param request body RequestReview true "Rating and text"
+// @Success 200 {object} model.Review
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /v1/reviews/{id} [put]
+
+// UpdateReviewHandler handles the request to edit the customer's own review
+func (h *ReviewHandler) UpdateReviewHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	var req RequestReview
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Validation error: "+err.Error())
+	}
+
+	review, err := h.reviewService.UpdateReview(ctx, model.Review{
+		ID:     id,
+		Rating: req.Rating,
+		Title:  req.Title,
+		Body:   req.Body,
+	})
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": review})
+}
+
+// @Summary Delete review
+// @Tags Review
+// @Produce json
+// @Param id path int true "Review ID"
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /v1/reviews/{id} [delete]
+
+// DeleteReviewHandler handles the removal of a review by its author or an admin
+func (h *ReviewHandler) DeleteReviewHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	if err := h.reviewService.DeleteReview(ctx, id); err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "review deleted"})
+}
+
+// @Summary Vote on review helpfulness
+// @Tags Review
+// @Accept json
+// @Produce json
+// @Param id path int true "Review ID"
+// @Param request body RequestReviewVote true "Whether the review was helpful"
+// @Success 200 {object} model.Review
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /v1/reviews/{id}/votes [post]
+
+// VoteReviewHandler handles the request to mark a review as helpful or not
+func (h *ReviewHandler) VoteReviewHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	var req RequestReviewVote
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Validation error: "+err.Error())
+	}
+
+	review, err := h.reviewService.VoteReview(ctx, id, *req.Helpful)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": review})
+}
+
+// @Summary List reviews for moderation
+// @Tags Review
+// @Produce json
+// @Param status query string false "PENDING, APPROVED or REJECTED"
+// @Param product_id query int false "Only reviews of this product"
+// @Param sort query string false "helpful, newest, rating or -rating"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Success 200 {object} ReviewListResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /v1/reviews [get]
+
+// GetReviewsHandler handles the request to list reviews of any status
+func (h *ReviewHandler) GetReviewsHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	query, err := parseReviewQuery(c)
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+	query.Status = c.QueryParam("status")
+	if raw := c.QueryParam("product_id"); raw != "" {
+		if query.ProductID, err = parseID(raw); err != nil {
+			return errors.HTTPErrorHandler(errors.ErrBadRequest)
+		}
+	}
+
+	page, err := h.reviewService.GetReviews(ctx, query)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, reviewListResponse(c, query, page))
+}
+
+// @Summary Moderate review
+// @Tags Review
+// @Accept json
+// @Produce json
+// @Param id path int true "Review ID"
+// @Param request body RequestModeration true "Decision"
+// @Success 200 {object} model.Review
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /v1/reviews/{id}/moderation [put]
+
+// ModerateReviewHandler handles the request to approve or reject a review
+func (h *ReviewHandler) ModerateReviewHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	var req RequestModeration
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Validation error: "+err.Error())
+	}
+
+	review, err := h.reviewService.ModerateReview(ctx, id, req.Status, req.Note)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": review})
+}
+
+func parseReviewQuery(c echo.Context) (model.ReviewQuery, error) {
+	query := model.ReviewQuery{Sort: c.QueryParam("sort")}
+
+	page, err := pagination.ParseInt(c.QueryParam("page"), 1)
+	if err != nil {
+		return query, err
+	}
+	limit, err := pagination.ParseInt(c.QueryParam("limit"), pagination.DefaultLimit)
+	if err != nil {
+		return query, err
+	}
+	query.Pagination = pagination.Params{Page: page, Limit: limit}
+
+	return query, nil
+}
+
+func reviewListResponse(c echo.Context, query model.ReviewQuery, page *model.ReviewPage) ReviewListResponse {
+	params := query.Pagination.Normalize()
+	meta := pagination.Meta{
+		Total:      page.Total,
+		Limit:      params.Limit,
+		Page:       params.Page,
+		TotalPages: pagination.TotalPages(page.Total, params.Limit),
+	}
+	if params.Page < meta.TotalPages {
+		meta.Next = pagination.NextLink(c.Request().URL, map[string]string{"page": strconv.Itoa(params.Page + 1)})
+	}
+
+	return ReviewListResponse{
+		Data: page.Reviews,
+		Meta: meta,
+	}
+}
+
+func parseID(raw string) (uint, error) {
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return uint(id), nil
+}
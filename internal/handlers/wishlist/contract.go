@@ -0,0 +1,14 @@
+package wishlist
+
+type RequestWishlist struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type RequestWishlistItem struct {
+	ProductID uint  `json:"product_id" validate:"required"`
+	VariantID *uint `json:"variant_id"`
+}
+
+type RequestMoveToCart struct {
+	Quantity uint `json:"quantity"`
+}
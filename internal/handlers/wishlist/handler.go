@@ -0,0 +1,343 @@
+package wishlist
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-online-store/internal/domain/wishlist/service"
+	"go-online-store/pkg/errors"
+
+	"github.com/labstack/echo/v4"
+)
+
+type WishlistHandler struct {
+	wishlistService service.WishlistServiceImpl
+}
+
+func NewWishlistHandler(wishlistService service.WishlistServiceImpl) *WishlistHandler {
+	return &WishlistHandler{
+		wishlistService: wishlistService,
+	}
+}
+
+// @Summary List wishlists
+// @Tags Wishlist
+// @Produce json
+// @Success 200 {array} model.Wishlist
+// @Failure 401 {object} ErrorResponse
+// @Router /v1/wishlists [get]
+
+// GetWishlistsHandler handles the request to list the customer's wishlists
+func (h *WishlistHandler) GetWishlistsHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	wishlists, err := h.wishlistService.GetWishlists(ctx)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": wishlists})
+}
+
+// @Summary Create wishlist
+// @Tags Wishlist
+// @Accept json
+// @Produce json
+// @Param request body RequestWishlist true "Wishlist name"
+// @Success 201 {object} model.Wishlist
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /v1/wishlists [post]
+
+// CreateWishlistHandler handles the request to start a new wishlist
+func (h *WishlistHandler) CreateWishlistHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req RequestWishlist
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Validation error: "+err.Error())
+	}
+
+	wishlist, err := h.wishlistService.CreateWishlist(ctx, req.Name)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{"data": wishlist})
+}
+
+// @Summary Get wishlist
+// @Tags Wishlist
+// @Produce json
+// @Param id path int true "Wishlist ID"
+// @Success 200 {object} model.Wishlist
+// @Failure 404 {object} ErrorResponse
+// @Router /v1/wishlists/{id} [get]
+
+// GetWishlistHandler handles the request to view one of the customer's wishlists
+func (h *WishlistHandler) GetWishlistHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	wishlist, err := h.wishlistService.GetWishlist(ctx, id)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": wishlist})
+}
+
+// @Summary Rename wishlist
+// @Tags Wishlist
+// @Accept json
+// @Produce json
+// @Param id path int true "Wishlist ID"
+// @Param request body RequestWishlist true "Wishlist name"
+// @Success 200 {object} model.Wishlist
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /v1/wishlists/{id} [put]
+
+// RenameWishlistHandler handles the request to rename a wishlist
+func (h *WishlistHandler) RenameWishlistHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	var req RequestWishlist
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Validation error: "+err.Error())
+	}
+
+	wishlist, err := h.wishlistService.RenameWishlist(ctx, id, req.Name)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": wishlist})
+}
+
+// @Summary Delete wishlist
+// @Tags Wishlist
+// @Produce json
+// @Param id path int true "Wishlist ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} ErrorResponse
+// @Router /v1/wishlists/{id} [delete]
+
+// DeleteWishlistHandler handles the removal of a wishlist
+func (h *WishlistHandler) DeleteWishlistHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	if err := h.wishlistService.DeleteWishlist(ctx, id); err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "wishlist deleted"})
+}
+
+// @Summary Add product to wishlist
+// @Tags Wishlist
+// @Accept json
+// @Produce json
+// @Param id path int true "Wishlist ID"
+// @Param request body RequestWishlistItem true "Product and optional variant"
+// @Success 200 {object} model.Wishlist
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /v1/wishlists/{id}/items [post]
+
+// AddToWishlistHandler handles the request to save a product to a wishlist
+func (h *WishlistHandler) AddToWishlistHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	var req RequestWishlistItem
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Validation error: "+err.Error())
+	}
+
+	wishlist, err := h.wishlistService.AddToWishlist(ctx, id, req.ProductID, req.VariantID)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": wishlist})
+}
+
+// @Summary Remove item from wishlist
+// @Tags Wishlist
+// @Produce json
+// @Param id path int true "Wishlist ID"
+// @Param itemId path int true "Wishlist item ID"
+// @Success 200 {object} model.Wishlist
+// @Failure 404 {object} ErrorResponse
+// @Router /v1/wishlists/{id}/items/{itemId} [delete]
+
+// RemoveFromWishlistHandler handles the request to remove an item from a wishlist
+func (h *WishlistHandler) RemoveFromWishlistHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+	itemID, err := parseID(c.Param("itemId"))
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	wishlist, err := h.wishlistService.RemoveFromWishlist(ctx, id, itemID)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": wishlist})
+}
+
+// @Summary Move wishlist item to cart
+// @Tags Wishlist
+// @Description Adds the item to the cart and removes it from the wishlist
+// @Accept json
+// @Produce json
+// @Param id path int true "Wishlist ID"
+// @Param itemId path int true "Wishlist item ID"
+// @Param request body RequestMoveToCart false "Quantity, defaults to 1"
+// @Success 200 {object} model.Wishlist
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /v1/wishlists/{id}/items/{itemId}/cart [post]
+
+// MoveToCartHandler handles the request to move a wishlist item to the cart
+func (h *WishlistHandler) MoveToCartHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+	itemID, err := parseID(c.Param("itemId"))
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	var req RequestMoveToCart
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+
+	wishlist, err := h.wishlistService.MoveToCart(ctx, id, itemID, req.Quantity)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": wishlist})
+}
+
+// @Summary Share wishlist
+// @Tags Wishlist
+// @Description Creates a link anyone can use to view the wishlist
+// @Produce json
+// @Param id path int true "Wishlist ID"
+// @Success 200 {object} model.Wishlist
+// @Failure 404 {object} ErrorResponse
+// @Router /v1/wishlists/{id}/share [post]
+
+// ShareWishlistHandler handles the request to create a share link for a wishlist
+func (h *WishlistHandler) ShareWishlistHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	wishlist, err := h.wishlistService.ShareWishlist(ctx, id)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": wishlist})
+}
+
+// @Summary Stop sharing wishlist
+// @Tags Wishlist
+// @Produce json
+// @Param id path int true "Wishlist ID"
+// @Success 200 {object} model.Wishlist
+// @Failure 404 {object} ErrorResponse
+// @Router /v1/wishlists/{id}/share [delete]
+
+// UnshareWishlistHandler handles the request to revoke a wishlist's share link
+func (h *WishlistHandler) UnshareWishlistHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		return errors.HTTPErrorHandler(errors.ErrBadRequest)
+	}
+
+	wishlist, err := h.wishlistService.UnshareWishlist(ctx, id)
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": wishlist})
+}
+
+// @Summary View shared wishlist
+// @Tags Wishlist
+// @Description Read-only view of a wishlist opened through its share link
+// @Produce json
+// @Param token path string true "Share token"
+// @Success 200 {object} model.SharedWishlist
+// @Failure 404 {object} ErrorResponse
+// @Router /v1/wishlists/shared/{token} [get]
+
+// GetSharedWishlistHandler handles the request to view a shared wishlist
+func (h *WishlistHandler) GetSharedWishlistHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	wishlist, err := h.wishlistService.GetSharedWishlist(ctx, c.Param("token"))
+	if err != nil {
+		return errors.HTTPErrorHandler(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": wishlist})
+}
+
+func parseID(raw string) (uint, error) {
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return uint(id), nil
+}
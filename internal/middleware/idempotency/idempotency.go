@@ -0,0 +1,119 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"go-online-store/internal/domain/idempotency/model"
+	"go-online-store/internal/domain/idempotency/service"
+	"go-online-store/internal/middleware/jwt"
+	customErrors "go-online-store/pkg/errors"
+	"go-online-store/pkg/logger"
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// KeyHeader carries the key a client picks for a request that must not
+	// be handled twice, e.g. a checkout retried on a flaky network.
+	KeyHeader = "Idempotency-Key"
+	// ReplayedHeader is set on responses replayed from an earlier request.
+	ReplayedHeader = "Idempotent-Replayed"
+)
+
+// Middleware makes a handler idempotent for signed-in customers who send an
+// Idempotency-Key header. The first response for a key is stored and
+// replayed for retries with the same method, URL and body; a key reused for
+// a different request is rejected. Server errors are not stored, so a retry
+// after one is handled again, and so is a retry after the response could not
+// be stored or the handler panicked. Requests without the header, or without
+// a customer in the context, pass through. It must run after ValidateJWT.
+func Middleware(svc service.IdempotencyServiceImpl, log *logger.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if svc == nil {
+			return next
+		}
+		return func(c echo.Context) error {
+			ctx := c.Request().Context()
+			key := c.Request().Header.Get(KeyHeader)
+			customer, ok := jwt.FromCustomer(ctx)
+			if key == "" || !ok {
+				return next(c)
+			}
+
+			body, err := io.ReadAll(c.Request().Body)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+			}
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+			record, err := svc.Begin(ctx, customer.ID, key, fingerprint(c.Request(), body))
+			if err != nil {
+				return customErrors.HTTPErrorHandler(err)
+			}
+			if record.Completed() {
+				c.Response().Header().Set(ReplayedHeader, "true")
+				return c.Blob(record.StatusCode, record.ContentType, record.Body)
+			}
+
+			// Settle the claim even when the client has gone away, and give it
+			// up if the handler panics, so retries are never locked out
+			settleCtx := context.WithoutCancel(ctx)
+			settled := false
+			defer func() {
+				if !settled {
+					release(settleCtx, svc, record, log)
+				}
+			}()
+
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+			if err := next(c); err != nil {
+				// Write the error response here so it can be stored too
+				c.Error(err)
+			}
+			settled = true
+
+			status := c.Response().Status
+			if status >= http.StatusInternalServerError {
+				release(settleCtx, svc, record, log)
+				return nil
+			}
+			err = svc.Complete(settleCtx, record, status, c.Response().Header().Get(echo.HeaderContentType), recorder.body.Bytes())
+			if err != nil {
+				log.Error("Failed to store response for idempotency key " + record.Key + ": " + err.Error())
+				release(settleCtx, svc, record, log)
+			}
+			return nil
+		}
+	}
+}
+
+// release gives up a claim so the next retry with its key is handled again.
+func release(ctx context.Context, svc service.IdempotencyServiceImpl, record *model.IdempotencyKey, log *logger.Logger) {
+	if err := svc.Release(ctx, record); err != nil {
+		log.Error("Failed to release idempotency key " + record.Key + ": " + err.Error())
+	}
+}
+
+// fingerprint identifies a request by its method, URL and body.
+func fingerprint(req *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(req.Method + " " + req.URL.RequestURI() + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder copies the response body while it is written.
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"go-online-store/internal/domain/customer/model"
+	"go-online-store/pkg/constant"
 	"net/http"
 	"os"
 	"strings"
@@ -23,9 +24,17 @@ const (
 
 // Customer represents the customer information stored in the context.
 type Customer struct {
-	ID      uint
-	Email   string
-	Address string
+	ID         uint
+	Email      string
+	Address    string
+	City       string
+	PostalCode string
+	Role       string
+}
+
+// IsAdmin reports whether the customer in the context has the admin role.
+func (c Customer) IsAdmin() bool {
+	return c.Role == constant.ROLE_ADMIN
 }
 
 // WithCustomer stores the customer information in the context.
@@ -98,11 +107,24 @@ func ValidateJWT(next echo.HandlerFunc) echo.HandlerFunc {
 		}
 		userIdFromSubClaim := uint(idFloat)
 
+		// Tokens issued before roles existed carry no role claim
+		roleFromSubClaim, _ := subClaim["role"].(string)
+		if roleFromSubClaim == "" {
+			roleFromSubClaim = constant.ROLE_CUSTOMER
+		}
+
+		// City and postal code are optional; they locate the nearest warehouse
+		cityFromSubClaim, _ := subClaim["city"].(string)
+		postalCodeFromSubClaim, _ := subClaim["postal_code"].(string)
+
 		// Create a customer object
 		customer := Customer{
-			ID:      userIdFromSubClaim,
-			Email:   emailFromSubClaim,
-			Address: addressFromSubClaim,
+			ID:         userIdFromSubClaim,
+			Email:      emailFromSubClaim,
+			Address:    addressFromSubClaim,
+			City:       cityFromSubClaim,
+			PostalCode: postalCodeFromSubClaim,
+			Role:       roleFromSubClaim,
 		}
 
 		ctx := WithCustomer(c.Request().Context(), customer)
@@ -112,6 +134,23 @@ func ValidateJWT(next echo.HandlerFunc) echo.HandlerFunc {
 	}
 }
 
+// RequireAdmin rejects requests whose customer is not an admin.
+// It must be chained after ValidateJWT.
+func RequireAdmin(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		customer, ok := FromCustomer(c.Request().Context())
+		if !ok {
+			return c.JSON(http.StatusUnauthorized, "Customer Unauthorized")
+		}
+
+		if !customer.IsAdmin() {
+			return c.JSON(http.StatusForbidden, "Admin role required")
+		}
+
+		return next(c)
+	}
+}
+
 // validateToken validates the JWT token.
 func validateToken(token string) (*jwt.Token, error) {
 	return jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
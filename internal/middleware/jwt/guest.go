@@ -0,0 +1,142 @@
+package jwt
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// GuestCartHeader carries the cart token of a visitor who is not signed
+	// in. It is also set on responses when a new token is issued.
+	GuestCartHeader = "X-Cart-Token"
+	// GuestCartCookie carries the same token for browsers.
+	GuestCartCookie = "cart_token"
+
+	guestKey        contextKey = "guest"
+	guestCartMaxAge            = 30 * 24 * time.Hour
+)
+
+var ErrInvalidGuestToken = errors.New("invalid cart token")
+
+// WithGuest stores the ID of an anonymous visitor in the context.
+func WithGuest(ctx context.Context, guestID string) context.Context {
+	return context.WithValue(ctx, guestKey, guestID)
+}
+
+// FromGuest retrieves the ID of an anonymous visitor from the context.
+func FromGuest(ctx context.Context) (string, bool) {
+	guestID, ok := ctx.Value(guestKey).(string)
+	return guestID, ok && guestID != ""
+}
+
+// NewGuestToken returns a new random guest ID and the signed token that
+// identifies it.
+func NewGuestToken(secret string) (string, string, error) {
+	buf := make([]byte, 18)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	guestID := base64.RawURLEncoding.EncodeToString(buf)
+	return guestID, guestID + "." + signGuestID(secret, guestID), nil
+}
+
+// ParseGuestToken verifies the signature of a guest token and returns its
+// guest ID.
+func ParseGuestToken(secret, token string) (string, error) {
+	guestID, signature, ok := strings.Cut(token, ".")
+	if !ok || guestID == "" {
+		return "", ErrInvalidGuestToken
+	}
+	if !hmac.Equal([]byte(signature), []byte(signGuestID(secret, guestID))) {
+		return "", ErrInvalidGuestToken
+	}
+	return guestID, nil
+}
+
+// GuestFromRequest returns the guest ID of a valid cart token sent in the
+// X-Cart-Token header or the cart_token cookie.
+func GuestFromRequest(c echo.Context) (string, bool) {
+	token := c.Request().Header.Get(GuestCartHeader)
+	if token == "" {
+		if cookie, err := c.Cookie(GuestCartCookie); err == nil {
+			token = cookie.Value
+		}
+	}
+	if token == "" {
+		return "", false
+	}
+
+	guestID, err := ParseGuestToken(os.Getenv("JWT_SECRET"), token)
+	if err != nil {
+		return "", false
+	}
+	return guestID, true
+}
+
+// ClearGuestToken tells the browser to drop the cart cookie, once the guest
+// cart has been merged into a customer's cart.
+func ClearGuestToken(c echo.Context) {
+	c.SetCookie(&http.Cookie{
+		Name:     GuestCartCookie,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// AllowGuest authenticates signed-in customers like ValidateJWT and lets
+// everyone else through as a guest identified by a signed cart token. A
+// visitor without a valid token is issued a new one in the X-Cart-Token
+// response header and the cart_token cookie.
+func AllowGuest(next echo.HandlerFunc) echo.HandlerFunc {
+	validated := ValidateJWT(next)
+	return func(c echo.Context) error {
+		if c.Request().Header.Get("Authorization") != "" {
+			return validated(c)
+		}
+
+		guestID, ok := GuestFromRequest(c)
+		if !ok {
+			var token string
+			var err error
+			guestID, token, err = NewGuestToken(os.Getenv("JWT_SECRET"))
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, "Failed to issue cart token")
+			}
+
+			c.Response().Header().Set(GuestCartHeader, token)
+			c.SetCookie(&http.Cookie{
+				Name:     GuestCartCookie,
+				Value:    token,
+				Path:     "/",
+				MaxAge:   int(guestCartMaxAge.Seconds()),
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+
+		ctx := WithGuest(c.Request().Context(), guestID)
+		c.SetRequest(c.Request().WithContext(ctx))
+
+		return next(c)
+	}
+}
+
+// signGuestID signs a guest ID with a key derived from the JWT secret, so a
+// cart token can never pass as any other signed value.
+func signGuestID(secret, guestID string) string {
+	mac := hmac.New(sha256.New, []byte("guest-cart:"+secret))
+	mac.Write([]byte(guestID))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
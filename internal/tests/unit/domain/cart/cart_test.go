@@ -0,0 +1,105 @@
+package cart
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-online-store/internal/domain/cart/model"
+	productModel "go-online-store/internal/domain/product/model"
+	"go-online-store/pkg/constant"
+)
+
+func uintPtr(v uint) *uint {
+	return &v
+}
+
+// TestCartQuantityMergesDuplicateLines checks duplicate lines count toward one quantity per product and variant.
+func TestCartQuantityMergesDuplicateLines(t *testing.T) {
+	cart := &model.Cart{Items: []model.CartItem{
+		{ID: 1, ProductID: 3, Quantity: 2},
+		{ID: 2, ProductID: 3, Quantity: 1},
+		{ID: 3, ProductID: 3, VariantID: uintPtr(7), Quantity: 4},
+		{ID: 4, ProductID: 5, Quantity: 1},
+	}}
+
+	assert.Equal(t, uint(3), cart.Quantity(3, nil))
+	assert.Equal(t, uint(4), cart.Quantity(3, uintPtr(7)))
+	assert.Equal(t, uint(0), cart.Quantity(3, uintPtr(8)))
+	assert.Equal(t, uint(0), cart.Quantity(9, nil))
+
+	assert.Equal(t, 0, cart.Index(3, nil))
+	assert.Equal(t, 2, cart.Index(3, uintPtr(7)))
+	assert.Equal(t, -1, cart.Index(9, nil))
+}
+
+// TestWithinPurchaseLimit checks a zero limit means no limit.
+func TestWithinPurchaseLimit(t *testing.T) {
+	product := &productModel.Product{}
+	assert.True(t, product.WithinPurchaseLimit(1000))
+
+	product.PurchaseLimit = 2
+	assert.True(t, product.WithinPurchaseLimit(2))
+	assert.False(t, product.WithinPurchaseLimit(3))
+}
+
+// TestCartItemCheckReportsChanges checks a line warns about price moves, stock shortages and deleted products.
+func TestCartItemCheckReportsChanges(t *testing.T) {
+	oldPrice := 100.0
+	item := model.CartItem{ProductID: 3, Quantity: 4, UnitPrice: &oldPrice}
+
+	product := &productModel.Product{ID: 3, Name: "Mug", Price: 100, Stok: 10}
+	assert.Empty(t, item.Check(product))
+
+	product.Price = 120
+	product.Stok = 2
+	warnings := item.Check(product)
+	if assert.Len(t, warnings, 2) {
+		assert.Equal(t, constant.CART_WARNING_PRICE_CHANGED, warnings[0].Code)
+		assert.Equal(t, 100.0, *warnings[0].OldPrice)
+		assert.Equal(t, 120.0, *warnings[0].NewPrice)
+		assert.Equal(t, constant.CART_WARNING_INSUFFICIENT_STOCK, warnings[1].Code)
+		assert.Equal(t, uint(2), *warnings[1].Available)
+	}
+
+	product.Stok = 0
+	warnings = item.Check(product)
+	if assert.Len(t, warnings, 2) {
+		assert.Equal(t, constant.CART_WARNING_OUT_OF_STOCK, warnings[1].Code)
+	}
+
+	warnings = item.Check(nil)
+	if assert.Len(t, warnings, 1) {
+		assert.Equal(t, constant.CART_WARNING_UNAVAILABLE, warnings[0].Code)
+	}
+}
+
+// TestCartItemCheckWithoutSnapshot checks lines added before prices were recorded only warn about stock.
+func TestCartItemCheckWithoutSnapshot(t *testing.T) {
+	item := model.CartItem{ProductID: 3, Quantity: 1}
+	product := &productModel.Product{ID: 3, Name: "Mug", Price: 150, Stok: 5}
+	assert.Empty(t, item.Check(product))
+}
+
+// TestCartSplitsSavedItems checks lines saved for later are kept apart from the lines to buy.
+func TestCartSplitsSavedItems(t *testing.T) {
+	cart := &model.Cart{Items: []model.CartItem{
+		{ID: 1, ProductID: 3, Quantity: 2},
+		{ID: 2, ProductID: 4, Quantity: 1, SavedForLater: true},
+		{ID: 3, ProductID: 5, Quantity: 1},
+	}}
+
+	active := cart.Active()
+	if assert.Len(t, active, 2) {
+		assert.Equal(t, uint(3), active[0].ProductID)
+		assert.Equal(t, uint(5), active[1].ProductID)
+	}
+	saved := cart.Saved()
+	if assert.Len(t, saved, 1) {
+		assert.Equal(t, uint(4), saved[0].ProductID)
+	}
+
+	empty := &model.Cart{}
+	assert.NotNil(t, empty.Active())
+	assert.Empty(t, empty.Saved())
+}
@@ -0,0 +1,172 @@
+package cart
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	recoveryConfig "go-online-store/config/recovery"
+	"go-online-store/internal/domain/cart/model"
+	"go-online-store/internal/domain/cart/repository"
+	"go-online-store/internal/domain/cart/service"
+	"go-online-store/pkg/constant"
+	"go-online-store/pkg/logger"
+	"go-online-store/pkg/notifier"
+	"go-online-store/pkg/scheduler"
+)
+
+// fakeRecoveryRepo keeps reminders in memory and reports every cart as
+// abandoned until a reminder for it has been sent or given up on.
+type fakeRecoveryRepo struct {
+	carts      []model.AbandonedCart
+	recoveries []*model.CartRecovery
+}
+
+func (r *fakeRecoveryRepo) GetAbandonedCarts(before time.Time, limit int) ([]model.AbandonedCart, error) {
+	var carts []model.AbandonedCart
+	for _, cart := range r.carts {
+		recovery, err := r.GetRecovery(cart.CartID, cart.UpdatedAt)
+		if cart.UpdatedAt.Before(before) && (err != nil || recovery.Status == constant.CART_RECOVERY_STATUS_PENDING) {
+			carts = append(carts, cart)
+		}
+	}
+	return carts, nil
+}
+
+func (r *fakeRecoveryRepo) GetRecovery(cartID uint, cartUpdatedAt time.Time) (*model.CartRecovery, error) {
+	for _, recovery := range r.recoveries {
+		if recovery.CartID == cartID && recovery.CartUpdatedAt.Equal(cartUpdatedAt) {
+			return recovery, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeRecoveryRepo) GetRecoveryByToken(token string) (*model.CartRecovery, error) {
+	for _, recovery := range r.recoveries {
+		if recovery.Token == token {
+			return recovery, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeRecoveryRepo) GetRecoveryByCode(code string) (*model.CartRecovery, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeRecoveryRepo) SaveRecovery(recovery *model.CartRecovery) error {
+	if recovery.ID == 0 {
+		recovery.ID = uint(len(r.recoveries) + 1)
+		r.recoveries = append(r.recoveries, recovery)
+	}
+	return nil
+}
+
+func (r *fakeRecoveryRepo) MarkOpened(id uint, at time.Time) error { return nil }
+
+func (r *fakeRecoveryRepo) Redeem(id uint, at time.Time) (bool, error) { return true, nil }
+
+func (r *fakeRecoveryRepo) MarkConverted(cartID, orderID uint, at time.Time) error { return nil }
+
+func (r *fakeRecoveryRepo) GetRecoveryStats(since time.Time) (*model.RecoveryStats, error) {
+	return &model.RecoveryStats{Sent: 4, Converted: 1}, nil
+}
+
+func (r *fakeRecoveryRepo) WithTx(tx *gorm.DB) repository.RecoveryRepositoryImpl { return r }
+
+// manualScheduler runs scheduled jobs only when the test asks it to.
+type manualScheduler struct {
+	interval time.Duration
+	job      scheduler.Job
+}
+
+func (s *manualScheduler) Every(ctx context.Context, interval time.Duration, job scheduler.Job) {
+	s.interval = interval
+	s.job = job
+}
+
+func newRecoveryService(repo *fakeRecoveryRepo, sink notifier.Notifier, rate float64) *service.RecoveryService {
+	cfg := &recoveryConfig.RecoveryConfig{
+		AbandonedAfter: time.Hour,
+		ScanInterval:   time.Minute,
+		CartURL:        "https://shop.example.com/cart",
+		DiscountRate:   rate,
+		CodeTTL:        24 * time.Hour,
+	}
+	return service.NewRecoveryService(repo, sink, cfg, logger.NewLogger(io.Discard, "test"))
+}
+
+// TestRecoverAbandonedCarts checks only carts left long enough are reminded, once, with a deep link and discount code.
+func TestRecoverAbandonedCarts(t *testing.T) {
+	repo := &fakeRecoveryRepo{carts: []model.AbandonedCart{
+		{CartID: 1, CustomerID: 7, Email: "a@example.com", UpdatedAt: time.Now().Add(-2 * time.Hour), ItemCount: 3},
+		{CartID: 2, CustomerID: 8, Email: "b@example.com", UpdatedAt: time.Now().Add(-10 * time.Minute), ItemCount: 1},
+	}}
+	sink := notifier.NewMemoryNotifier()
+	sched := &manualScheduler{}
+	recoveryService := newRecoveryService(repo, sink, 0.1)
+
+	recoveryService.StartRecovery(context.Background(), sched)
+	assert.Equal(t, time.Minute, sched.interval)
+
+	sched.job(context.Background())
+	sched.job(context.Background())
+
+	messages := sink.Messages()
+	if assert.Len(t, messages, 1) {
+		assert.Equal(t, "a@example.com", messages[0].To)
+		assert.Contains(t, messages[0].Body, "3 item(s)")
+		assert.Contains(t, messages[0].Body, "https://shop.example.com/cart?")
+		assert.Contains(t, messages[0].Body, "10% off")
+	}
+
+	if assert.Len(t, repo.recoveries, 1) {
+		recovery := repo.recoveries[0]
+		assert.Equal(t, constant.CART_RECOVERY_STATUS_SENT, recovery.Status)
+		assert.NotNil(t, recovery.SentAt)
+		assert.True(t, strings.HasPrefix(*recovery.DiscountCode, "BACK-"))
+		assert.Contains(t, messages[0].Body, *recovery.DiscountCode)
+		assert.True(t, recovery.Redeemable(time.Now()))
+		assert.False(t, recovery.Redeemable(time.Now().Add(48*time.Hour)))
+	}
+}
+
+// TestRecoverAbandonedCartsRetriesFailures checks a reminder that cannot be delivered is retried and then given up on.
+func TestRecoverAbandonedCartsRetriesFailures(t *testing.T) {
+	repo := &fakeRecoveryRepo{carts: []model.AbandonedCart{
+		{CartID: 1, CustomerID: 7, Email: "a@example.com", UpdatedAt: time.Now().Add(-2 * time.Hour), ItemCount: 1},
+	}}
+	sink := notifier.NewMemoryNotifier()
+	sink.Err = errors.New("mail server down")
+	recoveryService := newRecoveryService(repo, sink, 0)
+
+	for i := 0; i < model.MaxRecoveryAttempts+2; i++ {
+		sent, err := recoveryService.RecoverAbandonedCarts(context.Background())
+		assert.NoError(t, err)
+		assert.Zero(t, sent)
+	}
+
+	if assert.Len(t, repo.recoveries, 1) {
+		recovery := repo.recoveries[0]
+		assert.Equal(t, constant.CART_RECOVERY_STATUS_FAILED, recovery.Status)
+		assert.Equal(t, uint(model.MaxRecoveryAttempts), recovery.Attempts)
+		assert.Equal(t, "mail server down", recovery.LastError)
+		assert.Nil(t, recovery.DiscountCode)
+	}
+}
+
+// TestGetRecoveryStats checks the conversion rate is the share of sent reminders that led to a paid order.
+func TestGetRecoveryStats(t *testing.T) {
+	recoveryService := newRecoveryService(&fakeRecoveryRepo{}, notifier.NewMemoryNotifier(), 0)
+
+	stats, err := recoveryService.GetRecoveryStats(context.Background(), time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, 0.25, stats.ConversionRate)
+}
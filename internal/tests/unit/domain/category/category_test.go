@@ -0,0 +1,42 @@
+package category
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-online-store/internal/domain/category/model"
+)
+
+func uintPtr(v uint) *uint {
+	return &v
+}
+
+// TestSlugifyFoldsVariants checks that spelling variants of a legacy category share a slug.
+func TestSlugifyFoldsVariants(t *testing.T) {
+	assert.Equal(t, "shoes", model.Slugify("Shoes"))
+	assert.Equal(t, "shoes", model.Slugify("  shoes "))
+	assert.Equal(t, "men-s-shoes", model.Slugify("Men's Shoes!"))
+	assert.Equal(t, "", model.Slugify("--"))
+}
+
+// TestBuildTreeAndDescendants checks nesting, sibling order and descendant lookup.
+func TestBuildTreeAndDescendants(t *testing.T) {
+	categories := []*model.Category{
+		{ID: 1, Name: "Apparel"},
+		{ID: 2, Name: "Shoes", ParentID: uintPtr(1), SortOrder: 2},
+		{ID: 3, Name: "Shirts", ParentID: uintPtr(1), SortOrder: 1},
+		{ID: 4, Name: "Sneakers", ParentID: uintPtr(2)},
+		{ID: 5, Name: "Kitchen"},
+	}
+
+	roots := model.BuildTree(categories)
+
+	assert.Len(t, roots, 2)
+	assert.Equal(t, "Apparel", roots[0].Name)
+	assert.Equal(t, []string{"Shirts", "Shoes"}, []string{roots[0].Children[0].Name, roots[0].Children[1].Name})
+	assert.ElementsMatch(t, []uint{1, 2, 3, 4}, model.DescendantIDs(categories, 1))
+	assert.ElementsMatch(t, []uint{2, 4}, model.DescendantIDs(categories, 2))
+	assert.True(t, model.IsDescendant(categories, 1, 4))
+	assert.False(t, model.IsDescendant(categories, 2, 3))
+}
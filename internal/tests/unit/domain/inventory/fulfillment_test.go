@@ -0,0 +1,79 @@
+package inventory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-online-store/internal/domain/inventory/model"
+)
+
+var warehouses = []model.Warehouse{
+	{ID: 1, Code: "MAIN", City: "Jakarta", PostalCode: "10110", IsDefault: true, Active: true},
+	{ID: 2, Code: "SBY", City: "Surabaya", PostalCode: "60111", Active: true},
+	{ID: 3, Code: "BDG", City: "Bandung", PostalCode: "40111", Active: true},
+}
+
+// TestProximityPrefersPostalCode checks that shared postal-code digits outweigh a matching city.
+func TestProximityPrefersPostalCode(t *testing.T) {
+	location := model.Location{City: "Surabaya", PostalCode: "60234"}
+
+	assert.Equal(t, 25, warehouses[1].Proximity(location))
+	assert.Equal(t, 0, warehouses[0].Proximity(location))
+	assert.Equal(t, 5, warehouses[1].Proximity(model.Location{City: " surabaya "}))
+}
+
+// TestAllocatePicksNearestWarehouse checks that a line ships whole from the closest warehouse with enough stock.
+func TestAllocatePicksNearestWarehouse(t *testing.T) {
+	levels := model.StockLevels([]model.WarehouseStock{
+		{WarehouseID: 1, ProductID: 7, Quantity: 10},
+		{WarehouseID: 2, ProductID: 7, Quantity: 1},
+		{WarehouseID: 3, ProductID: 7, Quantity: 10},
+	})
+	lines := []model.FulfillmentLine{{ProductID: 7, Quantity: 2}}
+
+	allocations, err := model.Allocate(lines, warehouses, levels, model.Location{PostalCode: "40100"})
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Allocation{{Line: 0, WarehouseID: 3, Quantity: 2}}, allocations)
+
+	// Surabaya is closest but short, so the line ships whole from elsewhere
+	allocations, err = model.Allocate(lines, warehouses, levels, model.Location{PostalCode: "60100"})
+	assert.NoError(t, err)
+	assert.Len(t, allocations, 1)
+	assert.NotEqual(t, uint(2), allocations[0].WarehouseID)
+}
+
+// TestAllocateSplitsLine checks that a line no single warehouse can ship is split, nearest first.
+func TestAllocateSplitsLine(t *testing.T) {
+	variantID := uint(4)
+	levels := model.StockLevels([]model.WarehouseStock{
+		{WarehouseID: 1, ProductID: 7, VariantID: &variantID, Quantity: 3},
+		{WarehouseID: 2, ProductID: 7, VariantID: &variantID, Quantity: 2},
+	})
+	lines := []model.FulfillmentLine{{ProductID: 7, VariantID: &variantID, Quantity: 4}}
+
+	allocations, err := model.Allocate(lines, warehouses, levels, model.Location{City: "Surabaya"})
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Allocation{
+		{Line: 0, WarehouseID: 2, Quantity: 2},
+		{Line: 0, WarehouseID: 1, Quantity: 2},
+	}, allocations)
+}
+
+// TestAllocateSkipsInactiveWarehouses checks that stock at an inactive warehouse is never used.
+func TestAllocateSkipsInactiveWarehouses(t *testing.T) {
+	inactive := append([]model.Warehouse{}, warehouses...)
+	inactive[2].Active = false
+	levels := model.StockLevels([]model.WarehouseStock{
+		{WarehouseID: 1, ProductID: 7, Quantity: 1},
+		{WarehouseID: 3, ProductID: 7, Quantity: 10},
+	})
+	lines := []model.FulfillmentLine{{ProductID: 7, Quantity: 2}}
+
+	_, err := model.Allocate(lines, inactive, levels, model.Location{PostalCode: "40111"})
+	assert.ErrorIs(t, err, model.ErrUnfulfillable)
+
+	allocations, err := model.Allocate(lines, warehouses, levels, model.Location{PostalCode: "40111"})
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Allocation{{Line: 0, WarehouseID: 3, Quantity: 2}}, allocations)
+}
@@ -0,0 +1,99 @@
+package inventory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-online-store/internal/domain/inventory/model"
+	productModel "go-online-store/internal/domain/product/model"
+	"go-online-store/pkg/constant"
+)
+
+// balances sums movements the way the ledger derives stock.
+func balances(movements []model.StockMovement) (map[uint]int, map[uint]int) {
+	products := make(map[uint]int)
+	variants := make(map[uint]int)
+	for _, m := range movements {
+		products[m.ProductID] += m.Quantity
+		if m.VariantID != nil {
+			variants[*m.VariantID] += m.Quantity
+		}
+	}
+	return products, variants
+}
+
+// TestOpeningBalancesMatchStock checks the backfilled ledger of a variant
+// product derives exactly its stored stock.
+func TestOpeningBalancesMatchStock(t *testing.T) {
+	product := &productModel.Product{ID: 1, Stok: 12}
+	variants := []productModel.ProductVariant{
+		{ID: 10, ProductID: 1, Stok: 5},
+		{ID: 11, ProductID: 1, Stok: 0},
+		{ID: 12, ProductID: 1, Stok: 4},
+	}
+
+	movements := model.OpeningBalances(product, variants)
+	products, variantBalances := balances(movements)
+
+	assert.Len(t, movements, 3)
+	assert.Equal(t, 12, products[1])
+	assert.Equal(t, 5, variantBalances[10])
+	assert.Equal(t, 4, variantBalances[12])
+	for _, m := range movements {
+		assert.Equal(t, constant.STOCK_REASON_OPENING, m.Reason)
+	}
+	assert.Empty(t, model.OpeningBalances(&productModel.Product{ID: 2}, nil))
+}
+
+// TestReconcileFindsAndCorrectsDiscrepancies checks discrepancies are found
+// and that the corrections bring the ledger back in line.
+func TestReconcileFindsAndCorrectsDiscrepancies(t *testing.T) {
+	products := []*productModel.Product{
+		{ID: 1, Name: "Shirt", Stok: 9},
+		{ID: 2, Name: "Mug", Stok: 3},
+		{ID: 3, Name: "Cap", Stok: 7},
+	}
+	variants := []productModel.ProductVariant{
+		{ID: 10, ProductID: 1, SKU: "SHIRT-S", Stok: 4},
+		{ID: 11, ProductID: 1, SKU: "SHIRT-M", Stok: 5},
+		// Variants of deleted products are not checked
+		{ID: 20, ProductID: 99, SKU: "GONE", Stok: 1},
+	}
+	movements := []model.StockMovement{
+		{ProductID: 1, VariantID: &variants[0].ID, Quantity: 4},
+		{ProductID: 1, VariantID: &variants[1].ID, Quantity: 3},
+		{ProductID: 2, Quantity: 3},
+		{ProductID: 3, Quantity: 10},
+	}
+
+	productBalances, variantBalances := balances(movements)
+	discrepancies := model.FindDiscrepancies(products, variants, productBalances, variantBalances)
+
+	assert.Len(t, discrepancies, 3)
+	assert.Equal(t, uint(1), discrepancies[0].ProductID)
+	assert.Nil(t, discrepancies[0].VariantID)
+	assert.Equal(t, 2, discrepancies[0].Difference)
+	assert.Equal(t, "SHIRT-M", discrepancies[1].SKU)
+	assert.Equal(t, 2, discrepancies[1].Difference)
+	assert.Equal(t, uint(3), discrepancies[2].ProductID)
+	assert.Equal(t, -3, discrepancies[2].Difference)
+
+	corrections := model.Corrections(discrepancies, model.StockChange{Actor: "admin@example.com"})
+	productBalances, variantBalances = balances(append(movements, corrections...))
+
+	assert.Empty(t, model.FindDiscrepancies(products, variants, productBalances, variantBalances))
+	for _, m := range corrections {
+		assert.Equal(t, constant.STOCK_REASON_ADJUSTMENT, m.Reason)
+		assert.Equal(t, "admin@example.com", m.Actor)
+	}
+}
+
+// TestManualReasons checks only restock, adjustment and return can be
+// recorded by hand.
+func TestManualReasons(t *testing.T) {
+	assert.True(t, model.IsManualReason(constant.STOCK_REASON_RESTOCK))
+	assert.True(t, model.IsManualReason(constant.STOCK_REASON_RETURN))
+	assert.False(t, model.IsManualReason(constant.STOCK_REASON_SALE))
+	assert.False(t, model.IsManualReason(constant.STOCK_REASON_RESERVATION))
+}
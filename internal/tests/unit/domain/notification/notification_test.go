@@ -0,0 +1,81 @@
+package notification
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-online-store/internal/domain/notification/model"
+	productModel "go-online-store/internal/domain/product/model"
+	"go-online-store/pkg/constant"
+	"go-online-store/pkg/notifier"
+)
+
+// TestBackInStockTriggered checks a back-in-stock subscription fires once the product has stock.
+func TestBackInStockTriggered(t *testing.T) {
+	subscription := &model.Subscription{ProductID: 1, Kind: constant.SUBSCRIPTION_BACK_IN_STOCK, Active: true}
+
+	assert.False(t, subscription.Triggered(&productModel.Product{ID: 1, Stok: 0}))
+	assert.True(t, subscription.Triggered(&productModel.Product{ID: 1, Stok: 3}))
+	assert.False(t, subscription.Triggered(&productModel.Product{ID: 2, Stok: 3}))
+
+	subscription.Active = false
+	assert.False(t, subscription.Triggered(&productModel.Product{ID: 1, Stok: 3}))
+}
+
+// TestPriceDropTriggered checks a price-drop subscription fires at or below the target price.
+func TestPriceDropTriggered(t *testing.T) {
+	target := 80.0
+	subscription := &model.Subscription{ProductID: 1, Kind: constant.SUBSCRIPTION_PRICE_DROP, TargetPrice: &target, Active: true}
+
+	assert.False(t, subscription.Triggered(&productModel.Product{ID: 1, Price: 99.5}))
+	assert.True(t, subscription.Triggered(&productModel.Product{ID: 1, Price: 80}))
+	assert.True(t, subscription.Triggered(&productModel.Product{ID: 1, Price: 75}))
+}
+
+// TestNewNotification checks the queued message is addressed to the subscriber and names the product.
+func TestNewNotification(t *testing.T) {
+	target := 80.0
+	product := &productModel.Product{ID: 1, Name: "Kettle", Price: 75, Stok: 4}
+
+	notification := model.NewNotification(&model.Subscription{ID: 9, CustomerID: 5, Email: "a@example.com", Kind: constant.SUBSCRIPTION_PRICE_DROP, TargetPrice: &target}, product)
+	assert.Equal(t, uint(9), notification.SubscriptionID)
+	assert.Equal(t, "a@example.com", notification.Recipient)
+	assert.Equal(t, constant.NOTIFICATION_STATUS_PENDING, notification.Status)
+	assert.Equal(t, "Kettle is now 75.00", notification.Subject)
+	assert.Contains(t, notification.Body, "80.00")
+
+	notification = model.NewNotification(&model.Subscription{ID: 9, Email: "a@example.com", Kind: constant.SUBSCRIPTION_BACK_IN_STOCK}, product)
+	assert.Equal(t, "Kettle is back in stock", notification.Subject)
+}
+
+// TestFileNotifierAppendsMessages checks every message is written as its own JSON line.
+func TestFileNotifierAppendsMessages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox", "messages.jsonl")
+	sink, err := notifier.NewFileNotifier(path)
+	assert.NoError(t, err)
+
+	assert.NoError(t, sink.Send(context.Background(), notifier.Message{To: "a@example.com", Subject: "One", Body: "first"}))
+	assert.NoError(t, sink.Send(context.Background(), notifier.Message{To: "b@example.com", Subject: "Two", Body: "second"}))
+	assert.ErrorIs(t, sink.Send(context.Background(), notifier.Message{Subject: "Nobody"}), notifier.ErrNoRecipient)
+
+	file, err := os.Open(path)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	var messages []notifier.Message
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var msg notifier.Message
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &msg))
+		messages = append(messages, msg)
+	}
+	assert.Len(t, messages, 2)
+	assert.Equal(t, "b@example.com", messages[1].To)
+	assert.Equal(t, "second", messages[1].Body)
+}
@@ -0,0 +1,401 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	cartModel "go-online-store/internal/domain/cart/model"
+	repoCart "go-online-store/internal/domain/cart/repository"
+	inventoryModel "go-online-store/internal/domain/inventory/model"
+	inventoryRepo "go-online-store/internal/domain/inventory/repository"
+	"go-online-store/internal/domain/order/model"
+	repoOrder "go-online-store/internal/domain/order/repository"
+	"go-online-store/internal/domain/order/service"
+	"go-online-store/internal/domain/pricing"
+	productModel "go-online-store/internal/domain/product/model"
+	repoProduct "go-online-store/internal/domain/product/repository"
+	promotionModel "go-online-store/internal/domain/promotion/model"
+	repoPromotion "go-online-store/internal/domain/promotion/repository"
+	promotionService "go-online-store/internal/domain/promotion/service"
+	"go-online-store/internal/middleware/jwt"
+	"go-online-store/pkg/constant"
+	customErrors "go-online-store/pkg/errors"
+	"go-online-store/pkg/logger"
+)
+
+// store is the state of the fake repositories. The fake transactor restores
+// it when a transaction fails, the way the database rolls back.
+type store struct {
+	stock        map[uint]uint
+	cartItems    []cartModel.CartItem
+	orders       map[uint]model.Order
+	transactions map[uint]model.Transaction
+	reservations []model.StockReservation
+	history      []model.OrderStatusHistory
+	sales        int
+
+	failTransaction bool
+}
+
+func (s *store) snapshot() store {
+	copied := *s
+	copied.stock = maps.Clone(s.stock)
+	copied.cartItems = slices.Clone(s.cartItems)
+	copied.orders = maps.Clone(s.orders)
+	copied.transactions = maps.Clone(s.transactions)
+	copied.reservations = slices.Clone(s.reservations)
+	copied.history = slices.Clone(s.history)
+	return copied
+}
+
+type fakeTransactor struct {
+	store *store
+}
+
+func (t fakeTransactor) WithinTransaction(fn func(tx *gorm.DB) error) error {
+	saved := t.store.snapshot()
+	if err := fn(nil); err != nil {
+		*t.store = saved
+		return err
+	}
+	return nil
+}
+
+// Methods the order service does not use in these tests are left to the
+// embedded interfaces of the fakes below.
+
+type fakeOrderRepo struct {
+	repoOrder.OrderRepositoryImpl
+	store *store
+}
+
+func (r fakeOrderRepo) WithTx(tx *gorm.DB) repoOrder.OrderRepositoryImpl { return r }
+
+func (r fakeOrderRepo) CreateOrder(order *model.Order) error {
+	order.ID = uint(len(r.store.orders) + 1)
+	r.store.orders[order.ID] = *order
+	return nil
+}
+
+func (r fakeOrderRepo) UpdateOrder(order *model.Order) error {
+	r.store.orders[order.ID] = *order
+	return nil
+}
+
+func (r fakeOrderRepo) GetOrderForUpdate(id uint) (*model.Order, error) {
+	order, ok := r.store.orders[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &order, nil
+}
+
+func (r fakeOrderRepo) RecordStatusChange(history *model.OrderStatusHistory) error {
+	r.store.history = append(r.store.history, *history)
+	return nil
+}
+
+func (r fakeOrderRepo) CreateTransaction(transaction *model.Transaction) error {
+	if r.store.failTransaction {
+		return errors.New("payment provider unavailable")
+	}
+	r.store.transactions[transaction.OrderID] = *transaction
+	return nil
+}
+
+func (r fakeOrderRepo) UpdateTransaction(transaction *model.Transaction) error {
+	r.store.transactions[transaction.OrderID] = *transaction
+	return nil
+}
+
+func (r fakeOrderRepo) GetTransactionByOrderID(orderID uint) (*model.Transaction, error) {
+	transaction, ok := r.store.transactions[orderID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &transaction, nil
+}
+
+func (r fakeOrderRepo) CreateReservations(reservations []model.StockReservation) error {
+	r.store.reservations = append(r.store.reservations, reservations...)
+	return nil
+}
+
+func (r fakeOrderRepo) GetReservationsByOrderID(orderID uint) ([]model.StockReservation, error) {
+	var reservations []model.StockReservation
+	for _, reservation := range r.store.reservations {
+		if reservation.OrderID == orderID {
+			reservations = append(reservations, reservation)
+		}
+	}
+	return reservations, nil
+}
+
+func (r fakeOrderRepo) setStatus(match func(model.StockReservation) bool, to string) int64 {
+	var count int64
+	for i, reservation := range r.store.reservations {
+		if match(reservation) {
+			r.store.reservations[i].Status = to
+			count++
+		}
+	}
+	return count
+}
+
+func (r fakeOrderRepo) CommitReservations(orderID uint) (int64, error) {
+	return r.setStatus(func(reservation model.StockReservation) bool {
+		return reservation.OrderID == orderID && reservation.Status == constant.RESERVATION_STATUS_ACTIVE
+	}, constant.RESERVATION_STATUS_COMMITTED), nil
+}
+
+func (r fakeOrderRepo) ReleaseExpiredReservations(orderID uint, now time.Time) (int64, error) {
+	return r.setStatus(func(reservation model.StockReservation) bool {
+		return reservation.OrderID == orderID && reservation.Status == constant.RESERVATION_STATUS_ACTIVE && !reservation.ExpiresAt.After(now)
+	}, constant.RESERVATION_STATUS_RELEASED), nil
+}
+
+func (r fakeOrderRepo) GetOrderIDsWithExpiredReservations(now time.Time) ([]uint, error) {
+	var orderIDs []uint
+	for _, reservation := range r.store.reservations {
+		if reservation.Status == constant.RESERVATION_STATUS_ACTIVE && !reservation.ExpiresAt.After(now) && !slices.Contains(orderIDs, reservation.OrderID) {
+			orderIDs = append(orderIDs, reservation.OrderID)
+		}
+	}
+	return orderIDs, nil
+}
+
+// fakeCartRepo holds the cart (ID 1) of every customer.
+type fakeCartRepo struct {
+	repoCart.CartRepositoryImpl
+	store *store
+}
+
+func (r fakeCartRepo) WithTx(tx *gorm.DB) repoCart.CartRepositoryImpl { return r }
+
+func (r fakeCartRepo) GetCartByCustomerID(customerID uint) (*cartModel.Cart, error) {
+	return &cartModel.Cart{ID: 1, CustomerID: customerID, Items: slices.Clone(r.store.cartItems)}, nil
+}
+
+func (r fakeCartRepo) ClearCart(cartID uint) error {
+	r.store.cartItems = slices.DeleteFunc(r.store.cartItems, func(item cartModel.CartItem) bool { return !item.SavedForLater })
+	return nil
+}
+
+type fakeRecoveryRepo struct {
+	repoCart.RecoveryRepositoryImpl
+}
+
+func (fakeRecoveryRepo) MarkConverted(cartID, orderID uint, at time.Time) error { return nil }
+
+// fakeProductRepo sells mugs (ID 1) at 50000 from the stock in the store.
+type fakeProductRepo struct {
+	repoProduct.ProductRepositoryImpl
+	store *store
+}
+
+func (r fakeProductRepo) WithTx(tx *gorm.DB) repoProduct.ProductRepositoryImpl { return r }
+
+func (r fakeProductRepo) WithStockChange(change inventoryModel.StockChange) repoProduct.ProductRepositoryImpl {
+	return r
+}
+
+func (r fakeProductRepo) GetByID(id uint) (*productModel.Product, error) {
+	stock, ok := r.store.stock[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &productModel.Product{ID: id, Name: "Mug", Price: 50000, Stok: stock}, nil
+}
+
+func (r fakeProductRepo) ReserveStock(productID uint, variantID *uint, quantity uint) error {
+	if r.store.stock[productID] < quantity {
+		return repoProduct.ErrInsufficientStock
+	}
+	r.store.stock[productID] -= quantity
+	return nil
+}
+
+func (r fakeProductRepo) ReleaseStock(productID uint, variantID *uint, quantity uint) error {
+	r.store.stock[productID] += quantity
+	return nil
+}
+
+func (r fakeProductRepo) RecordSale(productID uint, variantID *uint, quantity uint) error {
+	r.store.sales++
+	return nil
+}
+
+// fakeWarehouseRepo has no warehouses, so stock is reserved unlocated.
+type fakeWarehouseRepo struct {
+	inventoryRepo.WarehouseRepositoryImpl
+}
+
+func (fakeWarehouseRepo) GetAll() ([]inventoryModel.Warehouse, error) { return nil, nil }
+
+type fakePromotionRepo struct {
+	repoPromotion.PromotionRepositoryImpl
+}
+
+func (r fakePromotionRepo) WithTx(tx *gorm.DB) repoPromotion.PromotionRepositoryImpl { return r }
+
+// fakePromotions runs no promotions.
+type fakePromotions struct {
+	promotionService.PromotionServiceImpl
+}
+
+func (fakePromotions) ApplyPromotions(ctx context.Context, customerID uint, breakdown *pricing.Breakdown, coupon *promotionModel.Promotion) error {
+	return nil
+}
+
+// newService returns an order service over a store with five mugs, two of
+// them in the cart of customer 1.
+func newService() (*service.OrderService, *store) {
+	s := &store{
+		stock:        map[uint]uint{1: 5},
+		cartItems:    []cartModel.CartItem{{CartID: 1, ProductID: 1, Quantity: 2}},
+		orders:       map[uint]model.Order{},
+		transactions: map[uint]model.Transaction{},
+	}
+	svc := service.NewOrderServiceWith(
+		fakeTransactor{store: s},
+		fakeOrderRepo{store: s},
+		fakeCartRepo{store: s},
+		fakeRecoveryRepo{},
+		fakeProductRepo{store: s},
+		nil,
+		fakeWarehouseRepo{},
+		fakePromotionRepo{},
+		fakePromotions{},
+		15*time.Minute,
+		logger.NewLogger(io.Discard, "test"),
+	)
+	return svc, s
+}
+
+func customer(id uint) context.Context {
+	return jwt.WithCustomer(context.Background(), jwt.Customer{ID: id, Email: "customer@example.com"})
+}
+
+// TestCheckoutReservesStock checks a placed order holds its stock until its payment is due.
+func TestCheckoutReservesStock(t *testing.T) {
+	svc, s := newService()
+
+	order, err := svc.Checkout(customer(1), "")
+	assert.NoError(t, err)
+	assert.Equal(t, constant.PAYMENT_STATUS_PENDING, s.orders[order.ID].PaymentStatus)
+	assert.Equal(t, constant.ORDER_STATUS_AWAITING_PAYMENT, s.orders[order.ID].OrderStatus)
+	assert.Equal(t, uint(3), s.stock[1])
+	assert.Len(t, s.reservations, 1)
+	assert.Equal(t, constant.RESERVATION_STATUS_ACTIVE, s.reservations[0].Status)
+	assert.Equal(t, *order.PaymentDueAt, s.reservations[0].ExpiresAt)
+	assert.Equal(t, constant.PAYMENT_STATUS_PENDING, s.transactions[order.ID].PaymentStatus)
+	assert.Len(t, s.history, 2)
+
+	// Two more mugs are left, not four, so the cart is sent back for review
+	s.cartItems = []cartModel.CartItem{{CartID: 1, ProductID: 1, Quantity: 4}}
+	_, err = svc.Checkout(customer(1), "")
+	assert.ErrorIs(t, err, customErrors.ErrCartChanged)
+	assert.Equal(t, uint(3), s.stock[1])
+	assert.Len(t, s.orders, 1)
+}
+
+// TestCheckoutRollsBackWhenTransactionFails checks a failed checkout leaves no order, reservation or held stock behind.
+func TestCheckoutRollsBackWhenTransactionFails(t *testing.T) {
+	svc, s := newService()
+	s.failTransaction = true
+
+	order, err := svc.Checkout(customer(1), "")
+	assert.Error(t, err)
+	assert.Nil(t, order)
+	assert.Equal(t, uint(5), s.stock[1])
+	assert.Empty(t, s.orders)
+	assert.Empty(t, s.reservations)
+	assert.Empty(t, s.transactions)
+	assert.Empty(t, s.history)
+	assert.Len(t, s.cartItems, 1)
+}
+
+// TestReleaseExpiredReservations checks the sweeper returns the stock of unpaid orders and cancels them.
+func TestReleaseExpiredReservations(t *testing.T) {
+	svc, s := newService()
+	order, err := svc.Checkout(customer(1), "")
+	assert.NoError(t, err)
+
+	// Still within the payment window
+	released, err := svc.ReleaseExpiredReservations(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, released)
+	assert.Equal(t, uint(3), s.stock[1])
+
+	s.reservations[0].ExpiresAt = time.Now().Add(-time.Minute)
+	released, err = svc.ReleaseExpiredReservations(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, released)
+	assert.Equal(t, uint(5), s.stock[1])
+	assert.Equal(t, constant.RESERVATION_STATUS_RELEASED, s.reservations[0].Status)
+	assert.Equal(t, constant.ORDER_STATUS_CANCELLED, s.orders[order.ID].OrderStatus)
+	assert.Equal(t, constant.PAYMENT_STATUS_EXPIRED, s.orders[order.ID].PaymentStatus)
+	assert.Equal(t, constant.PAYMENT_STATUS_EXPIRED, s.transactions[order.ID].PaymentStatus)
+
+	// Paying after the window closed changes nothing
+	before := s.snapshot()
+	err = svc.UpdatePaymentStatus(customer(1), order.ID)
+	assert.ErrorIs(t, err, customErrors.ErrReservationExpired)
+	assert.Equal(t, before, *s)
+}
+
+// TestUpdatePaymentStatus checks payment commits the reservations and clears the cart once, and only for the owner.
+func TestUpdatePaymentStatus(t *testing.T) {
+	svc, s := newService()
+	order, err := svc.Checkout(customer(1), "")
+	assert.NoError(t, err)
+
+	// Another customer cannot confirm the order
+	before := s.snapshot()
+	assert.ErrorIs(t, svc.UpdatePaymentStatus(customer(2), order.ID), customErrors.ErrNotFound)
+	assert.ErrorIs(t, svc.UpdatePaymentStatus(customer(1), 99), customErrors.ErrNotFound)
+	assert.Equal(t, before, *s)
+
+	assert.NoError(t, svc.UpdatePaymentStatus(customer(1), order.ID))
+	assert.Equal(t, constant.PAYMENT_STATUS_PAID, s.orders[order.ID].PaymentStatus)
+	assert.Equal(t, constant.ORDER_STATUS_PAID, s.orders[order.ID].OrderStatus)
+	assert.Equal(t, constant.PAYMENT_STATUS_PAID, s.transactions[order.ID].PaymentStatus)
+	assert.Equal(t, constant.RESERVATION_STATUS_COMMITTED, s.reservations[0].Status)
+	assert.Equal(t, uint(3), s.stock[1])
+	assert.Equal(t, 1, s.sales)
+	assert.Empty(t, s.cartItems)
+
+	// Paying again is a no-op
+	before = s.snapshot()
+	assert.NoError(t, svc.UpdatePaymentStatus(customer(1), order.ID))
+	assert.Equal(t, before, *s)
+
+	// A committed reservation is not released when its window passes
+	s.reservations[0].ExpiresAt = time.Now().Add(-time.Minute)
+	released, err := svc.ReleaseExpiredReservations(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, released)
+	assert.Equal(t, uint(3), s.stock[1])
+}
+
+// TestUpdateOrderStatusRejectsRefund checks an admin cannot mark a paid order refunded without cancelling it.
+func TestUpdateOrderStatusRejectsRefund(t *testing.T) {
+	svc, s := newService()
+	order, err := svc.Checkout(customer(1), "")
+	assert.NoError(t, err)
+	assert.NoError(t, svc.UpdatePaymentStatus(customer(1), order.ID))
+
+	admin := jwt.WithCustomer(context.Background(), jwt.Customer{ID: 9, Email: "admin@example.com", Role: constant.ROLE_ADMIN})
+	before := s.snapshot()
+	_, err = svc.UpdateOrderStatus(admin, order.ID, constant.ORDER_STATUS_REFUNDED, "")
+	assert.ErrorIs(t, err, customErrors.ErrInvalidTransition)
+	assert.Equal(t, before, *s)
+}
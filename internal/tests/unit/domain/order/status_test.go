@@ -0,0 +1,97 @@
+package order
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-online-store/internal/domain/order/model"
+	"go-online-store/pkg/constant"
+	customErrors "go-online-store/pkg/errors"
+)
+
+// TestOrderLifecycle checks an order can be walked from placement to delivery and every step is recorded.
+func TestOrderLifecycle(t *testing.T) {
+	order := &model.Order{ID: 4, OrderStatus: constant.ORDER_STATUS_PENDING}
+	steps := []struct {
+		to   string
+		role string
+	}{
+		{constant.ORDER_STATUS_AWAITING_PAYMENT, constant.ROLE_SYSTEM},
+		{constant.ORDER_STATUS_PAID, constant.ROLE_SYSTEM},
+		{constant.ORDER_STATUS_PROCESSING, constant.ROLE_ADMIN},
+		{constant.ORDER_STATUS_SHIPPED, constant.ROLE_ADMIN},
+		{constant.ORDER_STATUS_DELIVERED, constant.ROLE_ADMIN},
+	}
+
+	at := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	for _, step := range steps {
+		from := order.OrderStatus
+		history, err := order.Transition(step.to, step.role, "admin@example.com", "", at)
+		if assert.NoError(t, err, step.to) {
+			assert.Equal(t, uint(4), history.OrderID)
+			assert.Equal(t, from, history.FromStatus)
+			assert.Equal(t, step.to, history.ToStatus)
+			assert.Equal(t, step.role, history.Role)
+			assert.Equal(t, at, history.CreatedAt)
+		}
+		assert.Equal(t, step.to, order.OrderStatus)
+		assert.Equal(t, at, *order.StatusUpdatedAt)
+		at = at.Add(time.Hour)
+	}
+}
+
+// TestOrderTransitionRejected checks impossible changes and changes by the wrong role leave the order untouched.
+func TestOrderTransitionRejected(t *testing.T) {
+	order := &model.Order{OrderStatus: constant.ORDER_STATUS_AWAITING_PAYMENT}
+
+	_, err := order.Transition(constant.ORDER_STATUS_SHIPPED, constant.ROLE_ADMIN, "admin@example.com", "", time.Now())
+	assert.ErrorIs(t, err, customErrors.ErrInvalidTransition)
+
+	_, err = order.Transition(constant.ORDER_STATUS_PAID, constant.ROLE_CUSTOMER, "a@example.com", "", time.Now())
+	assert.ErrorIs(t, err, customErrors.ErrForbidden)
+	assert.Equal(t, constant.ORDER_STATUS_AWAITING_PAYMENT, order.OrderStatus)
+	assert.Nil(t, order.StatusUpdatedAt)
+
+	order.OrderStatus = constant.ORDER_STATUS_REFUNDED
+	_, err = order.Transition(constant.ORDER_STATUS_PAID, constant.ROLE_SYSTEM, "system", "", time.Now())
+	assert.ErrorIs(t, err, customErrors.ErrInvalidTransition)
+}
+
+// TestCancellationGuards checks customers may only cancel before shipment while admins may until delivery.
+func TestCancellationGuards(t *testing.T) {
+	assert.True(t, model.CanTransition(constant.ORDER_STATUS_PROCESSING, constant.ORDER_STATUS_CANCELLED, constant.ROLE_CUSTOMER))
+	assert.False(t, model.CanTransition(constant.ORDER_STATUS_SHIPPED, constant.ORDER_STATUS_CANCELLED, constant.ROLE_CUSTOMER))
+	assert.True(t, model.CanTransition(constant.ORDER_STATUS_SHIPPED, constant.ORDER_STATUS_CANCELLED, constant.ROLE_ADMIN))
+	assert.False(t, model.CanTransition(constant.ORDER_STATUS_DELIVERED, constant.ORDER_STATUS_CANCELLED, constant.ROLE_ADMIN))
+}
+
+// TestRefundOnlyByCancelling checks admins cannot mark an order refunded; only cancelling a paid order refunds it.
+func TestRefundOnlyByCancelling(t *testing.T) {
+	for _, from := range []string{constant.ORDER_STATUS_PAID, constant.ORDER_STATUS_DELIVERED} {
+		assert.ErrorIs(t, model.CheckTransition(from, constant.ORDER_STATUS_REFUNDED, constant.ROLE_ADMIN), customErrors.ErrInvalidTransition, from)
+	}
+	assert.ErrorIs(t, model.CheckTransition(constant.ORDER_STATUS_CANCELLED, constant.ORDER_STATUS_REFUNDED, constant.ROLE_ADMIN), customErrors.ErrForbidden)
+	assert.True(t, model.CanTransition(constant.ORDER_STATUS_CANCELLED, constant.ORDER_STATUS_REFUNDED, constant.ROLE_SYSTEM))
+}
+
+// TestValidOrderStatus checks every lifecycle status is known, final ones included.
+func TestValidOrderStatus(t *testing.T) {
+	for _, status := range []string{
+		constant.ORDER_STATUS_PENDING, constant.ORDER_STATUS_AWAITING_PAYMENT, constant.ORDER_STATUS_PAID,
+		constant.ORDER_STATUS_PROCESSING, constant.ORDER_STATUS_SHIPPED, constant.ORDER_STATUS_DELIVERED,
+		constant.ORDER_STATUS_CANCELLED, constant.ORDER_STATUS_REFUNDED, constant.ORDER_STATUS_FAILED,
+	} {
+		assert.True(t, model.ValidOrderStatus(status), status)
+	}
+	assert.False(t, model.ValidOrderStatus("SUCCESS"))
+}
+
+// TestValidCancelReason checks only the known reason codes are accepted.
+func TestValidCancelReason(t *testing.T) {
+	assert.True(t, model.ValidCancelReason(constant.CANCEL_REASON_CHANGED_MIND))
+	assert.True(t, model.ValidCancelReason(constant.CANCEL_REASON_OTHER))
+	assert.False(t, model.ValidCancelReason(""))
+	assert.False(t, model.ValidCancelReason("changed_mind"))
+}
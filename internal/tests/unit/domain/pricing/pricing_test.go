@@ -0,0 +1,74 @@
+package pricing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-online-store/internal/domain/pricing"
+	"go-online-store/internal/domain/product/model"
+	customErrors "go-online-store/pkg/errors"
+)
+
+func uintPtr(v uint) *uint {
+	return &v
+}
+
+// TestNewLineUsesVariantPrice checks variant lines carry the SKU and price of the variant.
+func TestNewLineUsesVariantPrice(t *testing.T) {
+	product := &model.Product{ID: 1, Name: "Shirt", Price: 100, Variants: []model.ProductVariant{
+		{ID: 7, ProductID: 1, SKU: "SHIRT-M", Price: 120},
+	}}
+
+	line, err := pricing.NewLine(product, uintPtr(7), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "SHIRT-M", line.SKU)
+	assert.Equal(t, 120.0, line.UnitPrice)
+	assert.Equal(t, 240.0, line.Subtotal)
+
+	_, err = pricing.NewLine(product, nil, 1)
+	assert.ErrorIs(t, err, customErrors.ErrVariantRequired)
+	_, err = pricing.NewLine(product, uintPtr(8), 1)
+	assert.ErrorIs(t, err, customErrors.ErrInvalidVariant)
+}
+
+// TestCalculate checks the breakdown adds shipping and tax; promotions are taken off separately.
+func TestCalculate(t *testing.T) {
+	mug, err := pricing.NewLine(&model.Product{ID: 1, Name: "Mug", Price: 50000}, nil, 2)
+	assert.NoError(t, err)
+	bowl, err := pricing.NewLine(&model.Product{ID: 2, Name: "Bowl", Price: 20000}, nil, 1)
+	assert.NoError(t, err)
+
+	breakdown := pricing.Calculate([]pricing.Line{mug, bowl})
+	assert.Equal(t, 120000.0, breakdown.Subtotal)
+	assert.Equal(t, 8000.0, breakdown.ShippingFee)
+	assert.InDelta(t, 12000.0, breakdown.Tax, 0.001)
+	assert.Equal(t, 0.0, breakdown.Discount)
+	assert.InDelta(t, 140000.0, breakdown.Total, 0.001)
+	assert.Equal(t, pricing.Currency, breakdown.Currency)
+	assert.Empty(t, breakdown.Promotions)
+}
+
+// TestApplyCapsDiscount checks promotions never take more than the subtotal and shipping fee.
+func TestApplyCapsDiscount(t *testing.T) {
+	mug, err := pricing.NewLine(&model.Product{ID: 1, Name: "Mug", Price: 50000}, nil, 1)
+	assert.NoError(t, err)
+	breakdown := pricing.Calculate([]pricing.Line{mug})
+
+	breakdown.Apply(pricing.Adjustment{Name: "Launch", Amount: 40000})
+	breakdown.Apply(pricing.Adjustment{Name: "Welcome", Amount: 40000})
+
+	assert.InDelta(t, 58000.0, breakdown.Discount, 0.001)
+	assert.InDelta(t, 5000.0, breakdown.Total, 0.001)
+	if assert.Len(t, breakdown.Promotions, 2) {
+		assert.InDelta(t, 18000.0, breakdown.Promotions[1].Amount, 0.001)
+	}
+}
+
+// TestCalculateEmpty checks an empty cart costs nothing.
+func TestCalculateEmpty(t *testing.T) {
+	breakdown := pricing.Calculate(nil)
+	assert.Equal(t, 0.0, breakdown.Total)
+	assert.Equal(t, 0.0, breakdown.ShippingFee)
+	assert.NotNil(t, breakdown.Lines)
+}
@@ -0,0 +1,136 @@
+package catalog
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-online-store/internal/domain/product/catalog"
+	"go-online-store/internal/domain/product/model"
+)
+
+func readAll(t *testing.T, format string, input string) ([]catalog.Row, []*catalog.RowError) {
+	reader, err := catalog.NewReader(format, strings.NewReader(input))
+	assert.NoError(t, err)
+
+	var rows []catalog.Row
+	var rowErrors []*catalog.RowError
+	for {
+		row, err := reader.Next()
+		if err == io.EOF {
+			return rows, rowErrors
+		}
+		if rowErr, ok := err.(*catalog.RowError); ok {
+			rowErrors = append(rowErrors, rowErr)
+			continue
+		}
+		assert.NoError(t, err)
+		rows = append(rows, row)
+	}
+}
+
+// TestParseFormat checks formats are recognized by name, file name and content type.
+func TestParseFormat(t *testing.T) {
+	for input, want := range map[string]string{
+		"CSV":                     catalog.FormatCSV,
+		"catalog.csv":             catalog.FormatCSV,
+		"text/csv; charset=utf-8": catalog.FormatCSV,
+		"export.ndjson":           catalog.FormatJSONL,
+		"application/x-ndjson":    catalog.FormatJSONL,
+	} {
+		format, err := catalog.ParseFormat(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, want, format, input)
+	}
+
+	_, err := catalog.ParseFormat("products.xlsx")
+	assert.ErrorIs(t, err, catalog.ErrUnsupportedFormat)
+}
+
+// TestCSVReaderReportsRowErrors checks columns are matched by header and bad rows do not stop the file.
+func TestCSVReaderReportsRowErrors(t *testing.T) {
+	input := "Name,SKU,price,stok,category,extra\n" +
+		"Mug,MUG-1,4.5,10,Kitchen,x\n" +
+		"Plate,,abc,3,Kitchen,x\n" +
+		"Bowl,BWL-1,2,,Kitchen,x\n"
+
+	rows, rowErrors := readAll(t, catalog.FormatCSV, input)
+
+	assert.Len(t, rows, 2)
+	assert.Equal(t, "MUG-1", rows[0].SKU)
+	assert.Equal(t, 4.5, *rows[0].Price)
+	assert.Equal(t, uint(10), *rows[0].Stock)
+	assert.Nil(t, rows[1].Stock)
+	assert.Equal(t, 4, rows[1].Line)
+
+	assert.Len(t, rowErrors, 1)
+	assert.Equal(t, 3, rowErrors[0].Line)
+	assert.Contains(t, rowErrors[0].Message, "invalid price")
+}
+
+// TestCSVReaderRequiresNameColumn checks a file without a name column is rejected outright.
+func TestCSVReaderRequiresNameColumn(t *testing.T) {
+	_, err := catalog.NewReader(catalog.FormatCSV, strings.NewReader("sku,price\nA,1\n"))
+	assert.ErrorIs(t, err, catalog.ErrInvalidFile)
+}
+
+// TestJSONLReaderSkipsBlankLines checks line numbers survive blank lines and invalid records.
+func TestJSONLReaderSkipsBlankLines(t *testing.T) {
+	input := `{"sku":"MUG-1","name":"Mug","category":"Kitchen","price":4.5}` + "\n\n" +
+		`{"name":` + "\n" +
+		`{"name":"Bowl","category":"Kitchen","price":2,"stok":7}` + "\n"
+
+	rows, rowErrors := readAll(t, catalog.FormatJSONL, input)
+
+	assert.Len(t, rows, 2)
+	assert.Equal(t, 1, rows[0].Line)
+	assert.Equal(t, 4, rows[1].Line)
+	assert.Equal(t, uint(7), *rows[1].Stock)
+	assert.Len(t, rowErrors, 1)
+	assert.Equal(t, 3, rowErrors[0].Line)
+}
+
+// TestRowValidateAndKey checks required fields and that SKU keys ignore case.
+func TestRowValidateAndKey(t *testing.T) {
+	price := 1.0
+	row := catalog.Row{SKU: " mug-1 ", Name: " Mug ", Category: "Kitchen", Price: &price}
+	row.Normalize()
+
+	assert.NoError(t, row.Validate())
+	assert.Equal(t, catalog.Row{SKU: "MUG-1", Name: "Cup"}.Key(), row.Key())
+	assert.Equal(t, "name:mug", catalog.Row{Name: "Mug"}.Key())
+
+	row.Price = nil
+	assert.EqualError(t, row.Validate(), "price is required")
+}
+
+// TestExportRoundTrip checks an export can be read back by the importer in both formats.
+func TestExportRoundTrip(t *testing.T) {
+	sku := "MUG-1"
+	products := []*model.Product{
+		{SKU: &sku, Name: "Mug, large", Category: "Kitchen", Description: "Holds \"a lot\"", Price: 4.5, Stok: 10},
+		{Name: "Bowl", Category: "Kitchen", Price: 2},
+	}
+
+	for _, format := range []string{catalog.FormatCSV, catalog.FormatJSONL} {
+		var buf bytes.Buffer
+		writer, err := catalog.NewWriter(format, &buf)
+		assert.NoError(t, err)
+		for _, product := range products {
+			assert.NoError(t, writer.Write(catalog.RowFromProduct(product)))
+		}
+		assert.NoError(t, writer.Flush())
+
+		rows, rowErrors := readAll(t, format, buf.String())
+		assert.Empty(t, rowErrors, format)
+		assert.Len(t, rows, 2, format)
+		assert.Equal(t, "MUG-1", rows[0].SKU, format)
+		assert.Equal(t, "Mug, large", rows[0].Name, format)
+		assert.Equal(t, "Holds \"a lot\"", rows[0].Description, format)
+		assert.Equal(t, uint(10), *rows[0].Stock, format)
+		assert.Equal(t, "", rows[1].SKU, format)
+	}
+}
@@ -0,0 +1,182 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"go-online-store/internal/domain/product/model"
+	"go-online-store/internal/domain/product/repository"
+	"go-online-store/internal/domain/product/service"
+	customErrors "go-online-store/pkg/errors"
+	"go-online-store/pkg/logger"
+	"go-online-store/pkg/storage"
+)
+
+// fakeProductRepo holds product 1 and its media in memory. Methods the media
+// service does not use are left to the embedded interface.
+type fakeProductRepo struct {
+	repository.ProductRepositoryImpl
+	media []model.ProductMedia
+}
+
+func (r *fakeProductRepo) GetByID(id uint) (*model.Product, error) {
+	if id != 1 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &model.Product{ID: 1, Name: "Mug"}, nil
+}
+
+func (r *fakeProductRepo) GetMediaByID(id uint) (*model.ProductMedia, error) {
+	for _, item := range r.media {
+		if item.ID == id {
+			return &item, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeProductRepo) GetMediaByProductID(productID uint) ([]model.ProductMedia, error) {
+	var items []model.ProductMedia
+	for _, item := range r.media {
+		if item.ProductID == productID {
+			items = append(items, item)
+		}
+	}
+	slices.SortFunc(items, func(a, b model.ProductMedia) int { return a.Position - b.Position })
+	return items, nil
+}
+
+func (r *fakeProductRepo) CreateMedia(item *model.ProductMedia) error {
+	item.ID = uint(len(r.media) + 1)
+	r.media = append(r.media, *item)
+	return nil
+}
+
+func (r *fakeProductRepo) ReorderMedia(productID uint, mediaIDs []uint) error {
+	for position, id := range mediaIDs {
+		for i := range r.media {
+			if r.media[i].ID == id && r.media[i].ProductID == productID {
+				r.media[i].Position = position
+			}
+		}
+	}
+	return nil
+}
+
+func newService(t *testing.T) (*service.MediaService, *fakeProductRepo, string) {
+	root := t.TempDir()
+	store, err := storage.NewLocalStorage(root, "http://localhost/media")
+	assert.NoError(t, err)
+	repo := &fakeProductRepo{}
+	return service.NewMediaService(repo, store, logger.NewLogger(io.Discard, "test")), repo, root
+}
+
+func pngImage(t *testing.T, width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+// TestUploadMedia checks an image is stored with its thumbnails and appended to the product's media.
+func TestUploadMedia(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, root := newService(t)
+
+	item, err := svc.UploadMedia(ctx, 1, pngImage(t, 1000, 500), "Red mug")
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", item.ContentType)
+	assert.Equal(t, 1000, item.Width)
+	assert.Equal(t, 500, item.Height)
+	assert.Equal(t, 0, item.Position)
+	assert.Len(t, item.Thumbnails, 3)
+	for _, key := range item.StorageKeys() {
+		_, err := os.Stat(filepath.Join(root, filepath.FromSlash(key)))
+		assert.NoError(t, err, key)
+	}
+
+	second, err := svc.UploadMedia(ctx, 1, pngImage(t, 10, 10), "")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, second.Position)
+	assert.Len(t, repo.media, 2)
+
+	_, err = svc.UploadMedia(ctx, 2, pngImage(t, 10, 10), "")
+	assert.ErrorIs(t, err, customErrors.ErrNotFound)
+}
+
+// TestUploadMediaRejectsUnsupportedFiles checks files that are not images, or are too large, are not stored.
+func TestUploadMediaRejectsUnsupportedFiles(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, root := newService(t)
+
+	_, err := svc.UploadMedia(ctx, 1, []byte("%PDF-1.4 not an image"), "")
+	assert.ErrorIs(t, err, customErrors.ErrUnsupportedMedia)
+
+	// Claims to be a PNG but cannot be decoded
+	broken := pngImage(t, 10, 10)[:32]
+	_, err = svc.UploadMedia(ctx, 1, broken, "")
+	assert.ErrorIs(t, err, customErrors.ErrUnsupportedMedia)
+
+	large := append(pngImage(t, 10, 10), make([]byte, service.MaxMediaSize)...)
+	_, err = svc.UploadMedia(ctx, 1, large, "")
+	assert.ErrorIs(t, err, customErrors.ErrMediaTooLarge)
+
+	assert.Empty(t, repo.media)
+	files, err := os.ReadDir(root)
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+// TestReorderMedia checks the new order must list every media of the product exactly once.
+func TestReorderMedia(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _ := newService(t)
+	repo.media = []model.ProductMedia{
+		{ID: 1, ProductID: 1, Position: 0},
+		{ID: 2, ProductID: 1, Position: 1},
+		{ID: 3, ProductID: 1, Position: 2},
+		{ID: 4, ProductID: 2, Position: 0},
+	}
+
+	for _, ids := range [][]uint{{3, 1}, {3, 1, 2, 4}, {3, 1, 1}, {3, 1, 4}} {
+		_, err := svc.ReorderMedia(ctx, 1, ids)
+		assert.ErrorIs(t, err, customErrors.ErrBadRequest, ids)
+	}
+
+	items, err := svc.ReorderMedia(ctx, 1, []uint{3, 1, 2})
+	assert.NoError(t, err)
+	var ids []uint
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	assert.Equal(t, []uint{3, 1, 2}, ids)
+
+	_, err = svc.ReorderMedia(ctx, 9, []uint{1})
+	assert.ErrorIs(t, err, customErrors.ErrNotFound)
+}
+
+// TestMediaDeletedRemovesFiles checks the files of media deleted with their product are removed from storage.
+func TestMediaDeletedRemovesFiles(t *testing.T) {
+	svc, _, root := newService(t)
+
+	item, err := svc.UploadMedia(context.Background(), 1, pngImage(t, 200, 200), "")
+	assert.NoError(t, err)
+
+	svc.MediaDeleted([]model.ProductMedia{*item})
+	for _, key := range item.StorageKeys() {
+		_, err := os.Stat(filepath.Join(root, filepath.FromSlash(key)))
+		assert.ErrorIs(t, err, os.ErrNotExist, key)
+	}
+}
@@ -0,0 +1,62 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-online-store/internal/domain/product/model"
+	"go-online-store/internal/domain/product/search"
+)
+
+func newTestIndex() *search.Index {
+	idx := search.NewIndex()
+	idx.Rebuild([]*model.Product{
+		{ID: 1, Name: "Running Shoes", Category: "Footwear", Description: "Lightweight shoes for daily running"},
+		{ID: 2, Name: "Leather Boots", Category: "Footwear", Description: "Waterproof boots"},
+		{ID: 3, Name: "Running Shorts", Category: "Apparel", Description: "Breathable shorts"},
+		{ID: 4, Name: "Coffee Mug", Category: "Kitchen", Description: "Ceramic mug, fits running shoes fans"},
+	})
+	return idx
+}
+
+// TestSearchRanksNameMatchesFirst checks that a match in the name outranks a description match.
+func TestSearchRanksNameMatchesFirst(t *testing.T) {
+	results := newTestIndex().Search("running shoes", 10)
+
+	assert.NotEmpty(t, results)
+	assert.Equal(t, uint(1), results[0].Product.ID)
+	assert.Equal(t, "<em>Running</em> <em>Shoes</em>", results[0].Highlights["name"])
+}
+
+// TestSearchToleratesTypos checks that misspelled terms still match.
+func TestSearchToleratesTypos(t *testing.T) {
+	results := newTestIndex().Search("lether bots", 10)
+
+	assert.NotEmpty(t, results)
+	assert.Equal(t, uint(2), results[0].Product.ID)
+}
+
+// TestSearchMatchesPrefixOfLastTerm checks search-as-you-type behaviour.
+func TestSearchMatchesPrefixOfLastTerm(t *testing.T) {
+	results := newTestIndex().Search("foot", 10)
+
+	ids := make([]uint, 0, len(results))
+	for _, r := range results {
+		ids = append(ids, r.Product.ID)
+	}
+	assert.ElementsMatch(t, []uint{1, 2}, ids)
+}
+
+// TestIndexUpsertAndRemove checks that writes are reflected in results.
+func TestIndexUpsertAndRemove(t *testing.T) {
+	idx := newTestIndex()
+
+	idx.Upsert(&model.Product{ID: 2, Name: "Suede Boots", Category: "Footwear"})
+	assert.Empty(t, idx.Search("leather", 10))
+	assert.NotEmpty(t, idx.Search("suede", 10))
+
+	idx.Remove(2)
+	assert.Empty(t, idx.Search("boots", 10))
+	assert.Equal(t, 3, idx.Len())
+}
@@ -0,0 +1,285 @@
+package promotion
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	categoryModel "go-online-store/internal/domain/category/model"
+	"go-online-store/internal/domain/pricing"
+	productModel "go-online-store/internal/domain/product/model"
+	"go-online-store/internal/domain/promotion/model"
+	"go-online-store/internal/domain/promotion/repository"
+	"go-online-store/internal/domain/promotion/service"
+	"go-online-store/pkg/constant"
+	customErrors "go-online-store/pkg/errors"
+	"go-online-store/pkg/logger"
+)
+
+func uintPtr(v uint) *uint {
+	return &v
+}
+
+func strPtr(v string) *string {
+	return &v
+}
+
+// cart is three mugs from category 1 and a bowl from category 2, with
+// 170000 in lines and 8000 shipping.
+func cart(t *testing.T) pricing.Breakdown {
+	mug, err := pricing.NewLine(&productModel.Product{ID: 1, Name: "Mug", Price: 50000, CategoryID: uintPtr(1)}, nil, 3)
+	assert.NoError(t, err)
+	bowl, err := pricing.NewLine(&productModel.Product{ID: 2, Name: "Bowl", Price: 20000, CategoryID: uintPtr(2)}, nil, 1)
+	assert.NoError(t, err)
+	return pricing.Calculate([]pricing.Line{mug, bowl})
+}
+
+// TestPromotionDiscountByType checks what each kind of rule takes off a cart.
+func TestPromotionDiscountByType(t *testing.T) {
+	b := cart(t)
+
+	percentage := model.Promotion{Type: constant.PROMOTION_TYPE_PERCENTAGE, Value: 0.1}
+	assert.InDelta(t, 17000.0, percentage.Discount(b), 0.001)
+
+	fixed := model.Promotion{Type: constant.PROMOTION_TYPE_FIXED, Value: 20000}
+	assert.Equal(t, 20000.0, fixed.Discount(b))
+	fixed.Value = 500000
+	assert.Equal(t, 170000.0, fixed.Discount(b))
+
+	shipping := model.Promotion{Type: constant.PROMOTION_TYPE_FREE_SHIPPING}
+	assert.Equal(t, 8000.0, shipping.Discount(b))
+
+	// Buy two, get one free: one of the three mugs, none of the single bowl
+	bogo := model.Promotion{Type: constant.PROMOTION_TYPE_BUY_X_GET_Y, BuyQuantity: 2, GetQuantity: 1}
+	assert.Equal(t, 50000.0, bogo.Discount(b))
+
+	// A stored rule with no quantities gives nothing rather than dividing by zero
+	empty := model.Promotion{Type: constant.PROMOTION_TYPE_BUY_X_GET_Y}
+	assert.Equal(t, 0.0, empty.Discount(b))
+}
+
+// TestPromotionCategoryScope checks scoped rules only count, and discount, products of their categories.
+func TestPromotionCategoryScope(t *testing.T) {
+	b := cart(t)
+
+	bowls := model.Promotion{Type: constant.PROMOTION_TYPE_PERCENTAGE, Value: 0.5, CategoryID: uintPtr(2)}
+	assert.Equal(t, 10000.0, bowls.Discount(b))
+
+	// Minimum spend is measured on the products in scope
+	bowls.MinSpend = 30000
+	assert.Equal(t, 0.0, bowls.Discount(b))
+
+	// A parent category covers the categories below it
+	kitchen := model.Promotion{Type: constant.PROMOTION_TYPE_FIXED, Value: 5000, CategoryID: uintPtr(9), Scope: []uint{9, 2}}
+	assert.Equal(t, 5000.0, kitchen.Discount(b))
+	kitchen.Scope = nil
+	assert.Equal(t, 0.0, kitchen.Discount(b))
+}
+
+// TestPromotionLiveAndAvailable checks the switch, the validity window and the usage limits.
+func TestPromotionLiveAndAvailable(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	start, end := now.Add(-time.Hour), now.Add(time.Hour)
+	promotion := model.Promotion{Active: true, StartsAt: &start, EndsAt: &end}
+
+	assert.True(t, promotion.Live(now))
+	assert.False(t, promotion.Live(start.Add(-time.Minute)))
+	assert.False(t, promotion.Live(end))
+	promotion.Active = false
+	assert.False(t, promotion.Live(now))
+
+	limited := model.Promotion{UsageLimit: 10, PerCustomerLimit: 1}
+	assert.True(t, limited.Available(9, 0))
+	assert.False(t, limited.Available(10, 0))
+	assert.False(t, limited.Available(3, 1))
+	assert.True(t, (&model.Promotion{}).Available(1000, 1000))
+}
+
+// TestPromotionValidate checks every rule needs what its type uses.
+func TestPromotionValidate(t *testing.T) {
+	start := time.Now()
+	valid := []model.Promotion{
+		{Name: "Ten off", Type: constant.PROMOTION_TYPE_PERCENTAGE, Value: 0.1},
+		{Name: "Voucher", Type: constant.PROMOTION_TYPE_FIXED, Value: 10000},
+		{Name: "Free shipping", Type: constant.PROMOTION_TYPE_FREE_SHIPPING},
+		{Name: "Two for one", Type: constant.PROMOTION_TYPE_BUY_X_GET_Y, BuyQuantity: 1, GetQuantity: 1},
+	}
+	for _, promotion := range valid {
+		assert.NoError(t, promotion.Validate(), promotion.Name)
+	}
+
+	invalid := []model.Promotion{
+		{Type: constant.PROMOTION_TYPE_FREE_SHIPPING},
+		{Name: "Too much", Type: constant.PROMOTION_TYPE_PERCENTAGE, Value: 1.5},
+		{Name: "Nothing", Type: constant.PROMOTION_TYPE_FIXED},
+		{Name: "Get nothing", Type: constant.PROMOTION_TYPE_BUY_X_GET_Y, BuyQuantity: 2},
+		{Name: "Unknown", Type: "CASHBACK", Value: 1},
+		{Name: "Backwards", Type: constant.PROMOTION_TYPE_FREE_SHIPPING, StartsAt: &start, EndsAt: &start},
+	}
+	for _, promotion := range invalid {
+		assert.ErrorIs(t, promotion.Validate(), customErrors.ErrInvalidPromotion, promotion.Name)
+	}
+}
+
+// TestCombine checks the stacking rules pick the promotions to apply.
+func TestCombine(t *testing.T) {
+	b := cart(t)
+	tenOff := model.Promotion{ID: 1, Name: "Ten off", Type: constant.PROMOTION_TYPE_PERCENTAGE, Value: 0.1, Stackable: true}
+	shipping := model.Promotion{ID: 2, Name: "Free shipping", Type: constant.PROMOTION_TYPE_FREE_SHIPPING, Stackable: true}
+	bogo := model.Promotion{ID: 3, Name: "Mugs", Type: constant.PROMOTION_TYPE_BUY_X_GET_Y, BuyQuantity: 2, GetQuantity: 1}
+	voucher := model.Promotion{ID: 4, Name: "Voucher", Code: strPtr("SAVE5"), Type: constant.PROMOTION_TYPE_FIXED, Value: 5000}
+
+	// Stackable promotions (25000) lose to the best one that is not (50000)
+	adjustments := model.Combine(b, []model.Promotion{tenOff, shipping, bogo}, nil)
+	if assert.Len(t, adjustments, 1) {
+		assert.Equal(t, uint(3), *adjustments[0].PromotionID)
+	}
+
+	// ...and win when they are worth more
+	adjustments = model.Combine(b, []model.Promotion{tenOff, shipping, shipping}, nil)
+	assert.Len(t, adjustments, 2, "shipping is waived once")
+
+	// A coupon that is not stackable is applied alone, even when worth less
+	adjustments = model.Combine(b, []model.Promotion{tenOff, bogo}, &voucher)
+	if assert.Len(t, adjustments, 1) {
+		assert.Equal(t, "SAVE5", adjustments[0].Code)
+	}
+
+	// A stackable coupon joins the stackable automatic promotions
+	voucher.Stackable = true
+	adjustments = model.Combine(b, []model.Promotion{tenOff, bogo}, &voucher)
+	assert.Len(t, adjustments, 2)
+
+	// A coupon the cart does not qualify for applies nothing
+	voucher.MinSpend = 500000
+	assert.Empty(t, model.Combine(b, []model.Promotion{tenOff}, &voucher))
+}
+
+// fakePromotionRepo keeps promotions in memory with a fixed use count per
+// customer.
+type fakePromotionRepo struct {
+	promotions []model.Promotion
+	uses       map[uint]int64
+}
+
+func (r *fakePromotionRepo) Create(promotion *model.Promotion) error {
+	promotion.ID = uint(len(r.promotions) + 1)
+	r.promotions = append(r.promotions, *promotion)
+	return nil
+}
+
+func (r *fakePromotionRepo) Update(promotion *model.Promotion) error { return nil }
+
+func (r *fakePromotionRepo) GetByID(id uint) (*model.Promotion, error) {
+	for _, promotion := range r.promotions {
+		if promotion.ID == id {
+			return &promotion, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakePromotionRepo) GetByCode(code string) (*model.Promotion, error) {
+	for _, promotion := range r.promotions {
+		if promotion.Code != nil && *promotion.Code == code {
+			return &promotion, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakePromotionRepo) GetAll() ([]model.Promotion, error) { return r.promotions, nil }
+
+func (r *fakePromotionRepo) GetAutomatic(now time.Time) ([]model.Promotion, error) {
+	var promotions []model.Promotion
+	for _, promotion := range r.promotions {
+		if promotion.Code == nil && promotion.Live(now) {
+			promotions = append(promotions, promotion)
+		}
+	}
+	return promotions, nil
+}
+
+func (r *fakePromotionRepo) GetForUpdate(id uint) (*model.Promotion, error) { return r.GetByID(id) }
+
+func (r *fakePromotionRepo) CountUses(promotionID uint, customerID uint) (int64, error) {
+	return r.uses[customerID], nil
+}
+
+func (r *fakePromotionRepo) WithTx(tx *gorm.DB) repository.PromotionRepositoryImpl { return r }
+
+// fakeCategoryRepo holds kitchen (9) with bowls (2) below it.
+type fakeCategoryRepo struct{}
+
+func (fakeCategoryRepo) Create(category *categoryModel.Category) error { return nil }
+func (fakeCategoryRepo) Update(category *categoryModel.Category) error { return nil }
+func (fakeCategoryRepo) Delete(id uint) error                          { return nil }
+func (fakeCategoryRepo) GetByID(id uint) (*categoryModel.Category, error) {
+	if id != 9 && id != 2 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &categoryModel.Category{ID: id}, nil
+}
+func (fakeCategoryRepo) GetBySlug(slug string) (*categoryModel.Category, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+func (fakeCategoryRepo) GetAll() ([]*categoryModel.Category, error) {
+	return []*categoryModel.Category{{ID: 9}, {ID: 2, ParentID: uintPtr(9)}}, nil
+}
+func (fakeCategoryRepo) ReparentChildren(fromID uint, toID *uint) error { return nil }
+
+func newService(repo *fakePromotionRepo) *service.PromotionService {
+	return service.NewPromotionService(repo, fakeCategoryRepo{}, logger.NewLogger(io.Discard, "test"))
+}
+
+// TestApplyPromotions checks used up promotions are skipped and category scopes cover subcategories.
+func TestApplyPromotions(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakePromotionRepo{uses: map[uint]int64{7: 1}}
+	svc := newService(repo)
+
+	_, err := svc.CreatePromotion(ctx, model.Promotion{Name: "Welcome", Type: constant.PROMOTION_TYPE_FIXED, Value: 15000, PerCustomerLimit: 1, Stackable: true, Active: true})
+	assert.NoError(t, err)
+	_, err = svc.CreatePromotion(ctx, model.Promotion{Name: "Kitchen", Type: constant.PROMOTION_TYPE_PERCENTAGE, Value: 0.5, CategoryID: uintPtr(9), Stackable: true, Active: true})
+	assert.NoError(t, err)
+
+	b := cart(t)
+	assert.NoError(t, svc.ApplyPromotions(ctx, 7, &b, nil))
+	if assert.Len(t, b.Promotions, 1, "customer 7 used the welcome discount") {
+		assert.Equal(t, "Kitchen", b.Promotions[0].Name)
+		assert.Equal(t, 10000.0, b.Promotions[0].Amount)
+	}
+
+	b = cart(t)
+	assert.NoError(t, svc.ApplyPromotions(ctx, 8, &b, nil))
+	assert.Len(t, b.Promotions, 2)
+	assert.InDelta(t, 170000.0, b.Total, 0.001)
+}
+
+// TestCoupons checks codes are case-insensitive, unique, and rejected when they do not apply.
+func TestCoupons(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakePromotionRepo{}
+	svc := newService(repo)
+
+	created, err := svc.CreatePromotion(ctx, model.Promotion{Name: "Big spender", Code: strPtr(" big50 "), Type: constant.PROMOTION_TYPE_FIXED, Value: 50000, MinSpend: 500000, Active: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "BIG50", *created.Code)
+
+	_, err = svc.CreatePromotion(ctx, model.Promotion{Name: "Copy", Code: strPtr("Big50"), Type: constant.PROMOTION_TYPE_FREE_SHIPPING, Active: true})
+	assert.ErrorIs(t, err, customErrors.ErrDuplicatePromotionCode)
+	_, err = svc.CreatePromotion(ctx, model.Promotion{Name: "Nowhere", Type: constant.PROMOTION_TYPE_FREE_SHIPPING, CategoryID: uintPtr(4)})
+	assert.ErrorIs(t, err, customErrors.ErrInvalidCategory)
+
+	_, err = svc.GetCoupon(ctx, 1, "nope")
+	assert.ErrorIs(t, err, customErrors.ErrNotFound)
+
+	coupon, err := svc.GetCoupon(ctx, 1, "big50")
+	assert.NoError(t, err)
+	b := cart(t)
+	assert.ErrorIs(t, svc.ApplyPromotions(ctx, 1, &b, coupon), customErrors.ErrPromotionNotApplicable)
+}
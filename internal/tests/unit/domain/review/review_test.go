@@ -0,0 +1,203 @@
+package review
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	orderModel "go-online-store/internal/domain/order/model"
+	repoOrder "go-online-store/internal/domain/order/repository"
+	productModel "go-online-store/internal/domain/product/model"
+	repoProduct "go-online-store/internal/domain/product/repository"
+	"go-online-store/internal/domain/review/model"
+	"go-online-store/internal/domain/review/service"
+	"go-online-store/internal/middleware/jwt"
+	"go-online-store/pkg/constant"
+	customErrors "go-online-store/pkg/errors"
+	"go-online-store/pkg/logger"
+)
+
+// TestRatingSummaryRounded checks the average shown to shoppers is rounded to one decimal.
+func TestRatingSummaryRounded(t *testing.T) {
+	assert.Equal(t, 4.3, model.RatingSummary{Average: 4.3333, Count: 3}.Rounded())
+	assert.Equal(t, 4.5, model.RatingSummary{Average: 4.45, Count: 20}.Rounded())
+	assert.Equal(t, 0.0, model.RatingSummary{}.Rounded())
+}
+
+// TestValidSort checks the supported review orderings.
+func TestValidSort(t *testing.T) {
+	for _, sort := range []string{model.SortHelpful, model.SortNewest, model.SortRatingAsc, model.SortRatingDesc} {
+		assert.True(t, model.ValidSort(sort), sort)
+	}
+	assert.False(t, model.ValidSort("-helpful"))
+	assert.False(t, model.ValidSort(""))
+}
+
+// fakeReviewRepo keeps reviews in memory.
+type fakeReviewRepo struct {
+	reviews []model.Review
+}
+
+func (r *fakeReviewRepo) Create(review *model.Review) error {
+	review.ID = uint(len(r.reviews) + 1)
+	r.reviews = append(r.reviews, *review)
+	return nil
+}
+
+func (r *fakeReviewRepo) Update(review *model.Review) error {
+	for i := range r.reviews {
+		if r.reviews[i].ID == review.ID {
+			r.reviews[i] = *review
+		}
+	}
+	return nil
+}
+
+func (r *fakeReviewRepo) Delete(id uint) error { return nil }
+
+func (r *fakeReviewRepo) GetByID(id uint) (*model.Review, error) {
+	for _, review := range r.reviews {
+		if review.ID == id {
+			return &review, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeReviewRepo) GetByProductAndCustomer(productID uint, customerID uint) (*model.Review, error) {
+	for _, review := range r.reviews {
+		if review.ProductID == productID && review.CustomerID == customerID {
+			return &review, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeReviewRepo) List(query model.ReviewQuery) (*model.ReviewPage, error) {
+	return &model.ReviewPage{Reviews: r.reviews, Total: int64(len(r.reviews))}, nil
+}
+
+func (r *fakeReviewRepo) RatingSummary(productID uint) (model.RatingSummary, error) {
+	var summary model.RatingSummary
+	var total uint
+	for _, review := range r.reviews {
+		if review.ProductID == productID && review.Status == constant.REVIEW_STATUS_APPROVED {
+			total += review.Rating
+			summary.Count++
+		}
+	}
+	if summary.Count > 0 {
+		summary.Average = float64(total) / float64(summary.Count)
+	}
+	return summary, nil
+}
+
+func (r *fakeReviewRepo) Vote(vote *model.ReviewVote) error { return nil }
+
+// fakeProductRepo holds product 1 and records its rating. Methods the
+// review service does not use are left to the embedded interface.
+type fakeProductRepo struct {
+	repoProduct.ProductRepositoryImpl
+	product productModel.Product
+}
+
+func (r *fakeProductRepo) GetByID(id uint) (*productModel.Product, error) {
+	if id != r.product.ID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	product := r.product
+	return &product, nil
+}
+
+func (r *fakeProductRepo) UpdateRating(productID uint, average float64, count uint) error {
+	r.product.RatingAverage = average
+	r.product.RatingCount = count
+	return nil
+}
+
+// fakeOrderRepo knows which customers have a paid order with which products.
+type fakeOrderRepo struct {
+	repoOrder.OrderRepositoryImpl
+	paid map[uint][]uint
+}
+
+func (r *fakeOrderRepo) GetPaidOrderWithProduct(customerID uint, productID uint) (*orderModel.Order, error) {
+	for _, id := range r.paid[customerID] {
+		if id == productID {
+			return &orderModel.Order{ID: 100 + customerID, CustomerID: customerID}, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// newService returns a review service for product 1, bought by customers 1
+// and 2 but not 3.
+func newService() (*service.ReviewService, *fakeReviewRepo, *fakeProductRepo) {
+	reviews := &fakeReviewRepo{}
+	products := &fakeProductRepo{product: productModel.Product{ID: 1, Name: "Mug"}}
+	orders := &fakeOrderRepo{paid: map[uint][]uint{1: {1}, 2: {1}}}
+	return service.NewReviewService(reviews, products, orders, logger.NewLogger(io.Discard, "test")), reviews, products
+}
+
+func customer(id uint) context.Context {
+	return jwt.WithCustomer(context.Background(), jwt.Customer{ID: id})
+}
+
+// TestCreateReviewRequiresPurchase checks only buyers may review a product, once, and reviews wait for moderation.
+func TestCreateReviewRequiresPurchase(t *testing.T) {
+	svc, reviews, _ := newService()
+
+	review, err := svc.CreateReview(customer(1), 1, model.Review{Rating: 5, Body: " Great mug ", Status: constant.REVIEW_STATUS_APPROVED})
+	assert.NoError(t, err)
+	assert.Equal(t, constant.REVIEW_STATUS_PENDING, review.Status)
+	assert.Equal(t, uint(101), review.OrderID)
+	assert.Equal(t, "Great mug", review.Body)
+	assert.Len(t, reviews.reviews, 1)
+
+	_, err = svc.CreateReview(customer(1), 1, model.Review{Rating: 4, Body: "Again"})
+	assert.ErrorIs(t, err, customErrors.ErrDuplicateReview)
+
+	_, err = svc.CreateReview(customer(3), 1, model.Review{Rating: 4, Body: "Never bought it"})
+	assert.ErrorIs(t, err, customErrors.ErrReviewNotAllowed)
+
+	_, err = svc.CreateReview(customer(2), 1, model.Review{Rating: 6, Body: "Too good"})
+	assert.ErrorIs(t, err, customErrors.ErrInvalidReview)
+
+	_, err = svc.CreateReview(customer(2), 2, model.Review{Rating: 4, Body: "Unknown product"})
+	assert.ErrorIs(t, err, customErrors.ErrNotFound)
+	assert.Len(t, reviews.reviews, 1)
+}
+
+// TestModerateReviewUpdatesRating checks approving and rejecting reviews refreshes the product rating.
+func TestModerateReviewUpdatesRating(t *testing.T) {
+	ctx := context.Background()
+	svc, _, products := newService()
+
+	first, err := svc.CreateReview(customer(1), 1, model.Review{Rating: 5, Body: "Great mug"})
+	assert.NoError(t, err)
+	second, err := svc.CreateReview(customer(2), 1, model.Review{Rating: 2, Body: "Chipped"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(0), products.product.RatingCount)
+
+	_, err = svc.ModerateReview(ctx, first.ID, constant.REVIEW_STATUS_APPROVED, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 5.0, products.product.RatingAverage)
+	assert.Equal(t, uint(1), products.product.RatingCount)
+
+	_, err = svc.ModerateReview(ctx, second.ID, constant.REVIEW_STATUS_APPROVED, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 3.5, products.product.RatingAverage)
+	assert.Equal(t, uint(2), products.product.RatingCount)
+
+	rejected, err := svc.ModerateReview(ctx, first.ID, constant.REVIEW_STATUS_REJECTED, " Off topic ")
+	assert.NoError(t, err)
+	assert.Equal(t, "Off topic", rejected.ModerationNote)
+	assert.Equal(t, 2.0, products.product.RatingAverage)
+	assert.Equal(t, uint(1), products.product.RatingCount)
+
+	_, err = svc.ModerateReview(ctx, first.ID, constant.REVIEW_STATUS_PENDING, "")
+	assert.ErrorIs(t, err, customErrors.ErrInvalidReview)
+}
@@ -0,0 +1,71 @@
+package wishlist
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-online-store/internal/domain/wishlist/model"
+)
+
+func newWishlist() *model.Wishlist {
+	variantID := uint(7)
+	return &model.Wishlist{
+		ID:         1,
+		CustomerID: 42,
+		Name:       "Birthday",
+		Items: []model.WishlistItem{
+			{ID: 10, WishlistID: 1, ProductID: 3},
+			{ID: 11, WishlistID: 1, ProductID: 3, VariantID: &variantID},
+		},
+	}
+}
+
+// TestFindItemMatchesVariant checks a product and each of its variants are separate items.
+func TestFindItemMatchesVariant(t *testing.T) {
+	wishlist := newWishlist()
+
+	item, ok := wishlist.FindItem(3, nil)
+	assert.True(t, ok)
+	assert.Equal(t, uint(10), item.ID)
+
+	variantID := uint(7)
+	item, ok = wishlist.FindItem(3, &variantID)
+	assert.True(t, ok)
+	assert.Equal(t, uint(11), item.ID)
+
+	otherVariant := uint(8)
+	_, ok = wishlist.FindItem(3, &otherVariant)
+	assert.False(t, ok)
+	_, ok = wishlist.FindItem(4, nil)
+	assert.False(t, ok)
+}
+
+// TestItemByID checks items are looked up by their own ID.
+func TestItemByID(t *testing.T) {
+	wishlist := newWishlist()
+
+	item, ok := wishlist.Item(11)
+	assert.True(t, ok)
+	assert.Equal(t, uint(3), item.ProductID)
+
+	_, ok = wishlist.Item(99)
+	assert.False(t, ok)
+}
+
+// TestSharedHidesOwner checks the shared view carries the items but not the owner or token.
+func TestSharedHidesOwner(t *testing.T) {
+	token := "abc"
+	wishlist := newWishlist()
+	wishlist.ShareToken = &token
+
+	shared := wishlist.Shared()
+	assert.Equal(t, "Birthday", shared.Name)
+	assert.Len(t, shared.Items, 2)
+
+	body, err := json.Marshal(shared)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(body), "customer_id")
+	assert.NotContains(t, string(body), "share_token")
+}
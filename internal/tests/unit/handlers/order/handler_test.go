@@ -0,0 +1,246 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"go-online-store/internal/domain/order/model"
+	"go-online-store/internal/handlers/order"
+	valiator "go-online-store/internal/middleware/validator"
+	customErrors "go-online-store/pkg/errors"
+	"go-online-store/pkg/pagination"
+)
+
+type MockOrderService struct {
+	mock.Mock
+}
+
+func (m *MockOrderService) Checkout(ctx context.Context, discountCode string) (*model.Order, error) {
+	args := m.Called(discountCode)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Order), nil
+}
+
+func (m *MockOrderService) UpdatePaymentStatus(ctx context.Context, orderID uint) error {
+	args := m.Called(orderID)
+	return args.Error(0)
+}
+
+func (m *MockOrderService) GetOrders(ctx context.Context, query model.OrderQuery) (*model.OrderPage, error) {
+	args := m.Called(query)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.OrderPage), nil
+}
+
+func (m *MockOrderService) GetOrder(ctx context.Context, orderID uint) (*model.Order, error) {
+	args := m.Called(orderID)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Order), nil
+}
+
+func (m *MockOrderService) UpdateOrderStatus(ctx context.Context, orderID uint, status string, note string) (*model.Order, error) {
+	args := m.Called(orderID, status, note)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Order), nil
+}
+
+func (m *MockOrderService) CancelOrder(ctx context.Context, orderID uint, reason string, note string) (*model.Order, error) {
+	args := m.Called(orderID, reason, note)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Order), nil
+}
+
+func (m *MockOrderService) ReleaseExpiredReservations(ctx context.Context) (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockOrderService) StartReservationSweeper(ctx context.Context, interval time.Duration) {
+	m.Called(interval)
+}
+
+func newContext(method, target, body string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	e.Validator = &valiator.CustomValidator{Validator: validator.New()}
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	return e.NewContext(req, rec), rec
+}
+
+// TestGetOrdersFilters checks the status, inclusive date range and page passed to the service and the next-page link.
+func TestGetOrdersFilters(t *testing.T) {
+	mockService := new(MockOrderService)
+	handler := order.NewOrderHandler(mockService)
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	query := model.OrderQuery{
+		Status:     "PENDING",
+		From:       &from,
+		To:         &to,
+		Pagination: pagination.Params{Page: 1, Limit: 2},
+	}
+	mockService.On("GetOrders", query).Return(&model.OrderPage{Orders: []model.Order{{ID: 9}, {ID: 8}}, Total: 5}, nil)
+
+	c, rec := newContext(http.MethodGet, "/v1/orders?status=PENDING&from=2024-01-01&to=2024-01-31&limit=2", "")
+
+	err := handler.GetOrdersHandler(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var body order.OrderListResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Len(t, body.Data, 2)
+	assert.Equal(t, 3, body.Meta.TotalPages)
+	assert.Contains(t, body.Meta.Next, "page=2")
+	mockService.AssertExpectations(t)
+}
+
+// TestGetOrdersInvalidDate checks a malformed date is rejected before reaching the service.
+func TestGetOrdersInvalidDate(t *testing.T) {
+	mockService := new(MockOrderService)
+	handler := order.NewOrderHandler(mockService)
+
+	c, _ := newContext(http.MethodGet, "/v1/orders?from=01-01-2024", "")
+
+	err := handler.GetOrdersHandler(c)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+	mockService.AssertNotCalled(t, "GetOrders", mock.Anything)
+}
+
+// TestGetOrderOfAnotherCustomer checks an order the customer does not own maps to 404.
+func TestGetOrderOfAnotherCustomer(t *testing.T) {
+	mockService := new(MockOrderService)
+	handler := order.NewOrderHandler(mockService)
+
+	mockService.On("GetOrder", uint(42)).Return(nil, customErrors.ErrNotFound)
+
+	c, _ := newContext(http.MethodGet, "/v1/orders/42", "")
+	c.SetParamNames("id")
+	c.SetParamValues("42")
+
+	err := handler.GetOrderHandler(c)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusNotFound, httpErr.Code)
+	mockService.AssertExpectations(t)
+}
+
+// TestUpdateOrderStatusRejectsCancel checks cancelling is not accepted by the status endpoint.
+func TestUpdateOrderStatusRejectsCancel(t *testing.T) {
+	mockService := new(MockOrderService)
+	handler := order.NewOrderHandler(mockService)
+
+	c, _ := newContext(http.MethodPut, "/v1/orders/7/status", `{"status": "CANCELLED"}`)
+	c.SetParamNames("id")
+	c.SetParamValues("7")
+
+	err := handler.UpdateOrderStatusHandler(c)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+	mockService.AssertNotCalled(t, "UpdateOrderStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestUpdateOrderStatusInvalidTransition checks a change the lifecycle does not allow maps to 409.
+func TestUpdateOrderStatusInvalidTransition(t *testing.T) {
+	mockService := new(MockOrderService)
+	handler := order.NewOrderHandler(mockService)
+
+	mockService.On("UpdateOrderStatus", uint(7), "DELIVERED", "").Return(nil, customErrors.ErrInvalidTransition)
+
+	c, _ := newContext(http.MethodPut, "/v1/orders/7/status", `{"status": "DELIVERED"}`)
+	c.SetParamNames("id")
+	c.SetParamValues("7")
+
+	err := handler.UpdateOrderStatusHandler(c)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusConflict, httpErr.Code)
+	mockService.AssertExpectations(t)
+}
+
+// TestCancelOrderRequiresReason checks a cancellation without a known reason code is rejected.
+func TestCancelOrderRequiresReason(t *testing.T) {
+	mockService := new(MockOrderService)
+	handler := order.NewOrderHandler(mockService)
+
+	for _, body := range []string{`{}`, `{"reason": "BORED"}`} {
+		c, _ := newContext(http.MethodPost, "/v1/orders/7/cancel", body)
+		c.SetParamNames("id")
+		c.SetParamValues("7")
+
+		err := handler.CancelOrderHandler(c)
+
+		httpErr, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+	}
+	mockService.AssertNotCalled(t, "CancelOrder", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestCancelOrder checks a cancelled order is returned.
+func TestCancelOrder(t *testing.T) {
+	mockService := new(MockOrderService)
+	handler := order.NewOrderHandler(mockService)
+
+	cancelled := &model.Order{ID: 7, OrderStatus: "CANCELLED", CancelReason: "CHANGED_MIND"}
+	mockService.On("CancelOrder", uint(7), "CHANGED_MIND", "too late").Return(cancelled, nil)
+
+	c, rec := newContext(http.MethodPost, "/v1/orders/7/cancel", `{"reason": "CHANGED_MIND", "note": "too late"}`)
+	c.SetParamNames("id")
+	c.SetParamValues("7")
+
+	err := handler.CancelOrderHandler(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"cancel_reason":"CHANGED_MIND"`)
+	mockService.AssertExpectations(t)
+}
+
+// TestCancelShippedOrder checks a customer cancelling a shipped order maps to 403.
+func TestCancelShippedOrder(t *testing.T) {
+	mockService := new(MockOrderService)
+	handler := order.NewOrderHandler(mockService)
+
+	mockService.On("CancelOrder", uint(7), "OTHER", "").Return(nil, customErrors.ErrForbidden)
+
+	c, _ := newContext(http.MethodPost, "/v1/orders/7/cancel", `{"reason": "OTHER"}`)
+	c.SetParamNames("id")
+	c.SetParamValues("7")
+
+	err := handler.CancelOrderHandler(c)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusForbidden, httpErr.Code)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,268 @@
+package product
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"go-online-store/internal/domain/product/catalog"
+	"go-online-store/internal/domain/product/model"
+	"go-online-store/internal/domain/product/search"
+	"go-online-store/internal/handlers/product"
+	valiator "go-online-store/internal/middleware/validator"
+	customErrors "go-online-store/pkg/errors"
+	"go-online-store/pkg/pagination"
+)
+
+type MockProductService struct {
+	mock.Mock
+}
+
+func (m *MockProductService) GetProductList(ctx context.Context) ([]*model.Product, error) {
+	args := m.Called()
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Product), nil
+}
+
+func (m *MockProductService) GetProductListByCategory(ctx context.Context, category string) ([]*model.Product, error) {
+	args := m.Called(category)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Product), nil
+}
+
+func (m *MockProductService) GetProductPage(ctx context.Context, query model.ProductQuery) (*model.ProductPage, error) {
+	args := m.Called(query)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ProductPage), nil
+}
+
+func (m *MockProductService) GetProductById(ctx context.Context, productId uint) (*model.Product, error) {
+	args := m.Called(productId)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Product), nil
+}
+
+func (m *MockProductService) CreateProduct(ctx context.Context, p model.Product) (*model.Product, error) {
+	args := m.Called(p)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Product), nil
+}
+
+func (m *MockProductService) UpdateProduct(ctx context.Context, p model.Product) (*model.Product, error) {
+	args := m.Called(p)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Product), nil
+}
+
+func (m *MockProductService) DeleteProduct(ctx context.Context, productId uint) error {
+	args := m.Called(productId)
+	return args.Error(0)
+}
+
+func (m *MockProductService) SearchProducts(ctx context.Context, query string, limit int) ([]search.Result, error) {
+	args := m.Called(query, limit)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]search.Result), nil
+}
+
+func (m *MockProductService) SetProductOptions(ctx context.Context, productId uint, options []model.ProductOption) (*model.Product, error) {
+	args := m.Called(productId, options)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Product), nil
+}
+
+func (m *MockProductService) GetProductVariants(ctx context.Context, productId uint) ([]model.ProductVariant, error) {
+	args := m.Called(productId)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.ProductVariant), nil
+}
+
+func (m *MockProductService) CreateVariant(ctx context.Context, productId uint, variant model.ProductVariant) (*model.ProductVariant, error) {
+	args := m.Called(productId, variant)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ProductVariant), nil
+}
+
+func (m *MockProductService) UpdateVariant(ctx context.Context, productId uint, variant model.ProductVariant) (*model.ProductVariant, error) {
+	args := m.Called(productId, variant)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ProductVariant), nil
+}
+
+func (m *MockProductService) DeleteVariant(ctx context.Context, productId uint, variantId uint) error {
+	args := m.Called(productId, variantId)
+	return args.Error(0)
+}
+
+func (m *MockProductService) ImportProducts(ctx context.Context, format string, r io.Reader, dryRun bool) (*catalog.ImportReport, error) {
+	args := m.Called(format, dryRun)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*catalog.ImportReport), nil
+}
+
+func (m *MockProductService) ExportProducts(ctx context.Context, format string, w io.Writer) (int, error) {
+	args := m.Called(format)
+	return args.Int(0), args.Error(1)
+}
+
+func newContext(method, target, body string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	e.Validator = &valiator.CustomValidator{Validator: validator.New()}
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	return e.NewContext(req, rec), rec
+}
+
+// TestPatchProduct checks that only the provided fields are changed.
+func TestPatchProduct(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := product.NewProductHandler(mockService)
+
+	existing := &model.Product{ID: 7, Name: "Shirt", Category: "Apparel", Price: 100, Stok: 3}
+	mockService.On("GetProductById", uint(7)).Return(existing, nil)
+	mockService.On("UpdateProduct", model.Product{ID: 7, Name: "Shirt", Category: "Apparel", Price: 80, Stok: 3}).
+		Return(&model.Product{ID: 7, Name: "Shirt", Category: "Apparel", Price: 80, Stok: 3}, nil)
+
+	c, rec := newContext(http.MethodPatch, "/v1/products/7", `{"price": 80}`)
+	c.SetParamNames("id")
+	c.SetParamValues("7")
+
+	err := handler.PatchProduct(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockService.AssertExpectations(t)
+}
+
+// TestDeleteProductNotFound checks that a missing product maps to 404.
+func TestDeleteProductNotFound(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := product.NewProductHandler(mockService)
+
+	mockService.On("DeleteProduct", uint(42)).Return(customErrors.ErrNotFound)
+
+	c, _ := newContext(http.MethodDelete, "/v1/products/42", "")
+	c.SetParamNames("id")
+	c.SetParamValues("42")
+
+	err := handler.DeleteProduct(c)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusNotFound, httpErr.Code)
+	mockService.AssertExpectations(t)
+}
+
+// TestUpdateProductValidation checks that PUT rejects an incomplete body.
+func TestUpdateProductValidation(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := product.NewProductHandler(mockService)
+
+	c, _ := newContext(http.MethodPut, "/v1/products/7", `{"price": 10}`)
+	c.SetParamNames("id")
+	c.SetParamValues("7")
+
+	err := handler.UpdateProduct(c)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+	mockService.AssertNotCalled(t, "UpdateProduct", mock.Anything)
+}
+
+// TestGetProductsOffsetPagination checks the filters passed to the service and the next-page link.
+func TestGetProductsOffsetPagination(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := product.NewProductHandler(mockService)
+
+	minPrice := 10.0
+	mockService.On("GetProductPage", model.ProductQuery{
+		Category: "Shoes",
+		MinPrice: &minPrice,
+		InStock:  true,
+		Sort:     model.SortPriceDesc,
+		Pagination: pagination.Params{
+			Page:  1,
+			Limit: 2,
+		},
+	}).Return(&model.ProductPage{
+		Products: []*model.Product{{ID: 1}, {ID: 2}},
+		Total:    5,
+	}, nil)
+
+	c, rec := newContext(http.MethodGet, "/v1/products?category=Shoes&min_price=10&in_stock=true&sort=-price&limit=2", "")
+
+	err := handler.GetProductsHandler(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var response product.ProductListResponse
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	assert.Len(t, response.Data, 2)
+	assert.Equal(t, int64(5), response.Meta.Total)
+	assert.Equal(t, 3, response.Meta.TotalPages)
+	assert.Contains(t, response.Meta.Next, "page=2")
+	mockService.AssertExpectations(t)
+}
+
+// TestGetProductsCursorPagination checks that the cursor is echoed into the next link.
+func TestGetProductsCursorPagination(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := product.NewProductHandler(mockService)
+
+	mockService.On("GetProductPage", mock.MatchedBy(func(q model.ProductQuery) bool {
+		return q.Pagination.UseCursor && q.Pagination.Cursor == ""
+	})).Return(&model.ProductPage{
+		Products:   []*model.Product{{ID: 1}},
+		Total:      3,
+		NextCursor: "abc",
+	}, nil)
+
+	c, rec := newContext(http.MethodGet, "/v1/products?cursor=&limit=1", "")
+
+	err := handler.GetProductsHandler(c)
+
+	assert.NoError(t, err)
+
+	var response product.ProductListResponse
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	assert.Equal(t, "abc", response.Meta.NextCursor)
+	assert.Contains(t, response.Meta.Next, "cursor=abc")
+	assert.Zero(t, response.Meta.Page)
+	mockService.AssertExpectations(t)
+}
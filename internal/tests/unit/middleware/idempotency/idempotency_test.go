@@ -0,0 +1,251 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	idempotencyConfig "go-online-store/config/idempotency"
+	"go-online-store/internal/domain/idempotency/model"
+	"go-online-store/internal/domain/idempotency/service"
+	"go-online-store/internal/middleware/idempotency"
+	"go-online-store/internal/middleware/jwt"
+	customErrors "go-online-store/pkg/errors"
+	"go-online-store/pkg/logger"
+)
+
+// fakeIdempotencyRepo keeps keys in memory.
+type fakeIdempotencyRepo struct {
+	keys    []*model.IdempotencyKey
+	saveErr error
+}
+
+func (r *fakeIdempotencyRepo) CreateKey(key *model.IdempotencyKey) (bool, error) {
+	if _, err := r.GetKey(key.CustomerID, key.Key); err == nil {
+		return false, nil
+	}
+	key.ID = uint(len(r.keys) + 1)
+	copied := *key
+	r.keys = append(r.keys, &copied)
+	return true, nil
+}
+
+func (r *fakeIdempotencyRepo) GetKey(customerID uint, key string) (*model.IdempotencyKey, error) {
+	for _, record := range r.keys {
+		if record.CustomerID == customerID && record.Key == key {
+			copied := *record
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeIdempotencyRepo) SaveKey(key *model.IdempotencyKey) error {
+	if r.saveErr != nil {
+		return r.saveErr
+	}
+	for i, record := range r.keys {
+		if record.ID == key.ID {
+			copied := *key
+			r.keys[i] = &copied
+		}
+	}
+	return nil
+}
+
+func (r *fakeIdempotencyRepo) DeleteKey(id uint) error {
+	for i, record := range r.keys {
+		if record.ID == id {
+			r.keys = append(r.keys[:i], r.keys[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *fakeIdempotencyRepo) DeleteExpiredKeys(now time.Time) (int64, error) {
+	var kept []*model.IdempotencyKey
+	for _, record := range r.keys {
+		if !record.Expired(now) {
+			kept = append(kept, record)
+		}
+	}
+	purged := int64(len(r.keys) - len(kept))
+	r.keys = kept
+	return purged, nil
+}
+
+var testLogger = logger.NewLogger(io.Discard, "test")
+
+func newService(repo *fakeIdempotencyRepo) *service.IdempotencyService {
+	cfg := &idempotencyConfig.IdempotencyConfig{Retention: time.Hour, PurgeInterval: time.Minute}
+	return service.NewIdempotencyService(repo, cfg, testLogger)
+}
+
+// checkout counts calls and answers with a new order number each time, like
+// a real checkout would.
+type checkout struct {
+	calls  int
+	status int
+}
+
+func (h *checkout) handle(c echo.Context) error {
+	h.calls++
+	if h.status != 0 {
+		return echo.NewHTTPError(h.status, "failed")
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"order_number": h.calls})
+}
+
+func send(e *echo.Echo, handler echo.HandlerFunc, customerID uint, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/v1/checkout", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if key != "" {
+		req.Header.Set(idempotency.KeyHeader, key)
+	}
+	req = req.WithContext(jwt.WithCustomer(req.Context(), jwt.Customer{ID: customerID}))
+	rec := httptest.NewRecorder()
+	if err := handler(e.NewContext(req, rec)); err != nil {
+		e.HTTPErrorHandler(err, e.NewContext(req, rec))
+	}
+	return rec
+}
+
+// TestIdempotentRetryIsReplayed checks a retry with the same key and body gets the first response without a second checkout.
+func TestIdempotentRetryIsReplayed(t *testing.T) {
+	e := echo.New()
+	h := &checkout{}
+	handler := idempotency.Middleware(newService(&fakeIdempotencyRepo{}), testLogger)(h.handle)
+
+	first := send(e, handler, 1, "abc", `{"discount_code":""}`)
+	retry := send(e, handler, 1, "abc", `{"discount_code":""}`)
+
+	assert.Equal(t, 1, h.calls)
+	assert.Equal(t, http.StatusOK, retry.Code)
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, "true", retry.Header().Get(idempotency.ReplayedHeader))
+	assert.Empty(t, first.Header().Get(idempotency.ReplayedHeader))
+
+	// Keys belong to one customer
+	send(e, handler, 2, "abc", `{"discount_code":""}`)
+	assert.Equal(t, 2, h.calls)
+}
+
+// TestIdempotencyKeyReusedForOtherRequest checks a key cannot be reused with a different body.
+func TestIdempotencyKeyReusedForOtherRequest(t *testing.T) {
+	e := echo.New()
+	h := &checkout{}
+	handler := idempotency.Middleware(newService(&fakeIdempotencyRepo{}), testLogger)(h.handle)
+
+	send(e, handler, 1, "abc", `{}`)
+	rec := send(e, handler, 1, "abc", `{"discount_code":"BACK-1234"}`)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, 1, h.calls)
+}
+
+// TestIdempotencyServerErrorIsNotStored checks a retry after a server error is handled again while client errors are replayed.
+func TestIdempotencyServerErrorIsNotStored(t *testing.T) {
+	e := echo.New()
+	h := &checkout{status: http.StatusInternalServerError}
+	handler := idempotency.Middleware(newService(&fakeIdempotencyRepo{}), testLogger)(h.handle)
+
+	send(e, handler, 1, "abc", `{}`)
+	h.status = 0
+	rec := send(e, handler, 1, "abc", `{}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 2, h.calls)
+
+	h.status = http.StatusConflict
+	send(e, handler, 1, "def", `{}`)
+	h.status = 0
+	rec = send(e, handler, 1, "def", `{}`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, 3, h.calls)
+}
+
+// TestRequestsWithoutKeyPassThrough checks the header is optional.
+func TestRequestsWithoutKeyPassThrough(t *testing.T) {
+	e := echo.New()
+	h := &checkout{}
+	repo := &fakeIdempotencyRepo{}
+	handler := idempotency.Middleware(newService(repo), testLogger)(h.handle)
+
+	send(e, handler, 1, "", `{}`)
+	send(e, handler, 1, "", `{}`)
+
+	assert.Equal(t, 2, h.calls)
+	assert.Empty(t, repo.keys)
+}
+
+// TestIdempotencyKeyInProgressAndExpired checks a key still being handled is refused and an expired one can be used again.
+func TestIdempotencyKeyInProgressAndExpired(t *testing.T) {
+	repo := &fakeIdempotencyRepo{}
+	svc := newService(repo)
+
+	record, err := svc.Begin(context.Background(), 1, "abc", "hash")
+	assert.NoError(t, err)
+	assert.False(t, record.Completed())
+
+	_, err = svc.Begin(context.Background(), 1, "abc", "hash")
+	assert.ErrorIs(t, err, customErrors.ErrIdempotencyKeyInUse)
+
+	_, err = svc.Begin(context.Background(), 1, strings.Repeat("k", model.MaxKeyLength+1), "hash")
+	assert.ErrorIs(t, err, customErrors.ErrInvalidIdempotencyKey)
+
+	repo.keys[0].ExpiresAt = time.Now().Add(-time.Minute)
+	record, err = svc.Begin(context.Background(), 1, "abc", "other")
+	assert.NoError(t, err)
+	assert.Equal(t, "other", record.RequestHash)
+	assert.Len(t, repo.keys, 1)
+
+	repo.keys[0].ExpiresAt = time.Now().Add(-time.Minute)
+	purged, err := svc.PurgeExpiredKeys(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+}
+
+// TestIdempotencyRetryAfterFailedStore checks a key whose response could not be stored is given back, so the retry is handled.
+func TestIdempotencyRetryAfterFailedStore(t *testing.T) {
+	e := echo.New()
+	repo := &fakeIdempotencyRepo{saveErr: errors.New("store unavailable")}
+	h := &checkout{}
+	handler := idempotency.Middleware(newService(repo), testLogger)(h.handle)
+
+	first := send(e, handler, 1, "abc", `{"cart":1}`)
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Empty(t, repo.keys)
+
+	repo.saveErr = nil
+	retry := send(e, handler, 1, "abc", `{"cart":1}`)
+	assert.Equal(t, http.StatusOK, retry.Code)
+	assert.Equal(t, 2, h.calls)
+	assert.Len(t, repo.keys, 1)
+	assert.True(t, repo.keys[0].Completed())
+}
+
+// TestIdempotencyKeyReleasedOnPanic checks a panicking handler does not leave its key in progress.
+func TestIdempotencyKeyReleasedOnPanic(t *testing.T) {
+	e := echo.New()
+	repo := &fakeIdempotencyRepo{}
+	handler := idempotency.Middleware(newService(repo), testLogger)(func(c echo.Context) error {
+		panic("boom")
+	})
+
+	assert.Panics(t, func() { send(e, handler, 1, "abc", `{"cart":1}`) })
+	assert.Empty(t, repo.keys)
+
+	h := &checkout{}
+	retry := send(e, idempotency.Middleware(newService(repo), testLogger)(h.handle), 1, "abc", `{"cart":1}`)
+	assert.Equal(t, http.StatusOK, retry.Code)
+	assert.Equal(t, 1, h.calls)
+}
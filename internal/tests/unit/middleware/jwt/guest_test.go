@@ -0,0 +1,57 @@
+package jwt
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"go-online-store/internal/middleware/jwt"
+)
+
+// TestGuestTokenRoundTrip checks a token verifies with its secret and nothing else.
+func TestGuestTokenRoundTrip(t *testing.T) {
+	guestID, token, err := jwt.NewGuestToken("secret")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, guestID)
+
+	parsed, err := jwt.ParseGuestToken("secret", token)
+	assert.NoError(t, err)
+	assert.Equal(t, guestID, parsed)
+
+	_, err = jwt.ParseGuestToken("other", token)
+	assert.ErrorIs(t, err, jwt.ErrInvalidGuestToken)
+	_, err = jwt.ParseGuestToken("secret", "someone-else"+token[len(guestID):])
+	assert.ErrorIs(t, err, jwt.ErrInvalidGuestToken)
+	_, err = jwt.ParseGuestToken("secret", guestID)
+	assert.ErrorIs(t, err, jwt.ErrInvalidGuestToken)
+}
+
+// TestAllowGuestIssuesAndReusesToken checks a new visitor gets a token and a returning one keeps theirs.
+func TestAllowGuestIssuesAndReusesToken(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	e := echo.New()
+
+	var seen string
+	handler := jwt.AllowGuest(func(c echo.Context) error {
+		guestID, ok := jwt.FromGuest(c.Request().Context())
+		assert.True(t, ok)
+		seen = guestID
+		return c.NoContent(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	assert.NoError(t, handler(e.NewContext(httptest.NewRequest(http.MethodGet, "/v1/cart", nil), rec)))
+	token := rec.Header().Get(jwt.GuestCartHeader)
+	assert.NotEmpty(t, token)
+	first := seen
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/cart", nil)
+	req.AddCookie(&http.Cookie{Name: jwt.GuestCartCookie, Value: token})
+	rec = httptest.NewRecorder()
+	assert.NoError(t, handler(e.NewContext(req, rec)))
+	assert.Empty(t, rec.Header().Get(jwt.GuestCartHeader))
+	assert.Equal(t, first, seen)
+}
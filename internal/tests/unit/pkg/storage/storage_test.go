@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-online-store/pkg/storage"
+)
+
+// TestLocalStorageSaveAndDelete checks objects are written below the root and served under the base URL.
+func TestLocalStorageSaveAndDelete(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	store, err := storage.NewLocalStorage(root, "http://localhost/media/")
+	assert.NoError(t, err)
+
+	url, err := store.Save(ctx, "products/1/mug.png", strings.NewReader("image"))
+	assert.NoError(t, err)
+	assert.Equal(t, "http://localhost/media/products/1/mug.png", url)
+
+	content, err := os.ReadFile(filepath.Join(root, "products", "1", "mug.png"))
+	assert.NoError(t, err)
+	assert.Equal(t, "image", string(content))
+
+	assert.NoError(t, store.Delete(ctx, "products/1/mug.png"))
+	_, err = os.Stat(filepath.Join(root, "products", "1", "mug.png"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	// Deleting a missing object is not an error
+	assert.NoError(t, store.Delete(ctx, "products/1/mug.png"))
+}
+
+// TestLocalStorageRejectsKeysOutsideRoot checks keys cannot reach files outside the root.
+func TestLocalStorageRejectsKeysOutsideRoot(t *testing.T) {
+	ctx := context.Background()
+	parent := t.TempDir()
+	root := filepath.Join(parent, "media")
+	store, err := storage.NewLocalStorage(root, "http://localhost/media")
+	assert.NoError(t, err)
+
+	victim := filepath.Join(parent, "secret.txt")
+	assert.NoError(t, os.WriteFile(victim, []byte("secret"), 0o644))
+
+	for _, key := range []string{"", "/", "../secret.txt", "products/../../secret.txt", "/etc/passwd", "products//mug.png", "products/./mug.png", "products/"} {
+		_, err := store.Save(ctx, key, strings.NewReader("overwritten"))
+		assert.ErrorIs(t, err, storage.ErrInvalidKey, key)
+		assert.ErrorIs(t, store.Delete(ctx, key), storage.ErrInvalidKey, key)
+	}
+
+	content, err := os.ReadFile(victim)
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", string(content))
+}
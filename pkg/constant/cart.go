@@ -0,0 +1,14 @@
+package constant
+
+const (
+	CART_WARNING_PRICE_CHANGED      = "PRICE_CHANGED"
+	CART_WARNING_OUT_OF_STOCK       = "OUT_OF_STOCK"
+	CART_WARNING_INSUFFICIENT_STOCK = "INSUFFICIENT_STOCK"
+	CART_WARNING_UNAVAILABLE        = "UNAVAILABLE"
+)
+
+const (
+	CART_RECOVERY_STATUS_PENDING = "PENDING"
+	CART_RECOVERY_STATUS_SENT    = "SENT"
+	CART_RECOVERY_STATUS_FAILED  = "FAILED"
+)
@@ -0,0 +1,22 @@
+package constant
+
+// Reasons of inventory movements
+const (
+	STOCK_REASON_OPENING     = "OPENING"
+	STOCK_REASON_SALE        = "SALE"
+	STOCK_REASON_RESTOCK     = "RESTOCK"
+	STOCK_REASON_ADJUSTMENT  = "ADJUSTMENT"
+	STOCK_REASON_RETURN      = "RETURN"
+	STOCK_REASON_RESERVATION = "RESERVATION"
+	STOCK_REASON_RELEASE     = "RELEASE"
+	STOCK_REASON_TRANSFER    = "TRANSFER"
+)
+
+// STOCK_ACTOR_SYSTEM is the actor of movements made by background jobs.
+const STOCK_ACTOR_SYSTEM = "system"
+
+// Warehouse that holds stock not assigned to a location
+const (
+	DEFAULT_WAREHOUSE_CODE = "MAIN"
+	DEFAULT_WAREHOUSE_NAME = "Main warehouse"
+)
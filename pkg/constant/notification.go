@@ -0,0 +1,12 @@
+package constant
+
+const (
+	SUBSCRIPTION_BACK_IN_STOCK = "BACK_IN_STOCK"
+	SUBSCRIPTION_PRICE_DROP    = "PRICE_DROP"
+)
+
+const (
+	NOTIFICATION_STATUS_PENDING = "PENDING"
+	NOTIFICATION_STATUS_SENT    = "SENT"
+	NOTIFICATION_STATUS_FAILED  = "FAILED"
+)
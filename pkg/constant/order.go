@@ -1,6 +1,29 @@
 package constant
 
+// Statuses of an order; see model.Order.Transition for the allowed changes.
 const (
-	ORDER_STATUS_PENDING = "PENDING"
-	ORDER_STATUS_SUCCESS = "SUCCESS"
+	ORDER_STATUS_PENDING          = "PENDING"
+	ORDER_STATUS_AWAITING_PAYMENT = "AWAITING_PAYMENT"
+	ORDER_STATUS_PAID             = "PAID"
+	ORDER_STATUS_PROCESSING       = "PROCESSING"
+	ORDER_STATUS_SHIPPED          = "SHIPPED"
+	ORDER_STATUS_DELIVERED        = "DELIVERED"
+	ORDER_STATUS_CANCELLED        = "CANCELLED"
+	ORDER_STATUS_REFUNDED         = "REFUNDED"
+	ORDER_STATUS_FAILED           = "FAILED"
+)
+
+// ORDER_ACTOR_SYSTEM is the actor of status changes made by the store itself.
+const ORDER_ACTOR_SYSTEM = "system"
+
+// Reasons an order is cancelled for
+const (
+	CANCEL_REASON_CHANGED_MIND       = "CHANGED_MIND"
+	CANCEL_REASON_ORDERED_BY_MISTAKE = "ORDERED_BY_MISTAKE"
+	CANCEL_REASON_FOUND_CHEAPER      = "FOUND_CHEAPER"
+	CANCEL_REASON_DELIVERY_TOO_SLOW  = "DELIVERY_TOO_SLOW"
+	CANCEL_REASON_OUT_OF_STOCK       = "OUT_OF_STOCK"
+	CANCEL_REASON_PAYMENT_ISSUE      = "PAYMENT_ISSUE"
+	CANCEL_REASON_FRAUD_SUSPECTED    = "FRAUD_SUSPECTED"
+	CANCEL_REASON_OTHER              = "OTHER"
 )
@@ -4,4 +4,9 @@ const (
 	PAYMENT_STATUS_PENDING = "PENDING"
 	PAYMENT_STATUS_PAID    = "PAID"
 	PAYMENT_STATUS_FAILED  = "FAILED"
+	PAYMENT_STATUS_EXPIRED = "EXPIRED"
+	// An unpaid transaction of a cancelled order
+	PAYMENT_STATUS_VOIDED = "VOIDED"
+	// A paid transaction paid back after cancellation, and the refund itself
+	PAYMENT_STATUS_REFUNDED = "REFUNDED"
 )
@@ -0,0 +1,13 @@
+package constant
+
+// Kinds of promotion rules. Any kind can be limited to a category.
+const (
+	// A rate of the subtotal, e.g. 0.1 for 10% off
+	PROMOTION_TYPE_PERCENTAGE = "PERCENTAGE"
+	// A fixed amount off the subtotal
+	PROMOTION_TYPE_FIXED = "FIXED"
+	// The shipping fee is waived
+	PROMOTION_TYPE_FREE_SHIPPING = "FREE_SHIPPING"
+	// Of every buy+get units of one line, get units are free
+	PROMOTION_TYPE_BUY_X_GET_Y = "BUY_X_GET_Y"
+)
@@ -0,0 +1,9 @@
+package constant
+
+const (
+	RESERVATION_STATUS_ACTIVE    = "ACTIVE"
+	RESERVATION_STATUS_COMMITTED = "COMMITTED"
+	RESERVATION_STATUS_RELEASED  = "RELEASED"
+	// Committed stock put back after a paid order was cancelled
+	RESERVATION_STATUS_RETURNED = "RETURNED"
+)
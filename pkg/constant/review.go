@@ -0,0 +1,7 @@
+package constant
+
+const (
+	REVIEW_STATUS_PENDING  = "PENDING"
+	REVIEW_STATUS_APPROVED = "APPROVED"
+	REVIEW_STATUS_REJECTED = "REJECTED"
+)
@@ -0,0 +1,9 @@
+package constant
+
+const (
+	ROLE_CUSTOMER = "CUSTOMER"
+	ROLE_ADMIN    = "ADMIN"
+	// ROLE_SYSTEM acts for the store itself: checkout, payment callbacks
+	// and background jobs.
+	ROLE_SYSTEM = "SYSTEM"
+)
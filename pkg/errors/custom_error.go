@@ -11,6 +11,7 @@ import (
 var (
 	ErrBadRequest               = errors.New("bad request")
 	ErrUnauthorized             = errors.New("unauthorized")
+	ErrForbidden                = errors.New("forbidden")
 	ErrNotFound                 = errors.New("not found")
 	ErrInternalServerError      = errors.New("internal server error")
 	ErrCartIsEmpty              = errors.New("cart is empty")
@@ -23,6 +24,36 @@ var (
 	ErrFailedToRemoveFromCart   = errors.New("failed to remove from cart")
 	ErrFailedToRetrieveCart     = errors.New("failed to retrieve cart")
 	ErrProductStockNotAvailable = errors.New("product stok not available")
+	ErrVariantRequired          = errors.New("variant is required for this product")
+	ErrInvalidVariant           = errors.New("invalid product variant")
+	ErrDuplicateSKU             = errors.New("sku already exists")
+	ErrUnsupportedMedia         = errors.New("unsupported media type")
+	ErrMediaTooLarge            = errors.New("media file too large")
+	ErrInvalidCategory          = errors.New("invalid category")
+	ErrCategoryInUse            = errors.New("category still has products or subcategories")
+	ErrDuplicateSlug            = errors.New("slug already exists")
+	ErrReservationExpired       = errors.New("order reservation expired")
+	ErrInvalidTransition        = errors.New("order cannot move to this status")
+	ErrInvalidStockMovement     = errors.New("invalid stock movement")
+	ErrInvalidWarehouse         = errors.New("invalid warehouse")
+	ErrInvalidImportFile        = errors.New("invalid import file")
+	ErrInvalidReview            = errors.New("invalid review")
+	ErrDuplicateReview          = errors.New("product already reviewed")
+	ErrReviewNotAllowed         = errors.New("only customers who bought the product can review it")
+	ErrInvalidWishlist          = errors.New("invalid wishlist")
+	ErrWishlistLimit            = errors.New("too many wishlists")
+	ErrInvalidSubscription      = errors.New("invalid subscription")
+	ErrInvalidQuantity          = errors.New("quantity must be at least 1")
+	ErrPurchaseLimitExceeded    = errors.New("purchase limit exceeded")
+	ErrCartChanged              = errors.New("cart changed since items were added, review it before checkout")
+	ErrInvalidDiscountCode      = errors.New("discount code is invalid, expired or already used")
+	ErrInvalidIdempotencyKey    = errors.New("invalid idempotency key")
+	ErrIdempotencyKeyInUse      = errors.New("a request with this idempotency key is still in progress")
+	ErrIdempotencyKeyReused     = errors.New("idempotency key was already used for a different request")
+	ErrInvalidPromotion         = errors.New("invalid promotion")
+	ErrDuplicatePromotionCode   = errors.New("promotion code already exists")
+	ErrPromotionNotApplicable   = errors.New("discount code does not apply to this cart")
+	ErrPromotionUnavailable     = errors.New("promotion is no longer available")
 )
 
 // HTTPErrorHandler maps service errors to HTTP errors
@@ -32,6 +63,8 @@ func HTTPErrorHandler(err error) *echo.HTTPError {
 		return echo.NewHTTPError(http.StatusBadRequest, ErrBadRequest.Error())
 	case errors.Is(err, ErrUnauthorized):
 		return echo.NewHTTPError(http.StatusUnauthorized, ErrUnauthorized.Error())
+	case errors.Is(err, ErrForbidden):
+		return echo.NewHTTPError(http.StatusForbidden, ErrForbidden.Error())
 	case errors.Is(err, ErrNotFound):
 		return echo.NewHTTPError(http.StatusNotFound, ErrNotFound.Error())
 	case errors.Is(err, ErrInternalServerError):
@@ -56,6 +89,66 @@ func HTTPErrorHandler(err error) *echo.HTTPError {
 		return echo.NewHTTPError(http.StatusBadRequest, ErrCartIsEmpty.Error())
 	case errors.Is(err, ErrProductStockNotAvailable):
 		return echo.NewHTTPError(http.StatusNotFound, ErrProductStockNotAvailable.Error())
+	case errors.Is(err, ErrVariantRequired):
+		return echo.NewHTTPError(http.StatusBadRequest, ErrVariantRequired.Error())
+	case errors.Is(err, ErrInvalidVariant):
+		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidVariant.Error())
+	case errors.Is(err, ErrDuplicateSKU):
+		return echo.NewHTTPError(http.StatusConflict, ErrDuplicateSKU.Error())
+	case errors.Is(err, ErrUnsupportedMedia):
+		return echo.NewHTTPError(http.StatusUnsupportedMediaType, ErrUnsupportedMedia.Error())
+	case errors.Is(err, ErrMediaTooLarge):
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, ErrMediaTooLarge.Error())
+	case errors.Is(err, ErrInvalidCategory):
+		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidCategory.Error())
+	case errors.Is(err, ErrCategoryInUse):
+		return echo.NewHTTPError(http.StatusConflict, ErrCategoryInUse.Error())
+	case errors.Is(err, ErrDuplicateSlug):
+		return echo.NewHTTPError(http.StatusConflict, ErrDuplicateSlug.Error())
+	case errors.Is(err, ErrReservationExpired):
+		return echo.NewHTTPError(http.StatusConflict, ErrReservationExpired.Error())
+	case errors.Is(err, ErrInvalidTransition):
+		return echo.NewHTTPError(http.StatusConflict, ErrInvalidTransition.Error())
+	case errors.Is(err, ErrInvalidStockMovement):
+		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidStockMovement.Error())
+	case errors.Is(err, ErrInvalidWarehouse):
+		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidWarehouse.Error())
+	case errors.Is(err, ErrInvalidImportFile):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrInvalidReview):
+		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidReview.Error())
+	case errors.Is(err, ErrDuplicateReview):
+		return echo.NewHTTPError(http.StatusConflict, ErrDuplicateReview.Error())
+	case errors.Is(err, ErrReviewNotAllowed):
+		return echo.NewHTTPError(http.StatusForbidden, ErrReviewNotAllowed.Error())
+	case errors.Is(err, ErrInvalidWishlist):
+		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidWishlist.Error())
+	case errors.Is(err, ErrWishlistLimit):
+		return echo.NewHTTPError(http.StatusConflict, ErrWishlistLimit.Error())
+	case errors.Is(err, ErrInvalidSubscription):
+		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidSubscription.Error())
+	case errors.Is(err, ErrInvalidQuantity):
+		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidQuantity.Error())
+	case errors.Is(err, ErrPurchaseLimitExceeded):
+		return echo.NewHTTPError(http.StatusBadRequest, ErrPurchaseLimitExceeded.Error())
+	case errors.Is(err, ErrCartChanged):
+		return echo.NewHTTPError(http.StatusConflict, ErrCartChanged.Error())
+	case errors.Is(err, ErrInvalidDiscountCode):
+		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidDiscountCode.Error())
+	case errors.Is(err, ErrInvalidIdempotencyKey):
+		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidIdempotencyKey.Error())
+	case errors.Is(err, ErrIdempotencyKeyInUse):
+		return echo.NewHTTPError(http.StatusConflict, ErrIdempotencyKeyInUse.Error())
+	case errors.Is(err, ErrIdempotencyKeyReused):
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, ErrIdempotencyKeyReused.Error())
+	case errors.Is(err, ErrInvalidPromotion):
+		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidPromotion.Error())
+	case errors.Is(err, ErrDuplicatePromotionCode):
+		return echo.NewHTTPError(http.StatusConflict, ErrDuplicatePromotionCode.Error())
+	case errors.Is(err, ErrPromotionNotApplicable):
+		return echo.NewHTTPError(http.StatusBadRequest, ErrPromotionNotApplicable.Error())
+	case errors.Is(err, ErrPromotionUnavailable):
+		return echo.NewHTTPError(http.StatusConflict, ErrPromotionUnavailable.Error())
 	default:
 		return echo.NewHTTPError(http.StatusInternalServerError, ErrInternalServerError.Error())
 	}
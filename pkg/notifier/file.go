@@ -0,0 +1,55 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileNotifier appends every message to a local file as one JSON object per
+// line instead of delivering it. It stands in for a mail server during
+// development and tests.
+type FileNotifier struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+type fileEntry struct {
+	Message
+	SentAt time.Time `json:"sent_at"`
+}
+
+func NewFileNotifier(path string) (*FileNotifier, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	return &FileNotifier{Path: path}, nil
+}
+
+func (n *FileNotifier) Send(ctx context.Context, msg Message) error {
+	if msg.To == "" {
+		return ErrNoRecipient
+	}
+
+	line, err := json.Marshal(fileEntry{Message: msg, SentAt: time.Now()})
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	file, err := os.OpenFile(n.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
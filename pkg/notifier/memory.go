@@ -0,0 +1,42 @@
+package notifier
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryNotifier keeps messages in memory instead of delivering them, for
+// tests. Setting Err makes every send fail with it.
+type MemoryNotifier struct {
+	Err error
+
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewMemoryNotifier() *MemoryNotifier {
+	return &MemoryNotifier{}
+}
+
+func (n *MemoryNotifier) Send(ctx context.Context, msg Message) error {
+	if msg.To == "" {
+		return ErrNoRecipient
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.Err != nil {
+		return n.Err
+	}
+	n.messages = append(n.messages, msg)
+	return nil
+}
+
+// Messages returns the messages sent so far, oldest first.
+func (n *MemoryNotifier) Messages() []Message {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return append([]Message(nil), n.messages...)
+}
@@ -0,0 +1,21 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrNoRecipient = errors.New("message has no recipient")
+
+// Message is a plain-text notification addressed to one customer.
+type Message struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// Notifier delivers messages to customers.
+type Notifier interface {
+	// Send delivers msg. A returned error means it should be retried later.
+	Send(ctx context.Context, msg Message) error
+}
@@ -0,0 +1,57 @@
+package notifier
+
+import (
+	"context"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+// SMTPNotifier sends messages as plain-text email through an SMTP server.
+// Credentials are optional so it also works against local catch-all servers
+// such as MailHog.
+type SMTPNotifier struct {
+	Addr     string
+	From     string
+	Username string
+	Password string
+}
+
+func NewSMTPNotifier(host, port, from, username, password string) *SMTPNotifier {
+	return &SMTPNotifier{
+		Addr:     net.JoinHostPort(host, port),
+		From:     from,
+		Username: username,
+		Password: password,
+	}
+}
+
+func (n *SMTPNotifier) Send(ctx context.Context, msg Message) error {
+	if msg.To == "" {
+		return ErrNoRecipient
+	}
+
+	var auth smtp.Auth
+	if n.Username != "" {
+		host, _, _ := net.SplitHostPort(n.Addr)
+		auth = smtp.PlainAuth("", n.Username, n.Password, host)
+	}
+	return smtp.SendMail(n.Addr, auth, n.From, []string{msg.To}, n.compose(msg))
+}
+
+func (n *SMTPNotifier) compose(msg Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + n.From + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + headerValue(msg.Subject) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// headerValue keeps a header on one line so a value cannot inject headers.
+func headerValue(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
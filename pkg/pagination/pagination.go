@@ -0,0 +1,107 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Params holds the pagination options of a list request.
+// Offset pagination uses Page, cursor pagination uses Cursor.
+type Params struct {
+	Page      int
+	Limit     int
+	Cursor    string
+	UseCursor bool
+}
+
+// Normalize applies the default and maximum page size and a minimum page.
+func (p Params) Normalize() Params {
+	if p.Limit <= 0 {
+		p.Limit = DefaultLimit
+	}
+	if p.Limit > MaxLimit {
+		p.Limit = MaxLimit
+	}
+	if p.Page <= 0 {
+		p.Page = 1
+	}
+	return p
+}
+
+// Offset returns the number of rows to skip for offset pagination.
+func (p Params) Offset() int {
+	return (p.Page - 1) * p.Limit
+}
+
+// Meta is returned alongside a page of results.
+type Meta struct {
+	Total      int64  `json:"total"`
+	Limit      int    `json:"limit"`
+	Page       int    `json:"page,omitempty"`
+	TotalPages int    `json:"total_pages,omitempty"`
+	Next       string `json:"next,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// Cursor marks the last row of a page for keyset pagination.
+type Cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    uint   `json:"id"`
+}
+
+// Encode returns the opaque string form of the cursor.
+func (c Cursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor parses a cursor produced by Cursor.Encode.
+func DecodeCursor(s string) (Cursor, error) {
+	var c Cursor
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// TotalPages returns the number of pages needed to hold total rows.
+func TotalPages(total int64, limit int) int {
+	if limit <= 0 {
+		return 0
+	}
+	return int((total + int64(limit) - 1) / int64(limit))
+}
+
+// NextLink returns the request URL with the given query parameters replaced.
+func NextLink(u *url.URL, replace map[string]string) string {
+	next := *u
+	query := next.Query()
+	for key, value := range replace {
+		query.Set(key, value)
+	}
+	next.RawQuery = query.Encode()
+	return next.String()
+}
+
+// ParseInt parses an optional integer query value, returning def when empty.
+func ParseInt(s string, def int) (int, error) {
+	if s == "" {
+		return def, nil
+	}
+	return strconv.Atoi(s)
+}
@@ -0,0 +1,39 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+)
+
+// Job is work run on a schedule. It should return once ctx is done.
+type Job func(ctx context.Context)
+
+// Scheduler runs jobs in the background of the process.
+type Scheduler interface {
+	// Every runs job once per interval until ctx is done.
+	Every(ctx context.Context, interval time.Duration, job Job)
+}
+
+// Ticker schedules jobs with a time.Ticker in their own goroutine. Runs never
+// overlap: a run that takes longer than the interval delays the next one.
+type Ticker struct{}
+
+func NewTicker() *Ticker {
+	return &Ticker{}
+}
+
+func (Ticker) Every(ctx context.Context, interval time.Duration, job Job) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				job(ctx)
+			}
+		}
+	}()
+}
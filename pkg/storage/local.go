@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage keeps objects on the local filesystem below Root and serves
+// them under BaseURL.
+type LocalStorage struct {
+	Root    string
+	BaseURL string
+}
+
+func NewLocalStorage(root, baseURL string) (*LocalStorage, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalStorage{
+		Root:    root,
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+	}, nil
+}
+
+func (s *LocalStorage) Save(ctx context.Context, key string, r io.Reader) (string, error) {
+	fullPath, err := s.path(key)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+		return "", err
+	}
+
+	// Write to a temporary file first so readers never see a partial object
+	tmp, err := os.CreateTemp(filepath.Dir(fullPath), ".upload-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), fullPath); err != nil {
+		return "", err
+	}
+
+	return s.URL(key), nil
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	fullPath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(fullPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalStorage) URL(key string) string {
+	return s.BaseURL + "/" + key
+}
+
+// path maps a key to a file below Root, rejecting keys that escape it.
+func (s *LocalStorage) path(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if key == "" || clean == "/" || clean != "/"+key {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(s.Root, filepath.FromSlash(clean)), nil
+}
@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// Storage stores binary objects under slash-separated keys and exposes them
+// through a public URL.
+type Storage interface {
+	// Save writes the content of r under key and returns its public URL.
+	Save(ctx context.Context, key string, r io.Reader) (string, error)
+	// Delete removes the object stored under key. Missing objects are not an error.
+	Delete(ctx context.Context, key string) error
+	// URL returns the public URL of key.
+	URL(key string) string
+}
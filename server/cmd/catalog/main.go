@@ -0,0 +1,126 @@
+// Command catalog imports and exports the product catalog as CSV or JSON Lines.
+//
+//	catalog import [-format csv|jsonl] [-dry-run] FILE
+//	catalog export [-format csv|jsonl] [-o FILE]
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"go-online-store/internal/domain/product/catalog"
+	productService "go-online-store/internal/domain/product/service"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	env := ".env"
+	if err := godotenv.Load(env); err != nil {
+		log.Fatal("Error loading .env file on catalog :" + err.Error())
+	}
+
+	switch os.Args[1] {
+	case "import":
+		runImport(os.Args[2:])
+	case "export":
+		runExport(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: catalog import [-format csv|jsonl] [-dry-run] FILE")
+	fmt.Fprintln(os.Stderr, "       catalog export [-format csv|jsonl] [-o FILE]")
+	os.Exit(2)
+}
+
+func runImport(args []string) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	formatFlag := flags.String("format", "", "csv or jsonl; defaults to the file extension")
+	dryRun := flags.Bool("dry-run", false, "validate every row without saving")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		usage()
+	}
+	path := flags.Arg(0)
+
+	format, err := catalog.ParseFormat(firstNonEmpty(*formatFlag, path))
+	if err != nil {
+		log.Fatal("Unknown format, pass -format csv or -format jsonl")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+
+	service := productService.NewInstanceProductService()
+	if service == nil {
+		log.Fatal("Failed to initialize product service")
+	}
+
+	report, err := service.ImportProducts(context.Background(), format, file, *dryRun)
+	if err != nil {
+		log.Fatal("Import failed: " + err.Error())
+	}
+
+	out, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(out))
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func runExport(args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	formatFlag := flags.String("format", catalog.FormatCSV, "csv or jsonl")
+	output := flags.String("o", "", "output file; defaults to products.<format>")
+	flags.Parse(args)
+
+	format, err := catalog.ParseFormat(*formatFlag)
+	if err != nil {
+		log.Fatal("Unknown format, pass -format csv or -format jsonl")
+	}
+	// Services log to stdout, so the export always goes to a file
+	path := firstNonEmpty(*output, "products."+format)
+
+	service := productService.NewInstanceProductService()
+	if service == nil {
+		log.Fatal("Failed to initialize product service")
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	count, err := service.ExportProducts(context.Background(), format, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Fatal("Export failed: " + err.Error())
+	}
+	fmt.Printf("Exported %d products to %s\n", count, path)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -1,16 +1,35 @@
 package router
 
 import (
+	"context"
+	notificationConfig "go-online-store/config/notification"
+	orderConfig "go-online-store/config/order"
+	storageConfig "go-online-store/config/storage"
 	cartService "go-online-store/internal/domain/cart/service"
+	categoryService "go-online-store/internal/domain/category/service"
 	customerService "go-online-store/internal/domain/customer/service"
+	idempotencyService "go-online-store/internal/domain/idempotency/service"
+	inventoryService "go-online-store/internal/domain/inventory/service"
+	notificationService "go-online-store/internal/domain/notification/service"
 	orderService "go-online-store/internal/domain/order/service"
 	productService "go-online-store/internal/domain/product/service"
+	promotionService "go-online-store/internal/domain/promotion/service"
+	reviewService "go-online-store/internal/domain/review/service"
+	wishlistService "go-online-store/internal/domain/wishlist/service"
 	"go-online-store/internal/handlers/cart"
+	"go-online-store/internal/handlers/category"
 	"go-online-store/internal/handlers/customer"
+	"go-online-store/internal/handlers/inventory"
+	"go-online-store/internal/handlers/notification"
 	"go-online-store/internal/handlers/order"
 	"go-online-store/internal/handlers/product"
+	"go-online-store/internal/handlers/promotion"
+	"go-online-store/internal/handlers/review"
+	"go-online-store/internal/handlers/wishlist"
+	"go-online-store/internal/middleware/idempotency"
 	"go-online-store/internal/middleware/jwt"
 	"go-online-store/pkg/logger"
+	"go-online-store/pkg/scheduler"
 	_ "go-online-store/server/cmd/docs"
 
 	"github.com/labstack/echo/v4"
@@ -22,15 +41,51 @@ func RegisterRouter(e *echo.Echo, log *logger.Logger) *echo.Echo {
 
 	// Init Service
 	userService := customerService.NewInstanceUserService()
+	mediaService := productService.NewInstanceMediaService()
 	productService := productService.NewInstanceProductService()
+	categoryService := categoryService.NewInstanceCategoryService()
+	// Warehouses come first so opening balances land at the default warehouse
+	warehouseService := inventoryService.NewInstanceWarehouseService()
+	inventoryService := inventoryService.NewInstanceInventoryService()
+	recoveryService := cartService.NewInstanceRecoveryService()
+	if recoveryService != nil {
+		// Remind customers about carts they left with items in them
+		recoveryService.StartRecovery(context.Background(), scheduler.NewTicker())
+	}
 	cartService := cartService.NewInstanceCartService()
+	promotionService := promotionService.NewInstancePromotionService()
+	reviewService := reviewService.NewInstanceReviewService()
+	wishlistService := wishlistService.NewInstanceWishlistService()
+	notificationService := notificationService.NewInstanceNotificationService()
+	if notificationService != nil {
+		// Deliver back-in-stock and price-drop notifications queued by product writes
+		notificationService.StartDispatcher(context.Background(), notificationConfig.LoadNotificationConfig().DispatchInterval)
+	}
+	idempotencyService := idempotencyService.NewInstanceIdempotencyService()
+	if idempotencyService != nil {
+		// Forget stored checkout responses once their retention window ends
+		idempotencyService.StartPurger(context.Background(), scheduler.NewTicker())
+	}
 	orderService, _ := orderService.NewOrderService()
+	if orderService != nil {
+		// Return reserved stock of orders that were never paid
+		orderService.StartReservationSweeper(context.Background(), orderConfig.LoadOrderConfig().ReservationSweepInterval)
+	}
 
 	// Init Handler
-	customerHandler := customer.NewCustomerHandler(userService)
+	customerHandler := customer.NewCustomerHandler(userService).WithCartService(cartService)
 	productHandler := product.NewProductHandler(productService)
+	productMediaHandler := product.NewProductMediaHandler(mediaService)
+	categoryHandler := category.NewCategoryHandler(categoryService)
+	inventoryHandler := inventory.NewInventoryHandler(inventoryService)
+	warehouseHandler := inventory.NewWarehouseHandler(warehouseService)
 	cartHandler := cart.NewCartHandler(cartService)
+	recoveryHandler := cart.NewRecoveryHandler(recoveryService)
 	orderHandler := order.NewOrderHandler(orderService)
+	promotionHandler := promotion.NewPromotionHandler(promotionService)
+	reviewHandler := review.NewReviewHandler(reviewService)
+	wishlistHandler := wishlist.NewWishlistHandler(wishlistService)
+	notificationHandler := notification.NewNotificationHandler(notificationService)
 
 	// Group routes for API v1
 	v1 := e.Group("/v1")
@@ -40,20 +95,108 @@ func RegisterRouter(e *echo.Echo, log *logger.Logger) *echo.Echo {
 	v1.POST("/user/register", customerHandler.CustomerRegister)
 
 	// Router for product
-	v1.GET("/products", jwt.ValidateJWT(productHandler.GetProductsByCategoryHandler))
-	v1.POST("/products", jwt.ValidateJWT(productHandler.CreateProduct))
+	v1.GET("/products", jwt.ValidateJWT(productHandler.GetProductsHandler))
+	v1.GET("/products/search", jwt.ValidateJWT(productHandler.SearchProductsHandler))
+	v1.GET("/products/export", jwt.ValidateJWT(jwt.RequireAdmin(productHandler.ExportProductsHandler)))
+	v1.POST("/products/import", jwt.ValidateJWT(jwt.RequireAdmin(productHandler.ImportProductsHandler)))
+	v1.GET("/products/:id", jwt.ValidateJWT(productHandler.GetProductByIdHandler))
+	v1.POST("/products", jwt.ValidateJWT(jwt.RequireAdmin(productHandler.CreateProduct)))
+	v1.PUT("/products/:id", jwt.ValidateJWT(jwt.RequireAdmin(productHandler.UpdateProduct)))
+	v1.PATCH("/products/:id", jwt.ValidateJWT(jwt.RequireAdmin(productHandler.PatchProduct)))
+	v1.DELETE("/products/:id", jwt.ValidateJWT(jwt.RequireAdmin(productHandler.DeleteProduct)))
+	v1.PUT("/products/:id/options", jwt.ValidateJWT(jwt.RequireAdmin(productHandler.SetProductOptionsHandler)))
+	v1.GET("/products/:id/variants", jwt.ValidateJWT(productHandler.GetVariantsHandler))
+	v1.POST("/products/:id/variants", jwt.ValidateJWT(jwt.RequireAdmin(productHandler.CreateVariantHandler)))
+	v1.PUT("/products/:id/variants/:variantId", jwt.ValidateJWT(jwt.RequireAdmin(productHandler.UpdateVariantHandler)))
+	v1.DELETE("/products/:id/variants/:variantId", jwt.ValidateJWT(jwt.RequireAdmin(productHandler.DeleteVariantHandler)))
+	v1.GET("/products/:id/media", jwt.ValidateJWT(productMediaHandler.GetMediaHandler))
+	v1.POST("/products/:id/media", jwt.ValidateJWT(jwt.RequireAdmin(productMediaHandler.UploadMediaHandler)))
+	v1.PUT("/products/:id/media/order", jwt.ValidateJWT(jwt.RequireAdmin(productMediaHandler.ReorderMediaHandler)))
+	v1.PATCH("/products/:id/media/:mediaId", jwt.ValidateJWT(jwt.RequireAdmin(productMediaHandler.UpdateMediaHandler)))
+	v1.DELETE("/products/:id/media/:mediaId", jwt.ValidateJWT(jwt.RequireAdmin(productMediaHandler.DeleteMediaHandler)))
+
+	// Routes for inventory
+	v1.GET("/products/:id/stock/history", jwt.ValidateJWT(jwt.RequireAdmin(inventoryHandler.GetStockHistoryHandler)))
+	v1.POST("/products/:id/stock", jwt.ValidateJWT(jwt.RequireAdmin(inventoryHandler.AdjustStockHandler)))
+	v1.GET("/inventory/reconcile", jwt.ValidateJWT(jwt.RequireAdmin(inventoryHandler.GetReconcileHandler)))
+	v1.POST("/inventory/reconcile", jwt.ValidateJWT(jwt.RequireAdmin(inventoryHandler.ReconcileHandler)))
+
+	// Routes for warehouses
+	v1.GET("/warehouses", jwt.ValidateJWT(jwt.RequireAdmin(warehouseHandler.GetWarehousesHandler)))
+	v1.POST("/warehouses", jwt.ValidateJWT(jwt.RequireAdmin(warehouseHandler.CreateWarehouseHandler)))
+	v1.PUT("/warehouses/:id", jwt.ValidateJWT(jwt.RequireAdmin(warehouseHandler.UpdateWarehouseHandler)))
+	v1.GET("/warehouses/:id/stock", jwt.ValidateJWT(jwt.RequireAdmin(warehouseHandler.GetWarehouseStockHandler)))
+	v1.POST("/warehouses/transfers", jwt.ValidateJWT(jwt.RequireAdmin(warehouseHandler.TransferStockHandler)))
+	v1.GET("/products/:id/stock/locations", jwt.ValidateJWT(jwt.RequireAdmin(warehouseHandler.GetProductStockLocationsHandler)))
+
+	// Routes for reviews
+	v1.GET("/products/:id/reviews", jwt.ValidateJWT(reviewHandler.GetProductReviewsHandler))
+	v1.POST("/products/:id/reviews", jwt.ValidateJWT(reviewHandler.CreateReviewHandler))
+	v1.PUT("/reviews/:id", jwt.ValidateJWT(reviewHandler.UpdateReviewHandler))
+	v1.DELETE("/reviews/:id", jwt.ValidateJWT(reviewHandler.DeleteReviewHandler))
+	v1.POST("/reviews/:id/votes", jwt.ValidateJWT(reviewHandler.VoteReviewHandler))
+	v1.GET("/reviews", jwt.ValidateJWT(jwt.RequireAdmin(reviewHandler.GetReviewsHandler)))
+	v1.PUT("/reviews/:id/moderation", jwt.ValidateJWT(jwt.RequireAdmin(reviewHandler.ModerateReviewHandler)))
+
+	// Routes for category
+	v1.GET("/categories", jwt.ValidateJWT(categoryHandler.GetCategoryTreeHandler))
+	v1.GET("/categories/:id", jwt.ValidateJWT(categoryHandler.GetCategoryHandler))
+	v1.POST("/categories", jwt.ValidateJWT(jwt.RequireAdmin(categoryHandler.CreateCategoryHandler)))
+	v1.PUT("/categories/:id", jwt.ValidateJWT(jwt.RequireAdmin(categoryHandler.UpdateCategoryHandler)))
+	v1.DELETE("/categories/:id", jwt.ValidateJWT(jwt.RequireAdmin(categoryHandler.DeleteCategoryHandler)))
+	v1.POST("/categories/:id/merge", jwt.ValidateJWT(jwt.RequireAdmin(categoryHandler.MergeCategoryHandler)))
 
 	// Routes for cart
-	v1.GET("/cart", jwt.ValidateJWT(cartHandler.GetCartHandler))
-	v1.POST("/cart", jwt.ValidateJWT(cartHandler.AddToCartHandler))
-	v1.DELETE("/cart", jwt.ValidateJWT(cartHandler.RemoveFromCartHandler))
+	// Visitors who are not signed in get a guest cart that is merged on login
+	v1.GET("/cart", jwt.AllowGuest(cartHandler.GetCartHandler))
+	v1.POST("/cart", jwt.AllowGuest(cartHandler.AddToCartHandler))
+	v1.DELETE("/cart", jwt.AllowGuest(cartHandler.RemoveFromCartHandler))
+	v1.PUT("/cart/items/:productId", jwt.AllowGuest(cartHandler.UpdateCartItemHandler))
+	v1.POST("/cart/confirm", jwt.AllowGuest(cartHandler.ConfirmCartHandler))
+	v1.POST("/cart/items/:productId/save-for-later", jwt.AllowGuest(cartHandler.SaveForLaterHandler))
+	v1.POST("/cart/items/:productId/move-to-cart", jwt.AllowGuest(cartHandler.MoveToCartHandler))
+	v1.GET("/cart/recoveries/stats", jwt.ValidateJWT(jwt.RequireAdmin(recoveryHandler.GetRecoveryStatsHandler)))
+	v1.GET("/cart/recoveries/:token", recoveryHandler.OpenRecoveryHandler)
+
+	// Routes for wishlist
+	v1.GET("/wishlists", jwt.ValidateJWT(wishlistHandler.GetWishlistsHandler))
+	v1.POST("/wishlists", jwt.ValidateJWT(wishlistHandler.CreateWishlistHandler))
+	v1.GET("/wishlists/shared/:token", wishlistHandler.GetSharedWishlistHandler)
+	v1.GET("/wishlists/:id", jwt.ValidateJWT(wishlistHandler.GetWishlistHandler))
+	v1.PUT("/wishlists/:id", jwt.ValidateJWT(wishlistHandler.RenameWishlistHandler))
+	v1.DELETE("/wishlists/:id", jwt.ValidateJWT(wishlistHandler.DeleteWishlistHandler))
+	v1.POST("/wishlists/:id/items", jwt.ValidateJWT(wishlistHandler.AddToWishlistHandler))
+	v1.DELETE("/wishlists/:id/items/:itemId", jwt.ValidateJWT(wishlistHandler.RemoveFromWishlistHandler))
+	v1.POST("/wishlists/:id/items/:itemId/cart", jwt.ValidateJWT(wishlistHandler.MoveToCartHandler))
+	v1.POST("/wishlists/:id/share", jwt.ValidateJWT(wishlistHandler.ShareWishlistHandler))
+	v1.DELETE("/wishlists/:id/share", jwt.ValidateJWT(wishlistHandler.UnshareWishlistHandler))
+
+	// Routes for product notifications
+	v1.POST("/products/:id/subscriptions", jwt.ValidateJWT(notificationHandler.SubscribeHandler))
+	v1.GET("/subscriptions", jwt.ValidateJWT(notificationHandler.GetSubscriptionsHandler))
+	v1.DELETE("/subscriptions/:id", jwt.ValidateJWT(notificationHandler.UnsubscribeHandler))
 
 	// Routes for order
-	v1.POST("/checkout", jwt.ValidateJWT(orderHandler.CheckoutHandler))
-	v1.POST("/checkout/paid", jwt.ValidateJWT(orderHandler.TransactionPaidHandler))
+	idempotent := idempotency.Middleware(idempotencyService, log)
+	v1.POST("/checkout", jwt.ValidateJWT(idempotent(orderHandler.CheckoutHandler)))
+	v1.POST("/checkout/paid", jwt.ValidateJWT(idempotent(orderHandler.TransactionPaidHandler)))
+	v1.GET("/orders", jwt.ValidateJWT(orderHandler.GetOrdersHandler))
+	v1.GET("/orders/:id", jwt.ValidateJWT(orderHandler.GetOrderHandler))
+	v1.PUT("/orders/:id/status", jwt.ValidateJWT(jwt.RequireAdmin(orderHandler.UpdateOrderStatusHandler)))
+	v1.POST("/orders/:id/cancel", jwt.ValidateJWT(orderHandler.CancelOrderHandler))
+
+	// Routes for promotions
+	v1.GET("/promotions", jwt.ValidateJWT(jwt.RequireAdmin(promotionHandler.GetPromotionsHandler)))
+	v1.GET("/promotions/:id", jwt.ValidateJWT(jwt.RequireAdmin(promotionHandler.GetPromotionHandler)))
+	v1.POST("/promotions", jwt.ValidateJWT(jwt.RequireAdmin(promotionHandler.CreatePromotionHandler)))
+	v1.PUT("/promotions/:id", jwt.ValidateJWT(jwt.RequireAdmin(promotionHandler.UpdatePromotionHandler)))
 
 	// Swagger endpoint
 	v1.GET("/swagger/*", echoSwagger.EchoWrapHandler())
 
+	// Uploaded media files
+	storageCfg := storageConfig.LoadStorageConfig()
+	e.Static(storageCfg.MediaBaseURL, storageCfg.MediaRoot)
+
 	return e
 }